@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const brevoEndpoint = "https://api.brevo.com/v3/smtp/email"
+
+type Brevo struct {
+	APIKey   string
+	From     string
+	FromName string
+	Client   *http.Client
+}
+
+func NewBrevo(cfg Config) *Brevo {
+	return &Brevo{
+		APIKey:   cfg.BrevoAPIKey,
+		From:     cfg.From,
+		FromName: cfg.FromName,
+		Client:   &http.Client{Timeout: 12 * time.Second},
+	}
+}
+
+func (b *Brevo) Send(ctx context.Context, msg Message) error {
+	if b.APIKey == "" {
+		return errors.New("BREVO_API_KEY not configured")
+	}
+	if b.From == "" {
+		return errors.New("MAIL_FROM not configured")
+	}
+
+	body := map[string]any{
+		"sender": map[string]string{
+			"name":  b.FromName,
+			"email": b.From,
+		},
+		"to": []map[string]string{
+			{"email": msg.To},
+		},
+		"subject":     msg.Subject,
+		"htmlContent": msg.HTML,
+		"textContent": msg.Text,
+	}
+	raw, _ := json.Marshal(body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, brevoEndpoint, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("api-key", b.APIKey)
+
+	resp, err := b.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("mail provider error: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package mailer
+
+// TemplateInfo describes a named template and the sample data used by
+// previews and override validation. Sample keys document what callers pass.
+type TemplateInfo struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Sample      map[string]any `json:"sample"`
+}
+
+var catalog = []TemplateInfo{
+	{
+		Name:        "welcome",
+		Description: "Sent to the owner after a new workspace is registered.",
+		Sample:      map[string]any{"Name": "Ada Lovelace", "TenantName": "Acme Corp", "Role": "owner"},
+	},
+	{
+		Name:        "invitation",
+		Description: "Invitation link to join a workspace.",
+		Sample:      map[string]any{"TenantName": "Acme Corp", "Role": "member", "AcceptURL": "https://app.example.com/auth?invite=sample", "ExpiresAt": "2026-01-31 09:00 UTC"},
+	},
+	{
+		Name:        "password_reset",
+		Description: "Single-use password reset link.",
+		Sample:      map[string]any{"Name": "Ada Lovelace", "TenantName": "Acme Corp", "ResetURL": "https://app.example.com/auth?reset=sample", "ExpiresMinutes": 30},
+	},
+	{
+		Name:        "password_changed",
+		Description: "Confirmation that a password reset completed.",
+		Sample:      map[string]any{},
+	},
+	{
+		Name:        "account_locked",
+		Description: "Account temporarily locked after repeated failed sign-ins.",
+		Sample:      map[string]any{"Attempts": 5, "ClientIP": "203.0.113.7", "LockedUntil": "2026-01-31 09:15 UTC"},
+	},
+	{
+		Name:        "approval_needed",
+		Description: "Asks the current approver to review a request.",
+		Sample: map[string]any{
+			"TenantName": "Acme Corp", "ProjectName": "Website Revamp", "BillableHours": "42.50",
+			"RequestedBy": "pm@example.com", "Pipeline": "multi approval", "Step": 1, "Steps": 2, "Note": "Sprint 4 hours",
+		},
+	},
+	{
+		Name:        "approval_rejected",
+		Description: "Tells the requester an approval was rejected.",
+		Sample:      map[string]any{"ProjectName": "Website Revamp", "Actor": "finance@example.com"},
+	},
+	{
+		Name:        "approval_completed",
+		Description: "Tells the requester an approval was granted.",
+		Sample:      map[string]any{"ProjectName": "Website Revamp"},
+	},
+	{
+		Name:        "reminder_summary",
+		Description: "Scheduled summary of pending work.",
+		Sample:      map[string]any{"PendingProjects": 3, "AssignedPendingProjects": 1, "OverdueProjects": 1, "OpenTasks": 5, "OverdueTasks": 2},
+	},
+	{
+		Name:        "project_reminder",
+		Description: "Reminder to assignees of a pending project.",
+		Sample:      map[string]any{"ProjectName": "Website Revamp", "DueDate": "2026-02-15", "OpenTasks": 4},
+	},
+	{
+		Name:        "daily_digest",
+		Description: "End-of-day digest of workload and recent notifications.",
+		Sample: map[string]any{
+			"Date": "2026-01-31", "PendingProjects": 3, "AssignedPendingProjects": 1, "OverdueProjects": 1, "OpenTasks": 5, "OverdueTasks": 2,
+			"UnreadCount": 4, "Notifications": []string{"Approval completed", "Project overdue: Website Revamp"},
+		},
+	},
+	{
+		Name:        "overdue_alert",
+		Description: "Alert to an assignee that a project passed its due date.",
+		Sample:      map[string]any{"ProjectName": "Website Revamp", "DueDate": "2026-01-28", "DaysOverdue": 3, "OpenTasks": 4},
+	},
+	{
+		Name:        "project_created",
+		Description: "Confirmation to the creator of a new project.",
+		Sample:      map[string]any{"ProjectName": "Website Revamp"},
+	},
+	{
+		Name:        "task_created",
+		Description: "Confirmation to the creator of a new task.",
+		Sample:      map[string]any{"TaskTitle": "Draft homepage copy"},
+	},
+	{
+		Name:        "system_update_scheduled",
+		Description: "Platform update announcement to organization admins.",
+		Sample:      map[string]any{"Date": "2026-02-01", "Title": "New reporting module", "Brief": "Adds billing reports.", "Expectations": "No downtime expected."},
+	},
+	{
+		Name:        "system_update_revised",
+		Description: "Revised platform update announcement.",
+		Sample:      map[string]any{"Date": "2026-02-03", "Title": "New reporting module", "Brief": "Adds billing reports.", "Expectations": "Ten minutes of read-only mode."},
+	},
+	{
+		Name:        "support_request",
+		Description: "Support request forwarded to the platform team.",
+		Sample: map[string]any{
+			"Requester": "ada@example.com", "Tenant": "acme", "Priority": "HIGH", "SubmittedAt": "2026-01-31 09:00:00",
+			"Subject": "Cannot export timesheets", "Message": "The export button returns an error.",
+		},
+	},
+	{
+		Name:        "notification",
+		Description: "Generic notification with a caller-supplied subject and message.",
+		Sample:      map[string]any{"Subject": "PulseForge notification", "Message": "This is a test notification from PulseForge backend."},
+	},
+}
+
+func Catalog() []TemplateInfo {
+	out := make([]TemplateInfo, len(catalog))
+	copy(out, catalog)
+	return out
+}
+
+func LookupTemplate(name string) (TemplateInfo, bool) {
+	for _, info := range catalog {
+		if info.Name == name {
+			return info, true
+		}
+	}
+	return TemplateInfo{}, false
+}
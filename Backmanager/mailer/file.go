@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// File writes every message to Dir as an .eml file that any mail client can
+// open. Intended for local development.
+type File struct {
+	Dir      string
+	From     string
+	FromName string
+}
+
+func NewFile(cfg Config) *File {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "mail-outbox"
+	}
+	from := cfg.From
+	if from == "" {
+		from = "no-reply@localhost"
+	}
+	return &File{Dir: dir, From: from, FromName: cfg.FromName}
+}
+
+func (f *File) Send(_ context.Context, msg Message) error {
+	now := time.Now()
+	raw, err := buildMIME(f.From, f.FromName, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	suffix, err := randomHex(4)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s-%s.eml", now.UTC().Format("20060102T150405.000"), sanitizeFilename(msg.To), suffix)
+	return os.WriteFile(filepath.Join(f.Dir, name), raw, 0o644)
+}
+
+func sanitizeFilename(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		}
+		return '_'
+	}, v)
+}
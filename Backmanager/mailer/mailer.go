@@ -0,0 +1,124 @@
+// Package mailer delivers outgoing email through a configurable transport.
+//
+// Drivers: "brevo" (HTTP API, the default), "smtp" (STARTTLS, implicit TLS or
+// plain, with optional auth), "file" (writes each message as an .eml file for
+// local development) and "memory" (records messages for tests). Message
+// content comes from the embedded, localized templates rendered by Render.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	Driver   string
+	From     string
+	FromName string
+
+	BrevoAPIKey string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// SMTPSecurity is "starttls" (default), "tls" for implicit TLS, or "none".
+	SMTPSecurity string
+
+	Dir string
+}
+
+// ConfigFromEnv reads MAIL_DRIVER, MAIL_FROM, MAIL_FROM_NAME, BREVO_API_KEY,
+// SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD, SMTP_SECURITY and MAIL_DIR.
+func ConfigFromEnv() Config {
+	port, _ := strconv.Atoi(strings.TrimSpace(os.Getenv("SMTP_PORT")))
+	cfg := Config{
+		Driver:       strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))),
+		From:         strings.TrimSpace(os.Getenv("MAIL_FROM")),
+		FromName:     strings.TrimSpace(os.Getenv("MAIL_FROM_NAME")),
+		BrevoAPIKey:  strings.TrimSpace(os.Getenv("BREVO_API_KEY")),
+		SMTPHost:     strings.TrimSpace(os.Getenv("SMTP_HOST")),
+		SMTPPort:     port,
+		SMTPUsername: strings.TrimSpace(os.Getenv("SMTP_USERNAME")),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		SMTPSecurity: strings.ToLower(strings.TrimSpace(os.Getenv("SMTP_SECURITY"))),
+		Dir:          strings.TrimSpace(os.Getenv("MAIL_DIR")),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = "brevo"
+	}
+	if cfg.FromName == "" {
+		cfg.FromName = "PulseForge"
+	}
+	return cfg
+}
+
+// New builds the transport selected by cfg.Driver. Missing credentials are
+// reported when a message is sent, matching how the app behaved before drivers
+// existed, so a server without mail configured still boots. A configuration
+// that could never work, such as SMTP credentials over a plain connection, is
+// rejected here instead.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Driver {
+	case "brevo":
+		return NewBrevo(cfg), nil
+	case "smtp":
+		if err := validateSMTP(cfg); err != nil {
+			return nil, err
+		}
+		return NewSMTP(cfg), nil
+	case "file":
+		return NewFile(cfg), nil
+	case "memory":
+		return NewMemory(), nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_DRIVER %q (want brevo, smtp, file or memory)", cfg.Driver)
+	}
+}
+
+// Memory records messages instead of delivering them.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (m *Memory) Send(_ context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Sent returns a copy of every message recorded so far.
+func (m *Memory) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Message, len(m.messages))
+	copy(out, m.messages)
+	return out
+}
+
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,168 @@
+package mailer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryRecordsMessages(t *testing.T) {
+	m := NewMemory()
+	first := Message{To: "a@example.com", Subject: "One", Text: "hello"}
+	second := Message{To: "b@example.com", Subject: "Two", HTML: "<p>hi</p>"}
+	for _, msg := range []Message{first, second} {
+		if err := m.Send(context.Background(), msg); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	sent := m.Sent()
+	if len(sent) != 2 || sent[0] != first || sent[1] != second {
+		t.Fatalf("Sent() = %+v", sent)
+	}
+	// Sent returns a copy, so callers cannot rewrite the record.
+	sent[0].Subject = "changed"
+	if m.Sent()[0].Subject != "One" {
+		t.Fatal("Sent() exposed the internal slice")
+	}
+
+	m.Reset()
+	if got := m.Sent(); len(got) != 0 {
+		t.Fatalf("after Reset, Sent() = %+v", got)
+	}
+}
+
+func TestMemoryConcurrentSends(t *testing.T) {
+	m := NewMemory()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = m.Send(context.Background(), Message{To: fmt.Sprintf("u%d@example.com", i)})
+		}(i)
+	}
+	wg.Wait()
+	if got := len(m.Sent()); got != 50 {
+		t.Fatalf("recorded %d messages, want 50", got)
+	}
+}
+
+func TestNewSelectsDriver(t *testing.T) {
+	cases := map[string]string{
+		"brevo":  "*mailer.Brevo",
+		"smtp":   "*mailer.SMTP",
+		"file":   "*mailer.File",
+		"memory": "*mailer.Memory",
+	}
+	for driver, want := range cases {
+		m, err := New(Config{Driver: driver})
+		if err != nil {
+			t.Fatalf("New(%q): %v", driver, err)
+		}
+		if got := fmt.Sprintf("%T", m); got != want {
+			t.Errorf("New(%q) = %s, want %s", driver, got, want)
+		}
+	}
+	if _, err := New(Config{Driver: "carrier-pigeon"}); err == nil {
+		t.Error("unknown driver was accepted")
+	}
+}
+
+func TestNewRejectsUnusableSMTPConfig(t *testing.T) {
+	cases := []struct {
+		cfg Config
+		ok  bool
+	}{
+		{Config{Driver: "smtp", SMTPSecurity: "none"}, true},
+		{Config{Driver: "smtp", SMTPSecurity: "starttls", SMTPUsername: "user"}, true},
+		{Config{Driver: "smtp", SMTPSecurity: "tls", SMTPUsername: "user"}, true},
+		{Config{Driver: "smtp", SMTPUsername: "user"}, true},
+		{Config{Driver: "smtp", SMTPSecurity: "none", SMTPUsername: "user"}, false},
+		{Config{Driver: "smtp", SMTPSecurity: "ssl"}, false},
+	}
+	for _, tc := range cases {
+		_, err := New(tc.cfg)
+		if (err == nil) != tc.ok {
+			t.Errorf("New(security=%q, username=%q): err = %v", tc.cfg.SMTPSecurity, tc.cfg.SMTPUsername, err)
+		}
+	}
+}
+
+// fakeSMTP accepts one plain-text SMTP session and reports the commands it
+// saw and the message data.
+func fakeSMTP(t *testing.T) (string, <-chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	seen := make(chan []string, 1)
+	go func() {
+		var commands []string
+		defer func() { seen <- commands }()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			commands = append(commands, verb)
+			switch verb {
+			case "EHLO":
+				reply("250-fake")
+				reply("250 8BITMIME")
+			case "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+				}
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), seen
+}
+
+func TestSMTPPlainConnectionWithoutAuth(t *testing.T) {
+	addr, seen := fakeSMTP(t)
+	host, rawPort, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(rawPort)
+
+	m, err := New(Config{Driver: "smtp", SMTPHost: host, SMTPPort: port, SMTPSecurity: "none", From: "noreply@example.com"})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := m.Send(context.Background(), Message{To: "a@example.com", Subject: "Hi", Text: "hello"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	commands := strings.Join(<-seen, " ")
+	if commands != "EHLO MAIL RCPT DATA QUIT" {
+		t.Fatalf("commands = %q", commands)
+	}
+}
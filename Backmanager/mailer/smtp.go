@@ -0,0 +1,200 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SMTP struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Security string
+	From     string
+	FromName string
+	Timeout  time.Duration
+}
+
+func validateSMTP(cfg Config) error {
+	switch cfg.SMTPSecurity {
+	case "", "starttls", "tls":
+		return nil
+	case "none":
+		// net/smtp refuses to send credentials over an unencrypted connection
+		// to anything but localhost, so every send would fail.
+		if cfg.SMTPUsername != "" {
+			return errors.New("SMTP_USERNAME requires SMTP_SECURITY starttls or tls; credentials are not sent over a plain connection")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown SMTP_SECURITY %q (want starttls, tls or none)", cfg.SMTPSecurity)
+	}
+}
+
+func NewSMTP(cfg Config) *SMTP {
+	port := cfg.SMTPPort
+	security := cfg.SMTPSecurity
+	if security == "" {
+		security = "starttls"
+	}
+	if port == 0 {
+		port = 587
+		if security == "tls" {
+			port = 465
+		}
+	}
+	return &SMTP{
+		Host:     cfg.SMTPHost,
+		Port:     port,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		Security: security,
+		From:     cfg.From,
+		FromName: cfg.FromName,
+		Timeout:  15 * time.Second,
+	}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	if s.Host == "" {
+		return errors.New("SMTP_HOST not configured")
+	}
+	if s.From == "" {
+		return errors.New("MAIL_FROM not configured")
+	}
+	raw, err := buildMIME(s.From, s.FromName, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+	addr := net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: s.Host, MinVersion: tls.VersionTLS12}
+	if s.Security == "tls" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.Security == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" && s.Security != "none" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMIME renders msg as a multipart/alternative RFC 5322 message with
+// quoted-printable text and HTML parts.
+func buildMIME(from, fromName string, msg Message, now time.Time) ([]byte, error) {
+	if strings.ContainsAny(msg.To, "\r\n") || strings.ContainsAny(msg.Subject, "\r\n") {
+		return nil, errors.New("mail header contains a line break")
+	}
+	boundary, err := randomHex(12)
+	if err != nil {
+		return nil, err
+	}
+	messageID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", (&mail.Address{Name: fromName, Address: from}).String())
+	header("To", (&mail.Address{Address: msg.To}).String())
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", "<"+messageID+"@"+domain+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	parts := []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, part := range parts {
+		if part.body == "" {
+			continue
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s\r\n", part.contentType)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	return buf.Bytes(), nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
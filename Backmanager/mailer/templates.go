@@ -0,0 +1,346 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates live in templates/<locale>/<name>.tmpl. Each file holds a
+// "-- subject --" section, a "-- text --" section and an optional
+// "-- html --" section. Subject and text are text/template; html is
+// html/template and, when omitted, the text body is escaped and used instead.
+// The rendered HTML is always wrapped in templates/layout.html.
+//
+//go:embed templates
+var templateFS embed.FS
+
+const DefaultLocale = "en"
+
+const defaultAccent = "#1f2937"
+
+var accentPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Brand is the per-tenant look applied by the layout.
+type Brand struct {
+	Name    string
+	LogoURL string
+	Accent  string
+}
+
+func DefaultBrand() Brand {
+	return Brand{Name: "PulseForge", Accent: defaultAccent}
+}
+
+// ValidAccent reports whether c is a #rrggbb color.
+func ValidAccent(c string) bool {
+	return accentPattern.MatchString(c)
+}
+
+// TemplateSource is the editable form of a template, used both for the
+// embedded defaults and for per-tenant overrides.
+type TemplateSource struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// RenderRequest selects a template and locale. Overrides are keyed by locale
+// and take precedence over the embedded template for the same locale.
+type RenderRequest struct {
+	Name      string
+	Locale    string
+	Brand     Brand
+	Data      map[string]any
+	Overrides map[string]TemplateSource
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+var (
+	embeddedSources = map[string]map[string]TemplateSource{}
+	layoutTemplate  *htmltemplate.Template
+)
+
+func init() {
+	layoutTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html"))
+
+	entries, err := fs.ReadDir(templateFS, "templates")
+	if err != nil {
+		panic(err)
+	}
+	for _, dir := range entries {
+		if !dir.IsDir() {
+			continue
+		}
+		locale := dir.Name()
+		files, err := fs.Glob(templateFS, path.Join("templates", locale, "*.tmpl"))
+		if err != nil {
+			panic(err)
+		}
+		embeddedSources[locale] = map[string]TemplateSource{}
+		for _, file := range files {
+			raw, err := templateFS.ReadFile(file)
+			if err != nil {
+				panic(err)
+			}
+			name := strings.TrimSuffix(path.Base(file), ".tmpl")
+			src, err := ParseTemplateSource(string(raw))
+			if err != nil {
+				panic(fmt.Sprintf("mailer: %s: %v", file, err))
+			}
+			if _, err := compileTemplate(name, src); err != nil {
+				panic(fmt.Sprintf("mailer: %s: %v", file, err))
+			}
+			embeddedSources[locale][name] = src
+		}
+	}
+	for _, info := range catalog {
+		if _, ok := embeddedSources[DefaultLocale][info.Name]; !ok {
+			panic("mailer: missing default template " + info.Name)
+		}
+	}
+}
+
+// ParseTemplateSource splits a sectioned template file.
+func ParseTemplateSource(raw string) (TemplateSource, error) {
+	sections := map[string]*strings.Builder{}
+	var current *strings.Builder
+	for _, line := range strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "-- ") && strings.HasSuffix(trimmed, " --") {
+			key := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(trimmed, "-- "), " --"))
+			if key != "subject" && key != "text" && key != "html" {
+				return TemplateSource{}, fmt.Errorf("unknown section %q", key)
+			}
+			current = &strings.Builder{}
+			sections[key] = current
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+			current.WriteByte('\n')
+		}
+	}
+	get := func(key string) string {
+		if b, ok := sections[key]; ok {
+			return strings.TrimSpace(b.String())
+		}
+		return ""
+	}
+	src := TemplateSource{Subject: get("subject"), Text: get("text"), HTML: get("html")}
+	if src.Subject == "" || src.Text == "" {
+		return TemplateSource{}, fmt.Errorf("subject and text sections are required")
+	}
+	return src, nil
+}
+
+func compileTemplate(name string, src TemplateSource) (compiledTemplate, error) {
+	var out compiledTemplate
+	var err error
+	if strings.TrimSpace(src.Subject) == "" || strings.TrimSpace(src.Text) == "" {
+		return out, fmt.Errorf("subject and text are required")
+	}
+	if out.subject, err = texttemplate.New(name + ".subject").Parse(src.Subject); err != nil {
+		return out, err
+	}
+	if out.text, err = texttemplate.New(name + ".text").Parse(src.Text); err != nil {
+		return out, err
+	}
+	if strings.TrimSpace(src.HTML) != "" {
+		if out.html, err = htmltemplate.New(name + ".html").Parse(src.HTML); err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// Locales lists the locales that ship embedded templates.
+func Locales() []string {
+	out := make([]string, 0, len(embeddedSources))
+	for locale := range embeddedSources {
+		out = append(out, locale)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// NormalizeLocale lowercases and converts "pt_BR" style tags to "pt-br".
+func NormalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// SupportedLocale reports whether locale, or its base language, has embedded
+// templates.
+func SupportedLocale(locale string) bool {
+	if _, ok := embeddedSources[NormalizeLocale(locale)]; ok {
+		return true
+	}
+	_, ok := embeddedSources[baseLanguage(locale)]
+	return ok
+}
+
+func baseLanguage(locale string) string {
+	locale = NormalizeLocale(locale)
+	if i := strings.IndexByte(locale, '-'); i > 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// localeCandidates is the fallback chain: exact tag, base language, default.
+func localeCandidates(locale string) []string {
+	locale = NormalizeLocale(locale)
+	out := make([]string, 0, 3)
+	for _, l := range []string{locale, baseLanguage(locale), DefaultLocale} {
+		if l == "" {
+			continue
+		}
+		dup := false
+		for _, seen := range out {
+			if seen == l {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// DefaultSource returns the embedded template for name in the closest
+// available locale, and that locale.
+func DefaultSource(name, locale string) (TemplateSource, string, bool) {
+	for _, candidate := range localeCandidates(locale) {
+		if src, ok := embeddedSources[candidate][name]; ok {
+			return src, candidate, true
+		}
+	}
+	return TemplateSource{}, "", false
+}
+
+// ValidateSource compiles src and executes it against the catalog sample data
+// so broken overrides are rejected before they are stored.
+func ValidateSource(name string, src TemplateSource) error {
+	info, ok := LookupTemplate(name)
+	if !ok {
+		return fmt.Errorf("unknown template %q", name)
+	}
+	compiled, err := compileTemplate(name, src)
+	if err != nil {
+		return err
+	}
+	_, err = compiled.execute(DefaultBrand(), DefaultLocale, info.Sample)
+	return err
+}
+
+// Render executes the named template. The returned message has no recipient.
+func Render(req RenderRequest) (Message, string, error) {
+	if _, ok := LookupTemplate(req.Name); !ok {
+		return Message{}, "", fmt.Errorf("unknown template %q", req.Name)
+	}
+	var src TemplateSource
+	var locale string
+	found := false
+	for _, candidate := range localeCandidates(req.Locale) {
+		if o, ok := req.Overrides[candidate]; ok {
+			src, locale, found = o, candidate, true
+			break
+		}
+		if e, ok := embeddedSources[candidate][req.Name]; ok {
+			src, locale, found = e, candidate, true
+			break
+		}
+	}
+	if !found {
+		return Message{}, "", fmt.Errorf("template %q not found", req.Name)
+	}
+	compiled, err := compileTemplate(req.Name, src)
+	if err != nil {
+		return Message{}, "", err
+	}
+	brand := req.Brand
+	if strings.TrimSpace(brand.Name) == "" {
+		brand.Name = DefaultBrand().Name
+	}
+	if !ValidAccent(brand.Accent) {
+		brand.Accent = defaultAccent
+	}
+	if !strings.HasPrefix(brand.LogoURL, "https://") && !strings.HasPrefix(brand.LogoURL, "http://") {
+		// Data URLs and relative paths do not survive most mail clients.
+		brand.LogoURL = ""
+	}
+	msg, err := compiled.execute(brand, locale, req.Data)
+	return msg, locale, err
+}
+
+func (t compiledTemplate) execute(brand Brand, locale string, data map[string]any) (Message, error) {
+	vars := make(map[string]any, len(data)+1)
+	for k, v := range data {
+		vars[k] = v
+	}
+	vars["Brand"] = brand
+
+	var subject, text bytes.Buffer
+	if err := t.subject.Execute(&subject, vars); err != nil {
+		return Message{}, err
+	}
+	if err := t.text.Execute(&text, vars); err != nil {
+		return Message{}, err
+	}
+	msg := Message{
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()),
+	}
+
+	var content htmltemplate.HTML
+	if t.html != nil {
+		var body bytes.Buffer
+		if err := t.html.Execute(&body, vars); err != nil {
+			return Message{}, err
+		}
+		content = htmltemplate.HTML(body.String())
+	} else {
+		content = htmltemplate.HTML(strings.ReplaceAll(htmltemplate.HTMLEscapeString(msg.Text), "\n", "<br/>"))
+	}
+
+	var page bytes.Buffer
+	if err := layoutTemplate.Execute(&page, map[string]any{
+		"Lang":    locale,
+		"Brand":   brand,
+		"Subject": msg.Subject,
+		"Content": content,
+		"Footer":  fmt.Sprintf(layoutFooter(locale), brand.Name),
+	}); err != nil {
+		return Message{}, err
+	}
+	msg.HTML = page.String()
+	return msg, nil
+}
+
+var footers = map[string]string{
+	"en": "Automated message from %s. For support, contact your workspace administrator.",
+	"es": "Mensaje automático de %s. Para soporte, contacta al administrador de tu espacio de trabajo.",
+}
+
+func layoutFooter(locale string) string {
+	for _, candidate := range localeCandidates(locale) {
+		if f, ok := footers[candidate]; ok {
+			return f
+		}
+	}
+	return footers[DefaultLocale]
+}
@@ -2,13 +2,13 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"backmanager/mailer"
 	"backmanager/routes"
 
 	"github.com/gin-contrib/cors"
@@ -28,6 +28,13 @@ func main() {
 	}
 	defer db.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(context.Background(), db, os.Args[2:]); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		jwtSecret = "change-me-in-env"
@@ -36,23 +43,36 @@ func main() {
 	if jwtIssuer == "" {
 		jwtIssuer = "backmanager"
 	}
-	jwtTTLHours := getEnvInt("JWT_TTL_HOURS", 24)
+	accessTTLMinutes := getEnvInt("JWT_ACCESS_TTL_MINUTES", 15)
+	refreshTTLHours := getEnvInt("JWT_REFRESH_TTL_HOURS", 24*30)
 	systemAdmins := splitCSV(os.Getenv("SYSTEM_ADMIN_EMAILS"))
 
-	svc := routes.NewService(db, []byte(jwtSecret), jwtIssuer, time.Duration(jwtTTLHours)*time.Hour, systemAdmins)
-
-	if err := svc.EnsureBaseTables(context.Background()); err != nil {
-		log.Fatalf("schema init failed: %v", err)
+	svc := routes.NewService(db, []byte(jwtSecret), jwtIssuer, time.Duration(accessTTLMinutes)*time.Minute, time.Duration(refreshTTLHours)*time.Hour, systemAdmins)
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		svc.Attempts = routes.NewPostgresAttemptStore(db)
 	}
-	if err := svc.EnsureTasksTable(); err != nil {
-		log.Fatalf("tasks schema init failed: %v", err)
+	mailTransport, err := mailer.New(mailer.ConfigFromEnv())
+	if err != nil {
+		log.Fatal(err)
 	}
-	if err := svc.EnsureTimesheetsTable(); err != nil {
-		log.Fatalf("timesheets schema init failed: %v", err)
+	svc.Mailer = mailTransport
+	svc.TimerIdleLimit = time.Duration(getEnvInt("TIMER_IDLE_HOURS", 12)) * time.Hour
+
+	if os.Getenv("MIGRATE_ON_BOOT") != "false" {
+		if err := runMigrateCommand(context.Background(), db, []string{"up"}); err != nil {
+			log.Fatalf("schema migration failed: %v", err)
+		}
 	}
 	if err := svc.EnsureSystemAdminRoles(context.Background()); err != nil {
 		log.Fatalf("system-admin role sync failed: %v", err)
 	}
+	go svc.RunEmailWorkers(context.Background(), getEnvInt("EMAIL_WORKERS", 2), 5*time.Second)
+	go svc.RunEventDispatcher(context.Background(), 2*time.Second)
+	go svc.RunWebhookWorkers(context.Background(), getEnvInt("WEBHOOK_WORKERS", 2), 5*time.Second)
+	go svc.RunNotificationListener(context.Background())
+	if os.Getenv("SCHEDULER_ENABLED") != "false" {
+		go svc.RunScheduler(context.Background(), 15*time.Second)
+	}
 
 	r := gin.Default()
 	r.Use(corsConfigFromEnv())
@@ -66,47 +86,116 @@ func main() {
 		auth.POST("/register", svc.Register)
 		auth.POST("/login", svc.Login)
 		auth.POST("/forgot-password", svc.ForgotPassword)
+		auth.POST("/reset-password", svc.ResetPassword)
+		auth.POST("/refresh", svc.RefreshSession)
+		auth.POST("/accept-invite", svc.AcceptInvite)
+		auth.POST("/mfa/challenge", svc.CompleteMFAChallenge)
 	}
 
 	api := r.Group("/api/v1")
 	api.Use(routes.AuthMiddleware(svc.JWTSecret, svc.JWTIssuer), routes.RequireActiveUser(svc), svc.AuditLogMiddleware())
 	{
-		api.GET("/users", svc.ListUsers)
-		api.GET("/sessions", svc.ListSessions)
-		api.PUT("/sessions/:id", svc.SessionAction)
+		api.POST("/auth/logout", svc.Logout)
+		api.PUT("/auth/password", svc.ChangePassword)
+		api.GET("/auth/mfa", svc.GetMFAStatus)
+		api.DELETE("/auth/mfa", svc.DisableMFA)
+		api.POST("/auth/mfa/enroll", svc.EnrollMFA)
+		api.POST("/auth/mfa/verify", svc.VerifyMFA)
+		api.POST("/auth/mfa/recovery-codes", svc.RegenerateRecoveryCodes)
+		api.PUT("/auth/mfa/policy", routes.RequirePermission(routes.PermUsersManage), svc.UpdateMFAPolicy)
+		api.GET("/email-branding", routes.RequirePermission(routes.PermBrandingManage), svc.GetEmailBranding)
+		api.PUT("/email-branding", routes.RequirePermission(routes.PermBrandingManage), svc.UpdateEmailBranding)
+		api.GET("/email-templates", routes.RequirePermission(routes.PermBrandingManage), svc.ListEmailTemplates)
+		api.GET("/email-templates/:name", routes.RequirePermission(routes.PermBrandingManage), svc.GetEmailTemplate)
+		api.PUT("/email-templates/:name", routes.RequirePermission(routes.PermBrandingManage), svc.SaveEmailTemplate)
+		api.DELETE("/email-templates/:name", routes.RequirePermission(routes.PermBrandingManage), svc.DeleteEmailTemplate)
+		api.POST("/email-templates/:name/preview", routes.RequirePermission(routes.PermBrandingManage), svc.PreviewEmailTemplate)
+		api.GET("/webhooks", routes.RequirePermission(routes.PermWebhooksManage), svc.ListWebhooks)
+		api.POST("/webhooks", routes.RequirePermission(routes.PermWebhooksManage), svc.CreateWebhook)
+		api.PUT("/webhooks/:id", routes.RequirePermission(routes.PermWebhooksManage), svc.UpdateWebhook)
+		api.DELETE("/webhooks/:id", routes.RequirePermission(routes.PermWebhooksManage), svc.DeleteWebhook)
+		api.POST("/webhooks/:id/rotate-secret", routes.RequirePermission(routes.PermWebhooksManage), svc.RotateWebhookSecret)
+		api.POST("/webhooks/:id/ping", routes.RequirePermission(routes.PermWebhooksManage), svc.PingWebhook)
+		api.GET("/webhooks/:id/deliveries", routes.RequirePermission(routes.PermWebhooksManage), svc.ListWebhookDeliveries)
+		api.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", routes.RequirePermission(routes.PermWebhooksManage), svc.RedeliverWebhook)
+		api.GET("/audit-log", routes.RequirePermission(routes.PermAuditRead), svc.ListAuditLog)
+		api.GET("/users", routes.RequirePermission(routes.PermUsersRead), svc.ListUsers)
+		api.PUT("/users/:id/role", routes.RequirePermission(routes.PermUsersManage), svc.UpdateUserRole)
+		api.GET("/invitations", routes.RequirePermission(routes.PermUsersManage), svc.ListInvitations)
+		api.POST("/invitations", routes.RequirePermission(routes.PermUsersManage), svc.CreateInvitation)
+		api.POST("/invitations/:id/resend", routes.RequirePermission(routes.PermUsersManage), svc.ResendInvitation)
+		api.DELETE("/invitations/:id", routes.RequirePermission(routes.PermUsersManage), svc.RevokeInvitation)
+		api.GET("/sessions", routes.RequirePermission(routes.PermSessionsManage), svc.ListSessions)
+		api.PUT("/sessions/:id", routes.RequirePermission(routes.PermSessionsManage), svc.SessionAction)
 		api.GET("/settings", svc.GetUserSettings)
 		api.PUT("/settings", svc.UpdateUserSettings)
 		api.GET("/profile", svc.GetUserProfile)
 		api.PUT("/profile", svc.UpdateUserProfile)
-		api.GET("/projects", svc.ListProjects)
-		api.POST("/projects", svc.CreateProject)
-		api.DELETE("/projects/:id", svc.DeleteProject)
-		api.GET("/tasks", svc.ListTasks)
-		api.POST("/tasks", svc.CreateTask)
-		api.PUT("/tasks/:id", svc.UpdateTask)
-		api.DELETE("/tasks/:id", svc.DeleteTask)
-		fmt.Printf("Registered DELETE /tasks/:id route\n")
-		api.GET("/forum/posts", svc.ListForumPosts)
-		api.POST("/forum/posts", svc.CreateForumPost)
-		api.DELETE("/forum/posts/:id", svc.DeleteForumPost)
-		api.GET("/code-shares", svc.ListCodeShares)
-		api.POST("/code-shares", svc.CreateCodeShare)
-		api.PUT("/code-shares/:id", svc.UpdateCodeShare)
-		api.DELETE("/code-shares/:id", svc.DeleteCodeShare)
-		api.GET("/issues", svc.ListIssues)
-		api.POST("/issues", svc.CreateIssue)
-		api.GET("/timesheets", svc.ListTimesheets)
-		api.POST("/timesheets", svc.CreateTimesheet)
-		api.DELETE("/timesheets/:id", svc.DeleteTimesheet)
-		api.GET("/approvals/requests", svc.ListApprovalRequests)
-		api.POST("/approvals/requests", svc.CreateApprovalRequest)
-		api.PUT("/approvals/requests/:id/action", svc.ActionApprovalRequest)
-		api.DELETE("/approvals/requests/:id", svc.DeleteApprovalRequest)
+		api.GET("/projects", routes.RequirePermission(routes.PermProjectsRead), svc.ListProjects)
+		api.POST("/projects", routes.RequirePermission(routes.PermProjectsCreate), svc.CreateProject)
+		api.DELETE("/projects/:id", routes.RequirePermission(routes.PermProjectsDelete), svc.DeleteProject)
+		api.GET("/tasks", routes.RequirePermission(routes.PermTasksRead), svc.ListTasks)
+		api.POST("/tasks", routes.RequirePermission(routes.PermTasksCreate), svc.CreateTask)
+		api.PUT("/tasks/:id", routes.RequirePermission(routes.PermTasksUpdate), svc.UpdateTask)
+		api.DELETE("/tasks/:id", routes.RequirePermission(routes.PermTasksDelete), svc.DeleteTask)
+		api.GET("/tasks/:id/history", routes.RequirePermission(routes.PermTasksRead), svc.TaskStatusHistory)
+		api.GET("/tasks/:id/subtasks", routes.RequirePermission(routes.PermTasksRead), svc.ListSubtasks)
+		api.POST("/tasks/:id/subtasks", routes.RequirePermission(routes.PermTasksUpdate), svc.CreateSubtask)
+		api.POST("/tasks/:id/subtasks/reorder", routes.RequirePermission(routes.PermTasksUpdate), svc.ReorderSubtasks)
+		api.PUT("/tasks/:id/subtasks/:subtask_id", routes.RequirePermission(routes.PermTasksUpdate), svc.UpdateSubtask)
+		api.DELETE("/tasks/:id/subtasks/:subtask_id", routes.RequirePermission(routes.PermTasksUpdate), svc.DeleteSubtask)
+		api.GET("/forum/posts", routes.RequirePermission(routes.PermForumRead), svc.ListForumPosts)
+		api.POST("/forum/posts", routes.RequirePermission(routes.PermForumCreate), svc.CreateForumPost)
+		api.DELETE("/forum/posts/:id", routes.RequirePermission(routes.PermForumCreate), svc.DeleteForumPost)
+		api.GET("/code-shares", routes.RequirePermission(routes.PermCodeSharesRead), svc.ListCodeShares)
+		api.POST("/code-shares", routes.RequirePermission(routes.PermCodeSharesWrite), svc.CreateCodeShare)
+		api.PUT("/code-shares/:id", routes.RequirePermission(routes.PermCodeSharesWrite), svc.UpdateCodeShare)
+		api.DELETE("/code-shares/:id", routes.RequirePermission(routes.PermCodeSharesWrite), svc.DeleteCodeShare)
+		api.GET("/issues", routes.RequirePermission(routes.PermIssuesRead), svc.ListIssues)
+		api.POST("/issues", routes.RequirePermission(routes.PermIssuesCreate), svc.CreateIssue)
+		api.GET("/timesheets", routes.RequirePermission(routes.PermTimesheetsRead), svc.ListTimesheets)
+		api.POST("/timesheets", routes.RequirePermission(routes.PermTimesheetsCreate), svc.CreateTimesheet)
+		api.PUT("/timesheets/:id", routes.RequirePermission(routes.PermTimesheetsCreate), svc.UpdateTimesheet)
+		api.DELETE("/timesheets/:id", routes.RequirePermission(routes.PermTimesheetsCreate), svc.DeleteTimesheet)
+		api.POST("/timesheets/submit", routes.RequirePermission(routes.PermTimesheetsCreate), svc.SubmitTimesheetWeek)
+		api.GET("/timesheets/submissions", routes.RequirePermission(routes.PermTimesheetsRead), svc.ListTimesheetSubmissions)
+		api.POST("/timesheets/submissions/:id/reopen", routes.RequirePermission(routes.PermApprovalsRead), svc.ReopenTimesheetSubmission)
+		api.GET("/timesheet-policy", routes.RequirePermission(routes.PermTimesheetsRead), svc.GetTimesheetPolicy)
+		api.PUT("/timesheet-policy", routes.RequirePermission(routes.PermTimesheetsManage), svc.UpdateTimesheetPolicy)
+		api.GET("/timers/current", routes.RequirePermission(routes.PermTimesheetsCreate), svc.CurrentTimer)
+		api.POST("/timers/start", routes.RequirePermission(routes.PermTimesheetsCreate), svc.StartTimer)
+		api.POST("/timers/stop", routes.RequirePermission(routes.PermTimesheetsCreate), svc.StopTimer)
+		api.POST("/timers/heartbeat", routes.RequirePermission(routes.PermTimesheetsCreate), svc.HeartbeatTimer)
+		api.GET("/billing/rates", routes.RequirePermission(routes.PermBillingManage), svc.ListBillingRates)
+		api.POST("/billing/rates", routes.RequirePermission(routes.PermBillingManage), svc.CreateBillingRate)
+		api.PUT("/billing/rates/:id", routes.RequirePermission(routes.PermBillingManage), svc.UpdateBillingRate)
+		api.DELETE("/billing/rates/:id", routes.RequirePermission(routes.PermBillingManage), svc.DeleteBillingRate)
+		api.GET("/reports/billing", routes.RequirePermission(routes.PermBillingManage), svc.BillingReport)
+		api.GET("/approvals/requests", routes.RequirePermission(routes.PermApprovalsRead), svc.ListApprovalRequests)
+		api.POST("/approvals/requests", routes.RequirePermission(routes.PermApprovalsCreate), svc.CreateApprovalRequest)
+		api.PUT("/approvals/requests/:id/action", routes.RequirePermission(routes.PermApprovalsAct), svc.ActionApprovalRequest)
+		api.DELETE("/approvals/requests/:id", routes.RequirePermission(routes.PermApprovalsDelete), svc.DeleteApprovalRequest)
 		api.POST("/notifications/test", svc.TestNotification)
 		api.GET("/notifications", svc.ListNotifications)
+		api.GET("/notifications/stream", svc.StreamNotifications)
+		api.GET("/notifications/unread-count", svc.NotificationUnreadCount)
+		api.GET("/notifications/preferences", svc.GetNotificationPreferences)
+		api.PUT("/notifications/preferences", svc.UpdateNotificationPreferences)
+		api.PUT("/notifications/read-all", svc.MarkAllNotificationsRead)
 		api.PUT("/notifications/:id/read", svc.MarkNotificationRead)
+		api.PUT("/notifications/:id/archive", svc.ArchiveNotification)
+		api.DELETE("/notifications/:id", svc.DeleteNotification)
 		api.POST("/support/request", svc.SupportRequest)
-		api.PUT("/projects/:id", svc.UpdateProject)
+		api.PUT("/projects/:id", routes.RequirePermission(routes.PermProjectsUpdate), svc.UpdateProject)
+		api.GET("/projects/:id/schedule", routes.RequirePermission(routes.PermProjectsRead), svc.ProjectSchedule)
+		api.GET("/projects/:id/history", routes.RequirePermission(routes.PermProjectsRead), svc.ProjectStatusHistory)
+		api.GET("/workflows", routes.RequirePermission(routes.PermProjectsRead), svc.ListWorkflows)
+		api.POST("/workflows", routes.RequirePermission(routes.PermWorkflowsManage), svc.CreateWorkflow)
+		api.PUT("/workflows/:id", routes.RequirePermission(routes.PermWorkflowsManage), svc.UpdateWorkflow)
+		api.DELETE("/workflows/:id", routes.RequirePermission(routes.PermWorkflowsManage), svc.DeleteWorkflow)
+		api.GET("/projects/:id/dependencies", routes.RequirePermission(routes.PermProjectsRead), svc.ListDependencies)
+		api.POST("/projects/:id/dependencies", routes.RequirePermission(routes.PermTasksUpdate), svc.CreateDependency)
+		api.DELETE("/projects/:id/dependencies/:dependency_id", routes.RequirePermission(routes.PermTasksUpdate), svc.DeleteDependency)
 	}
 
 	system := api.Group("/system")
@@ -121,6 +210,11 @@ func main() {
 		system.GET("/tenants", svc.SystemTenants)
 		system.POST("/tenants", svc.CreateTenant)
 		system.PUT("/tenants/:id", svc.UpdateTenant)
+		system.GET("/email-outbox", svc.SystemEmailOutbox)
+		system.POST("/email-outbox/:id/retry", svc.RetryOutboxMessage)
+		system.GET("/jobs", svc.SystemJobs)
+		system.GET("/event-subscribers", svc.SystemEventSubscribers)
+		system.POST("/jobs/:name/run", svc.RunJobNow)
 	}
 
 	port := os.Getenv("PORT")
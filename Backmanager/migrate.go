@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+
+	"backmanager/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// runMigrateCommand implements `backmanager migrate up|down [steps]|status`.
+func runMigrateCommand(ctx context.Context, db *pgxpool.Pool, args []string) error {
+	runner, err := migrations.NewRunner(db)
+	if err != nil {
+		return err
+	}
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	switch cmd {
+	case "up":
+		ran, err := runner.Up(ctx)
+		for _, m := range ran {
+			log.Printf("applied migration %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ran) == 0 {
+			log.Printf("schema is up to date")
+		}
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := runner.Down(ctx, steps)
+		for _, m := range reverted {
+			log.Printf("reverted migration %04d_%s", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		items, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			applied := "-"
+			if item.AppliedAt != nil {
+				applied = item.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-9s  %-19s  %s\n", item.Version, item.State, applied, item.Name)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down or status)", cmd)
+	}
+}
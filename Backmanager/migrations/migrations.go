@@ -0,0 +1,325 @@
+// Package migrations applies the versioned SQL files embedded under sql/.
+//
+// Files are named NNNN_description.up.sql / NNNN_description.down.sql and are
+// applied in version order, each inside its own transaction. Applied versions
+// are recorded in schema_migrations together with a checksum of the up file so
+// edits to already-applied migrations are detected instead of silently ignored.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockKey is the pg_advisory_lock key that serialises migration runs across
+// replicas ("backmgr" in ASCII).
+const lockKey int64 = 0x6261636b6d6772
+
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+type Runner struct {
+	DB         *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewRunner(db *pgxpool.Pool) (*Runner, error) {
+	items, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{DB: db, migrations: items}, nil
+}
+
+// Load reads and orders the embedded migration files.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s: expected .up.sql or .down.sql suffix", name)
+		}
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		rawVersion, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected NNNN_name prefix", name)
+		}
+		version, err := strconv.Atoi(rawVersion)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", name)
+		}
+		body, err := fs.ReadFile(files, path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %04d: conflicting names %q and %q", version, m.Name, label)
+		}
+		if direction == "up" {
+			sum := sha256.Sum256(body)
+			m.Up = string(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %04d_%s: missing up file", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+type appliedMigration struct {
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Up applies every pending migration and returns the ones it ran.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	ran := make([]Migration, 0)
+	err := r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := r.verifyChecksums(applied); err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			if _, ok := applied[m.Version]; ok {
+				continue
+			}
+			if err := applyUp(ctx, conn, m); err != nil {
+				return err
+			}
+			ran = append(ran, m)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+// Down reverts the most recently applied migrations, newest first.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		steps = 1
+	}
+	reverted := make([]Migration, 0, steps)
+	err := r.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(r.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := r.migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(m.Down) == "" {
+				return fmt.Errorf("migration %04d_%s has no down file", m.Version, m.Name)
+			}
+			if err := applyDown(ctx, conn, m); err != nil {
+				return err
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports every known migration as applied, pending or modified, plus
+// any version recorded in the database that no longer has a file.
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	conn, err := r.DB.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Release()
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := loadApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Status, 0, len(r.migrations))
+	known := make(map[int]struct{}, len(r.migrations))
+	for _, m := range r.migrations {
+		known[m.Version] = struct{}{}
+		item := Status{Version: m.Version, Name: m.Name, State: "pending"}
+		if a, ok := applied[m.Version]; ok {
+			at := a.AppliedAt
+			item.AppliedAt = &at
+			item.State = "applied"
+			if a.Checksum != m.Checksum {
+				item.State = "modified"
+			}
+		}
+		out = append(out, item)
+	}
+	for version, a := range applied {
+		if _, ok := known[version]; ok {
+			continue
+		}
+		at := a.AppliedAt
+		out = append(out, Status{Version: version, State: "missing", AppliedAt: &at})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+func (r *Runner) verifyChecksums(applied map[int]appliedMigration) error {
+	for _, m := range r.migrations {
+		a, ok := applied[m.Version]
+		if !ok {
+			continue
+		}
+		if a.Checksum != m.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied (checksum mismatch)", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// withLock holds a session-level advisory lock on a dedicated connection for
+// the duration of fn so concurrent replicas migrate one at a time.
+func (r *Runner) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := r.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+	}()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func ensureTable(ctx context.Context, conn *pgxpool.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			execution_ms BIGINT NOT NULL DEFAULT 0
+		)
+	`)
+	return err
+}
+
+func loadApplied(ctx context.Context, conn *pgxpool.Conn) (map[int]appliedMigration, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var item appliedMigration
+		if err := rows.Scan(&version, &item.Checksum, &item.AppliedAt); err != nil {
+			return nil, err
+		}
+		out[version] = item
+	}
+	return out, rows.Err()
+}
+
+func applyUp(ctx context.Context, conn *pgxpool.Conn, m Migration) error {
+	start := time.Now()
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, m.Up); err != nil {
+		return fmt.Errorf("migration %04d_%s up: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO schema_migrations (version, name, checksum, execution_ms)
+		VALUES ($1, $2, $3, $4)
+	`, m.Version, m.Name, m.Checksum, time.Since(start).Milliseconds()); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func applyDown(ctx context.Context, conn *pgxpool.Conn, m Migration) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, m.Down); err != nil {
+		return fmt.Errorf("migration %04d_%s down: %w", m.Version, m.Name, err)
+	}
+	cmd, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return errors.New("migration bookkeeping row disappeared during down")
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS timesheets;
+DROP TABLE IF EXISTS tasks;
+DROP TABLE IF EXISTS approval_requests;
+DROP TABLE IF EXISTS user_profiles;
+DROP TABLE IF EXISTS user_settings;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS issues;
+DROP TABLE IF EXISTS code_shares;
+DROP TABLE IF EXISTS forum_posts;
+DROP TABLE IF EXISTS system_updates;
+DROP TABLE IF EXISTS system_logs;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS tenants;
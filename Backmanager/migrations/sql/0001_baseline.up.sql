@@ -0,0 +1,285 @@
+-- Baseline schema. Every statement is idempotent so databases created by the
+-- former boot-time DDL converge to the same shape when this is first applied.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id BIGSERIAL PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    logo_url TEXT NOT NULL DEFAULT '',
+    max_sessions INT NOT NULL DEFAULT 5,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS logo_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS max_sessions INT NOT NULL DEFAULT 5;
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGSERIAL PRIMARY KEY,
+    public_id TEXT,
+    tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    email TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'org_admin',
+    blocked BOOLEAN NOT NULL DEFAULT false,
+    last_login_at TIMESTAMPTZ,
+    last_login_user_agent TEXT NOT NULL DEFAULT '',
+    last_login_ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, email)
+);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'org_admin';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS blocked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_ip TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_id TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_public_id ON users (public_id) WHERE public_id IS NOT NULL;
+
+DO $$
+DECLARE
+    row_record RECORD;
+    candidate TEXT;
+BEGIN
+    FOR row_record IN
+        SELECT id
+        FROM users
+        WHERE public_id IS NULL OR public_id !~ '^[0-9]{7}$'
+    LOOP
+        LOOP
+            candidate := LPAD((FLOOR(RANDOM() * 9000000) + 1000000)::TEXT, 7, '0');
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM users WHERE public_id = candidate);
+        END LOOP;
+        UPDATE users SET public_id = candidate WHERE id = row_record.id;
+    END LOOP;
+END $$;
+
+CREATE TABLE IF NOT EXISTS projects (
+    id BIGSERIAL PRIMARY KEY,
+    project_code TEXT NOT NULL DEFAULT '',
+    tenant_id TEXT NOT NULL,
+    name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'active',
+    assignees JSONB NOT NULL DEFAULT '[]'::jsonb,
+    start_date DATE,
+    due_date DATE,
+    duration_days INT NOT NULL DEFAULT 1,
+    team_size INT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS assignees JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS project_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS start_date DATE;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS duration_days INT NOT NULL DEFAULT 1;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS team_size INT NOT NULL DEFAULT 1;
+
+CREATE INDEX IF NOT EXISTS idx_projects_tenant_id ON projects (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_email ON users (tenant_id, email);
+
+CREATE TABLE IF NOT EXISTS system_logs (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_slug TEXT,
+    user_email TEXT,
+    role TEXT,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status_code INT NOT NULL,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_system_logs_created_at ON system_logs (created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_system_logs_tenant_slug ON system_logs (tenant_slug);
+
+CREATE TABLE IF NOT EXISTS system_updates (
+    id BIGSERIAL PRIMARY KEY,
+    scheduled_date DATE NOT NULL,
+    title TEXT NOT NULL,
+    feature_brief TEXT NOT NULL,
+    expectations TEXT NOT NULL,
+    created_by_email TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_system_updates_scheduled_date ON system_updates (scheduled_date);
+
+CREATE TABLE IF NOT EXISTS forum_posts (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    author_email TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_forum_posts_tenant_id ON forum_posts (tenant_id);
+
+CREATE TABLE IF NOT EXISTS code_shares (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    author_email TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    language TEXT NOT NULL,
+    code TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_code_shares_tenant_id ON code_shares (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_code_shares_updated_at ON code_shares (tenant_id, (COALESCE(updated_at, created_at)) DESC);
+
+CREATE TABLE IF NOT EXISTS issues (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    severity TEXT NOT NULL DEFAULT 'medium',
+    status TEXT NOT NULL DEFAULT 'open',
+    created_by_email TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_issues_tenant_id ON issues (tenant_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    recipient_email TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'info',
+    title TEXT NOT NULL,
+    detail TEXT NOT NULL,
+    meta JSONB NOT NULL DEFAULT '{}'::jsonb,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_recipient_created
+    ON notifications (tenant_id, recipient_email, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread
+    ON notifications (tenant_id, recipient_email, read_at);
+
+CREATE TABLE IF NOT EXISTS user_settings (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_email TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    week_starts_on TEXT NOT NULL DEFAULT 'monday',
+    reminder_frequency TEXT NOT NULL DEFAULT 'daily',
+    reminder_days JSONB NOT NULL DEFAULT '[]'::jsonb,
+    reminder_time TEXT NOT NULL DEFAULT '09:00',
+    reminders_enabled BOOLEAN NOT NULL DEFAULT true,
+    daily_digest BOOLEAN NOT NULL DEFAULT true,
+    overdue_alerts BOOLEAN NOT NULL DEFAULT true,
+    email_summaries BOOLEAN NOT NULL DEFAULT true,
+    private_projects BOOLEAN NOT NULL DEFAULT true,
+    log_retention_days INT NOT NULL DEFAULT 180,
+    admins_can_export BOOLEAN NOT NULL DEFAULT true,
+    approval_pipeline TEXT NOT NULL DEFAULT 'simple',
+    approval_email_notifications BOOLEAN NOT NULL DEFAULT true,
+    approval_approvers JSONB NOT NULL DEFAULT '[]'::jsonb,
+    last_reminder_sent_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, user_email)
+);
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS approval_pipeline TEXT NOT NULL DEFAULT 'simple';
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS approval_email_notifications BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS approval_approvers JSONB NOT NULL DEFAULT '[]'::jsonb;
+CREATE INDEX IF NOT EXISTS idx_user_settings_tenant_user ON user_settings (tenant_id, user_email);
+
+CREATE TABLE IF NOT EXISTS user_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    user_email TEXT NOT NULL,
+    display_name TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    organization_name TEXT NOT NULL DEFAULT '',
+    town TEXT NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, user_email)
+);
+CREATE INDEX IF NOT EXISTS idx_user_profiles_tenant_user ON user_profiles (tenant_id, user_email);
+
+CREATE TABLE IF NOT EXISTS approval_requests (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE SET NULL,
+    project_name TEXT NOT NULL DEFAULT '',
+    billable_hours NUMERIC(8,2) NOT NULL DEFAULT 0,
+    requested_by_email TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    approval_mode TEXT NOT NULL DEFAULT 'simple',
+    approver_emails JSONB NOT NULL DEFAULT '[]'::jsonb,
+    current_step INT NOT NULL DEFAULT 0,
+    response_token TEXT NOT NULL DEFAULT '',
+    required_approvals INT NOT NULL DEFAULT 1,
+    approvals JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_approval_requests_tenant_created ON approval_requests (tenant_id, created_at DESC);
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS approver_emails JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS current_step INT NOT NULL DEFAULT 0;
+ALTER TABLE approval_requests ADD COLUMN IF NOT EXISTS response_token TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id BIGSERIAL PRIMARY KEY,
+    task_code TEXT NOT NULL DEFAULT '',
+    tenant_id TEXT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+    phase TEXT NOT NULL DEFAULT '',
+    title TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'todo',
+    priority TEXT NOT NULL DEFAULT 'medium',
+    subtasks JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS subtasks JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS task_code TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS phase TEXT NOT NULL DEFAULT '';
+-- Legacy databases stored tasks.project_id as TEXT and subtasks as a scalar.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM information_schema.columns
+        WHERE table_schema = 'public' AND table_name = 'tasks' AND column_name = 'project_id' AND data_type = 'text'
+    ) THEN
+        ALTER TABLE tasks
+        ALTER COLUMN project_id TYPE BIGINT
+        USING CASE
+            WHEN project_id IS NULL OR trim(project_id) = '' THEN NULL
+            WHEN project_id ~ '^[0-9]+$' THEN project_id::BIGINT
+            ELSE NULL
+        END;
+    END IF;
+END $$;
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM information_schema.columns
+        WHERE table_schema = 'public' AND table_name = 'tasks' AND column_name = 'subtasks' AND data_type <> 'jsonb'
+    ) THEN
+        ALTER TABLE tasks
+        ALTER COLUMN subtasks TYPE JSONB
+        USING
+            CASE
+                WHEN subtasks IS NULL THEN '[]'::jsonb
+                ELSE to_jsonb(ARRAY[subtasks::text])
+            END;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id ON tasks (tenant_id);
+
+CREATE TABLE IF NOT EXISTS timesheets (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE SET NULL,
+    task_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL,
+    work_date DATE NOT NULL,
+    hours NUMERIC(5,2) NOT NULL,
+    billable BOOLEAN NOT NULL DEFAULT true,
+    notes TEXT NOT NULL DEFAULT '',
+    created_by_email TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_timesheets_tenant_date ON timesheets (tenant_id, work_date DESC);
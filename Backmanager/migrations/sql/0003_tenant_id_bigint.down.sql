@@ -0,0 +1,32 @@
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'timesheets', 'approval_requests', 'issues', 'tasks', 'projects',
+        'forum_posts', 'code_shares', 'notifications', 'user_settings', 'user_profiles'
+    ]
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN tenant_slug TEXT', tbl);
+        EXECUTE format('UPDATE %I x SET tenant_slug = t.slug FROM tenants t WHERE t.id = x.tenant_id', tbl);
+        EXECUTE format('ALTER TABLE %I DROP COLUMN tenant_id', tbl);
+        EXECUTE format('ALTER TABLE %I RENAME COLUMN tenant_slug TO tenant_id', tbl);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET NOT NULL', tbl);
+    END LOOP;
+END $$;
+
+CREATE INDEX idx_projects_tenant_id ON projects (tenant_id);
+CREATE INDEX idx_tasks_tenant_id ON tasks (tenant_id);
+CREATE INDEX idx_timesheets_tenant_date ON timesheets (tenant_id, work_date DESC);
+CREATE INDEX idx_forum_posts_tenant_id ON forum_posts (tenant_id);
+CREATE INDEX idx_code_shares_tenant_id ON code_shares (tenant_id);
+CREATE INDEX idx_code_shares_updated_at ON code_shares (tenant_id, (COALESCE(updated_at, created_at)) DESC);
+CREATE INDEX idx_issues_tenant_id ON issues (tenant_id);
+CREATE INDEX idx_notifications_tenant_recipient_created ON notifications (tenant_id, recipient_email, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications (tenant_id, recipient_email, read_at);
+CREATE INDEX idx_approval_requests_tenant_created ON approval_requests (tenant_id, created_at DESC);
+ALTER TABLE user_settings ADD CONSTRAINT user_settings_tenant_id_user_email_key UNIQUE (tenant_id, user_email);
+ALTER TABLE user_profiles ADD CONSTRAINT user_profiles_tenant_id_user_email_key UNIQUE (tenant_id, user_email);
+
+-- legacy_orphaned_rows is kept: it is the only copy of the rows the up
+-- migration removed.
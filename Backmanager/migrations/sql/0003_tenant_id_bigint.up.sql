@@ -0,0 +1,64 @@
+-- Domain tables used to key on the tenant slug. Re-key them on tenants.id so a
+-- slug rename is a single-row change. Rows whose slug no longer matches any
+-- tenant (left behind by earlier renames) are preserved in
+-- legacy_orphaned_rows rather than dropped.
+
+CREATE TABLE IF NOT EXISTS legacy_orphaned_rows (
+    id BIGSERIAL PRIMARY KEY,
+    table_name TEXT NOT NULL,
+    tenant_slug TEXT NOT NULL,
+    row_data JSONB NOT NULL,
+    moved_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Tasks cascade with their project. A task whose own slug still resolves but
+-- whose project is orphaned would vanish with it, so preserve those first;
+-- tasks that are orphaned themselves are preserved by the loop below.
+INSERT INTO legacy_orphaned_rows (table_name, tenant_slug, row_data)
+SELECT 'tasks', tk.tenant_id, to_jsonb(tk)
+FROM tasks tk
+JOIN projects p ON p.id = tk.project_id
+WHERE NOT EXISTS (SELECT 1 FROM tenants t WHERE t.slug = p.tenant_id)
+  AND EXISTS (SELECT 1 FROM tenants t WHERE t.slug = tk.tenant_id);
+
+-- Children before parents so orphaned children are preserved before any
+-- cascade from their parent could remove them.
+DO $$
+DECLARE
+    tbl TEXT;
+BEGIN
+    FOREACH tbl IN ARRAY ARRAY[
+        'timesheets', 'approval_requests', 'issues', 'tasks', 'projects',
+        'forum_posts', 'code_shares', 'notifications', 'user_settings', 'user_profiles'
+    ]
+    LOOP
+        EXECUTE format('ALTER TABLE %I ADD COLUMN tenant_ref BIGINT', tbl);
+        EXECUTE format('UPDATE %I x SET tenant_ref = t.id FROM tenants t WHERE t.slug = x.tenant_id', tbl);
+        EXECUTE format(
+            'INSERT INTO legacy_orphaned_rows (table_name, tenant_slug, row_data) SELECT %L, x.tenant_id, to_jsonb(x) - ''tenant_ref'' FROM %I x WHERE x.tenant_ref IS NULL',
+            tbl, tbl
+        );
+        EXECUTE format('DELETE FROM %I WHERE tenant_ref IS NULL', tbl);
+        EXECUTE format('ALTER TABLE %I DROP COLUMN tenant_id', tbl);
+        EXECUTE format('ALTER TABLE %I RENAME COLUMN tenant_ref TO tenant_id', tbl);
+        EXECUTE format('ALTER TABLE %I ALTER COLUMN tenant_id SET NOT NULL', tbl);
+        EXECUTE format(
+            'ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE',
+            tbl, tbl || '_tenant_id_fkey'
+        );
+    END LOOP;
+END $$;
+
+-- Dropping the text column dropped every index and constraint built on it.
+CREATE INDEX idx_projects_tenant_id ON projects (tenant_id);
+CREATE INDEX idx_tasks_tenant_id ON tasks (tenant_id);
+CREATE INDEX idx_timesheets_tenant_date ON timesheets (tenant_id, work_date DESC);
+CREATE INDEX idx_forum_posts_tenant_id ON forum_posts (tenant_id);
+CREATE INDEX idx_code_shares_tenant_id ON code_shares (tenant_id);
+CREATE INDEX idx_code_shares_updated_at ON code_shares (tenant_id, (COALESCE(updated_at, created_at)) DESC);
+CREATE INDEX idx_issues_tenant_id ON issues (tenant_id);
+CREATE INDEX idx_notifications_tenant_recipient_created ON notifications (tenant_id, recipient_email, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications (tenant_id, recipient_email, read_at);
+CREATE INDEX idx_approval_requests_tenant_created ON approval_requests (tenant_id, created_at DESC);
+ALTER TABLE user_settings ADD CONSTRAINT user_settings_tenant_id_user_email_key UNIQUE (tenant_id, user_email);
+ALTER TABLE user_profiles ADD CONSTRAINT user_profiles_tenant_id_user_email_key UNIQUE (tenant_id, user_email);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+UPDATE users SET role = 'org_admin' WHERE role IN ('owner', 'manager', 'member', 'viewer', 'guest');
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'org_admin';
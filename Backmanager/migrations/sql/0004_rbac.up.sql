@@ -0,0 +1,15 @@
+-- Every tenant keeps at least one owner: promote its earliest org_admin.
+UPDATE users
+SET role = 'owner'
+WHERE id IN (
+    SELECT DISTINCT ON (tenant_id) id
+    FROM users
+    WHERE role = 'org_admin'
+    ORDER BY tenant_id, created_at, id
+);
+
+UPDATE users SET role = 'member' WHERE trim(role) = '';
+
+ALTER TABLE users ALTER COLUMN role SET DEFAULT 'member';
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('system_admin', 'owner', 'org_admin', 'manager', 'member', 'viewer', 'guest'));
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS invitations (
+    id BIGSERIAL PRIMARY KEY,
+    public_id TEXT NOT NULL UNIQUE,
+    tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    role TEXT NOT NULL DEFAULT 'member',
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    accepted_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    CONSTRAINT invitations_role_check
+        CHECK (role IN ('owner', 'org_admin', 'manager', 'member', 'viewer', 'guest'))
+);
+CREATE INDEX IF NOT EXISTS idx_invitations_tenant_created ON invitations (tenant_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_pending_email
+    ON invitations (tenant_id, lower(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
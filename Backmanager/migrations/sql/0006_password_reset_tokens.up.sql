@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    requested_ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_email_created ON password_reset_tokens (lower(email), created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_ip_created ON password_reset_tokens (requested_ip, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user ON password_reset_tokens (user_id) WHERE used_at IS NULL;
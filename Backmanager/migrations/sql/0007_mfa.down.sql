@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS mfa_challenges;
+DROP TABLE IF EXISTS user_mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_mfa_policy_check;
+ALTER TABLE tenants DROP COLUMN IF EXISTS mfa_policy;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS rate_limit_buckets;
+DROP INDEX IF EXISTS idx_system_logs_event;
+ALTER TABLE system_logs DROP COLUMN IF EXISTS event;
+ALTER TABLE users DROP COLUMN IF EXISTS locked_until;
+ALTER TABLE users DROP COLUMN IF EXISTS failed_login_count;
@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS failed_login_count INT NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ;
+
+-- Security events (lockouts, unlocks) share system_logs with request entries;
+-- event is NULL for ordinary requests.
+ALTER TABLE system_logs ADD COLUMN IF NOT EXISTS event TEXT;
+CREATE INDEX IF NOT EXISTS idx_system_logs_event ON system_logs (event, created_at DESC) WHERE event IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets (updated_at);
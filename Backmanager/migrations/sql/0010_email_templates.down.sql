@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_template_overrides;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS locale;
+ALTER TABLE tenants DROP COLUMN IF EXISTS brand_color;
@@ -0,0 +1,18 @@
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS brand_color TEXT;
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en';
+
+-- Per-tenant replacements for the templates embedded in the mailer package.
+-- Rows are keyed by template name and locale; missing rows fall back to the
+-- embedded default.
+CREATE TABLE IF NOT EXISTS email_template_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    locale TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body_text TEXT NOT NULL,
+    body_html TEXT NOT NULL DEFAULT '',
+    updated_by_email TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (tenant_id, name, locale)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS overdue_alert_log;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS last_digest_sent_at;
+DROP TABLE IF EXISTS scheduled_jobs;
@@ -0,0 +1,28 @@
+-- State for the background jobs in routes/scheduler.go. Rows are registered by
+-- whichever replica holds the scheduler advisory lock.
+CREATE TABLE IF NOT EXISTS scheduled_jobs (
+    name TEXT PRIMARY KEY,
+    interval_seconds BIGINT NOT NULL,
+    last_started_at TIMESTAMPTZ,
+    last_finished_at TIMESTAMPTZ,
+    last_status TEXT,
+    last_error TEXT,
+    last_items INT NOT NULL DEFAULT 0,
+    last_duration_ms BIGINT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    run_count BIGINT NOT NULL DEFAULT 0,
+    failure_count BIGINT NOT NULL DEFAULT 0,
+    ran_by TEXT
+);
+
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS last_digest_sent_at TIMESTAMPTZ;
+
+-- One overdue alert per assignee, project and due date.
+CREATE TABLE IF NOT EXISTS overdue_alert_log (
+    tenant_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_email TEXT NOT NULL,
+    due_date DATE NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, project_id, user_email, due_date)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_notifications_recipient_id;
+DROP TRIGGER IF EXISTS notifications_notify ON notifications;
+DROP FUNCTION IF EXISTS notifications_notify();
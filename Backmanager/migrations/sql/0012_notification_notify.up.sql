@@ -0,0 +1,18 @@
+-- Wake SSE streams on every replica when a notification is inserted. The
+-- payload only identifies the recipient; streams read the row themselves.
+CREATE OR REPLACE FUNCTION notifications_notify() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('notifications', json_build_object(
+        'tenant_id', NEW.tenant_id,
+        'recipient', lower(NEW.recipient_email)
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS notifications_notify ON notifications;
+CREATE TRIGGER notifications_notify
+    AFTER INSERT ON notifications
+    FOR EACH ROW EXECUTE FUNCTION notifications_notify();
+
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient_id ON notifications (tenant_id, lower(recipient_email), id);
@@ -0,0 +1,3 @@
+ALTER TABLE user_settings DROP COLUMN IF EXISTS notification_channels;
+DROP INDEX IF EXISTS idx_notifications_inbox_unread;
+ALTER TABLE notifications DROP COLUMN IF EXISTS archived_at;
@@ -0,0 +1,11 @@
+-- Archived notifications drop out of the inbox and unread counts but are kept
+-- until deleted.
+ALTER TABLE notifications ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_inbox_unread
+    ON notifications (tenant_id, lower(recipient_email))
+    WHERE read_at IS NULL AND archived_at IS NULL;
+
+-- Per-type delivery channel: {"approval": "email", "summary": "none", ...}.
+-- Types missing from the map are delivered on every channel.
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS notification_channels JSONB NOT NULL DEFAULT '{}'::jsonb;
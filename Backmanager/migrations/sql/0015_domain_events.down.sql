@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS event_subscribers;
+DROP TABLE IF EXISTS events;
@@ -0,0 +1,44 @@
+-- Domain events written by handlers in the same transaction as the change
+-- they describe. txid lets the dispatcher in routes/events.go read events in
+-- commit-safe order: rows below the oldest running transaction can no longer
+-- be joined by an earlier-numbered event.
+CREATE TABLE IF NOT EXISTS events (
+    id BIGSERIAL PRIMARY KEY,
+    txid BIGINT NOT NULL DEFAULT txid_current(),
+    tenant_id BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    actor_email TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_events_txid_id ON events (txid, id);
+CREATE INDEX IF NOT EXISTS idx_events_tenant_created ON events (tenant_id, created_at DESC);
+
+-- One cursor per subscriber. The row lock doubles as the lease that keeps two
+-- replicas from running the same subscriber at once.
+CREATE TABLE IF NOT EXISTS event_subscribers (
+    name TEXT PRIMARY KEY,
+    last_txid BIGINT NOT NULL DEFAULT 0,
+    last_event_id BIGINT NOT NULL DEFAULT 0,
+    failures INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Human-readable trail of domain events, kept by the audit subscriber.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    event_id BIGINT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    actor_email TEXT NOT NULL DEFAULT '',
+    subject_type TEXT NOT NULL,
+    subject_id BIGINT,
+    summary TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_created ON audit_log (tenant_id, created_at DESC);
+
+-- The webhook subscriber skips events it already fanned out when a batch is
+-- replayed.
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries (webhook_id, event_id);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_tasks_due_date;
+DROP INDEX IF EXISTS idx_tasks_assignees;
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimated_hours;
+ALTER TABLE tasks DROP COLUMN IF EXISTS story_points;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_date;
+ALTER TABLE tasks DROP COLUMN IF EXISTS start_date;
+ALTER TABLE tasks DROP COLUMN IF EXISTS assignees;
+ALTER TABLE tasks DROP COLUMN IF EXISTS description;
@@ -0,0 +1,17 @@
+-- Task planning fields. Assignees are lowercase user emails, like
+-- projects.assignees.
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignees JSONB NOT NULL DEFAULT '[]'::jsonb;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS start_date DATE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_date DATE;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS story_points INT;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS estimated_hours NUMERIC(8,2);
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Existing closed tasks have no completion time; created_at is the best we have.
+UPDATE tasks
+SET completed_at = created_at
+WHERE completed_at IS NULL AND lower(trim(status)) IN ('done', 'completed', 'closed');
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignees ON tasks USING GIN (assignees);
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks (tenant_id, due_date) WHERE due_date IS NOT NULL;
@@ -0,0 +1,12 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS subtasks JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+UPDATE tasks t
+SET subtasks = s.titles
+FROM (
+    SELECT task_id, jsonb_agg(title ORDER BY position, id) AS titles
+    FROM task_subtasks
+    GROUP BY task_id
+) s
+WHERE s.task_id = t.id;
+
+DROP TABLE IF EXISTS task_subtasks;
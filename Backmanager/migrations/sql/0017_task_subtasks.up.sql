@@ -0,0 +1,26 @@
+-- Subtasks become rows with their own status, assignee and ordering. The old
+-- tasks.subtasks JSON list is converted in order and dropped.
+CREATE TABLE IF NOT EXISTS task_subtasks (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'todo',
+    assignee_email TEXT NOT NULL DEFAULT '',
+    position INT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_subtasks_task ON task_subtasks (task_id, position, id);
+
+INSERT INTO task_subtasks (tenant_id, task_id, title, position, created_at)
+SELECT t.tenant_id::bigint, t.id, trim(e.value), (e.ord - 1)::int, t.created_at
+FROM tasks t
+CROSS JOIN LATERAL jsonb_array_elements_text(
+    CASE WHEN jsonb_typeof(t.subtasks) = 'array' THEN t.subtasks ELSE '[]'::jsonb END
+) WITH ORDINALITY AS e(value, ord)
+WHERE trim(e.value) <> '';
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS subtasks;
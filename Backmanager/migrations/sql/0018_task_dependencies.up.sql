@@ -0,0 +1,18 @@
+-- Dependencies between tasks of one project. FS: the successor starts after
+-- the predecessor finishes; SS: it starts after the predecessor starts. lag_days
+-- shifts either by whole days.
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    project_id BIGINT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    predecessor_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    successor_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL DEFAULT 'FS' CHECK (kind IN ('FS', 'SS')),
+    lag_days INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (predecessor_id, successor_id),
+    CHECK (predecessor_id <> successor_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_project ON task_dependencies (project_id);
+CREATE INDEX IF NOT EXISTS idx_task_dependencies_successor ON task_dependencies (successor_id);
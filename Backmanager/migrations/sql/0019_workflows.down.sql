@@ -0,0 +1,3 @@
+ALTER TABLE projects DROP COLUMN IF EXISTS completed_at;
+DROP TABLE IF EXISTS status_history;
+DROP TABLE IF EXISTS workflows;
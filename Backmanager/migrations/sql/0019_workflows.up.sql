@@ -0,0 +1,40 @@
+-- Status workflows for tasks and projects. A row with project_id NULL is the
+-- tenant default for its entity; a project row overrides it for that project.
+-- states: [{"key": "todo", "label": "To do", "terminal": false}, ...] in order,
+-- the first being the initial state.
+-- transitions: [{"from": "todo", "to": "doing", "required_fields": ["assignees"]}].
+-- An empty transitions list allows any move between states.
+CREATE TABLE IF NOT EXISTS workflows (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+    entity TEXT NOT NULL CHECK (entity IN ('task', 'project')),
+    name TEXT NOT NULL DEFAULT '',
+    states JSONB NOT NULL DEFAULT '[]'::jsonb,
+    transitions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflows_scope ON workflows (tenant_id, entity, COALESCE(project_id, 0));
+
+CREATE TABLE IF NOT EXISTS status_history (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    entity TEXT NOT NULL,
+    entity_id BIGINT NOT NULL,
+    from_status TEXT NOT NULL DEFAULT '',
+    to_status TEXT NOT NULL,
+    actor_email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_status_history_entity ON status_history (tenant_id, entity, entity_id, created_at);
+
+-- Terminal-ness now depends on the workflow, so it is recorded when a status
+-- is set instead of being re-derived from the status text in every query.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+UPDATE projects
+SET completed_at = created_at
+WHERE completed_at IS NULL AND lower(trim(status)) IN ('done', 'completed', 'closed');
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_timesheets_submission;
+
+ALTER TABLE timesheets
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS submission_id,
+    DROP COLUMN IF EXISTS status;
+
+DROP TABLE IF EXISTS timesheet_submissions;
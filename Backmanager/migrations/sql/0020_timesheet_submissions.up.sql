@@ -0,0 +1,31 @@
+-- A submission groups one user's timesheet entries for one week and ties them
+-- to the approval request raised for that week. Entries are 'draft' until
+-- submitted, and 'locked' once the approval request is approved.
+CREATE TABLE IF NOT EXISTS timesheet_submissions (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    user_email TEXT NOT NULL,
+    week_start DATE NOT NULL,
+    week_end DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'submitted' CHECK (status IN ('submitted', 'approved', 'rejected', 'reopened')),
+    total_hours NUMERIC(8,2) NOT NULL DEFAULT 0,
+    billable_hours NUMERIC(8,2) NOT NULL DEFAULT 0,
+    approval_request_id BIGINT REFERENCES approval_requests(id) ON DELETE SET NULL,
+    submitted_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    approved_at TIMESTAMPTZ,
+    reopened_at TIMESTAMPTZ,
+    reopened_by_email TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timesheet_submissions_week
+    ON timesheet_submissions (tenant_id, lower(user_email), week_start);
+CREATE INDEX IF NOT EXISTS idx_timesheet_submissions_approval ON timesheet_submissions (approval_request_id);
+
+ALTER TABLE timesheets
+    ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'submitted', 'locked')),
+    ADD COLUMN IF NOT EXISTS submission_id BIGINT REFERENCES timesheet_submissions(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_timesheets_submission ON timesheets (submission_id);
@@ -0,0 +1,22 @@
+-- Line items of an approval request: the timesheet entries it covers, with
+-- hours snapshotted at request time. An entry may sit in only one active
+-- (pending or approved) request; rejection or reopening deactivates its rows.
+CREATE TABLE IF NOT EXISTS approval_request_entries (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    approval_request_id BIGINT NOT NULL REFERENCES approval_requests(id) ON DELETE CASCADE,
+    timesheet_id BIGINT REFERENCES timesheets(id) ON DELETE SET NULL,
+    user_email TEXT NOT NULL,
+    project_id BIGINT,
+    task_id BIGINT,
+    work_date DATE NOT NULL,
+    hours NUMERIC(5,2) NOT NULL,
+    billable BOOLEAN NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_approval_request_entries_request ON approval_request_entries (approval_request_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_approval_request_entries_active
+    ON approval_request_entries (timesheet_id)
+    WHERE active;
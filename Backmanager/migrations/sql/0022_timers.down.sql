@@ -0,0 +1,3 @@
+ALTER TABLE timesheets DROP COLUMN IF EXISTS timer_id;
+
+DROP TABLE IF EXISTS timers;
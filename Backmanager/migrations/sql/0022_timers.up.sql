@@ -0,0 +1,25 @@
+-- Running timers. A user has at most one timer with stopped_at NULL; stopping
+-- it writes one timesheet row per local day the timer spanned, linked back
+-- through timesheets.timer_id.
+CREATE TABLE IF NOT EXISTS timers (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    user_email TEXT NOT NULL,
+    project_id BIGINT REFERENCES projects(id) ON DELETE SET NULL,
+    task_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL,
+    billable BOOLEAN NOT NULL DEFAULT true,
+    notes TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_activity_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    stopped_at TIMESTAMPTZ,
+    auto_stopped BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_timers_running
+    ON timers (tenant_id, lower(user_email))
+    WHERE stopped_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_timers_activity ON timers (last_activity_at) WHERE stopped_at IS NULL;
+
+ALTER TABLE timesheets
+    ADD COLUMN IF NOT EXISTS timer_id BIGINT REFERENCES timers(id) ON DELETE SET NULL;
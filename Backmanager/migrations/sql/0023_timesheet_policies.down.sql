@@ -0,0 +1,6 @@
+ALTER TABLE timesheets DROP CONSTRAINT IF EXISTS timesheets_clock_times_check;
+ALTER TABLE timesheets
+    DROP COLUMN IF EXISTS end_time,
+    DROP COLUMN IF EXISTS start_time;
+
+DROP TABLE IF EXISTS timesheet_policies;
@@ -0,0 +1,20 @@
+-- Per-tenant timesheet validation policy. NULL limits are not enforced.
+CREATE TABLE IF NOT EXISTS timesheet_policies (
+    tenant_id BIGINT PRIMARY KEY,
+    max_hours_per_day NUMERIC(5,2) CHECK (max_hours_per_day > 0 AND max_hours_per_day <= 24),
+    max_hours_per_week NUMERIC(6,2) CHECK (max_hours_per_week > 0 AND max_hours_per_week <= 168),
+    max_future_days INT CHECK (max_future_days >= 0),
+    enforce_project_window BOOLEAN NOT NULL DEFAULT false,
+    block_closed_projects BOOLEAN NOT NULL DEFAULT false,
+    require_clock_times BOOLEAN NOT NULL DEFAULT false,
+    prevent_overlaps BOOLEAN NOT NULL DEFAULT true,
+    updated_by_email TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Optional clock times on an entry; both or neither, within one day.
+ALTER TABLE timesheets
+    ADD COLUMN IF NOT EXISTS start_time TIME,
+    ADD COLUMN IF NOT EXISTS end_time TIME;
+ALTER TABLE timesheets ADD CONSTRAINT timesheets_clock_times_check
+    CHECK ((start_time IS NULL) = (end_time IS NULL) AND (start_time IS NULL OR end_time > start_time));
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS billing_rates;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS client_name;
@@ -0,0 +1,31 @@
+-- Client a project is billed to; billing reports group by it.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS client_name TEXT NOT NULL DEFAULT '';
+
+-- Hourly billing rates. The most specific scope that matches an entry wins
+-- (task, user on project, project, user, tenant); within a scope the rate with
+-- the latest effective_from on or before the work date applies, so a rate
+-- change is recorded by adding a row rather than editing the old one.
+CREATE TABLE IF NOT EXISTS billing_rates (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id BIGINT NOT NULL,
+    scope TEXT NOT NULL CHECK (scope IN ('tenant', 'project', 'user', 'task')),
+    project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
+    task_id BIGINT REFERENCES tasks(id) ON DELETE CASCADE,
+    user_email TEXT,
+    hourly_rate NUMERIC(12,2) NOT NULL CHECK (hourly_rate >= 0),
+    currency TEXT NOT NULL CHECK (currency ~ '^[A-Z]{3}$'),
+    effective_from DATE NOT NULL,
+    effective_to DATE,
+    created_by_email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (effective_to IS NULL OR effective_to >= effective_from),
+    CHECK (
+        (scope = 'tenant' AND project_id IS NULL AND task_id IS NULL AND user_email IS NULL) OR
+        (scope = 'project' AND project_id IS NOT NULL AND task_id IS NULL AND user_email IS NULL) OR
+        (scope = 'user' AND task_id IS NULL AND user_email IS NOT NULL) OR
+        (scope = 'task' AND task_id IS NOT NULL AND project_id IS NULL AND user_email IS NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_billing_rates_tenant ON billing_rates (tenant_id, scope, effective_from);
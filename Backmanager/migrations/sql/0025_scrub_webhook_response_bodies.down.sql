@@ -0,0 +1,2 @@
+-- Scrubbed response bodies cannot be restored.
+SELECT 1;
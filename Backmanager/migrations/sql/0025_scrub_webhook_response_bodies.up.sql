@@ -0,0 +1,5 @@
+-- Deliveries used to keep up to 2 KB of the receiver's response; only the
+-- status line is kept now. Drop the stored bodies.
+UPDATE webhook_deliveries
+SET response_body = NULL
+WHERE response_body IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Scrubbed message bodies cannot be restored.
+SELECT 1;
@@ -0,0 +1,5 @@
+-- Sent and dead messages used to keep their rendered bodies, which for reset
+-- and invite mail include live tokens. Clear the ones already stored.
+UPDATE email_outbox
+SET body_text = '', body_html = ''
+WHERE status IN ('sent', 'dead') AND (body_text <> '' OR body_html <> '');
@@ -54,16 +54,16 @@ func (s *Service) SystemOrganizations(c *gin.Context) {
 			t.name,
 			COALESCE(t.logo_url, '') AS logo_url,
 			COALESCE(t.max_sessions, 5) AS max_sessions,
-			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '24 hours') AS active_sessions_24h,
+			(SELECT COUNT(*) FROM user_sessions us WHERE us.tenant_id = t.id AND us.revoked_at IS NULL AND us.expires_at > NOW() AND us.last_seen_at >= NOW() - INTERVAL '24 hours') AS active_sessions_24h,
 			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id) AS user_count,
-			(SELECT COUNT(*) FROM projects p WHERE p.tenant_id = t.slug) AS project_count,
-			(SELECT COUNT(*) FROM tasks tk WHERE tk.tenant_id = t.slug) AS task_count,
+			(SELECT COUNT(*) FROM projects p WHERE p.tenant_id = t.id) AS project_count,
+			(SELECT COUNT(*) FROM tasks tk WHERE tk.tenant_id = t.id) AS task_count,
 			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '7 days') AS active_users_7d,
 			(SELECT MAX(u.last_login_at) FROM users u WHERE u.tenant_id = t.id) AS last_login_at,
 			(
 				EXISTS (SELECT 1 FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '7 days')
-				OR EXISTS (SELECT 1 FROM projects p WHERE p.tenant_id = t.slug AND p.created_at >= NOW() - INTERVAL '7 days')
-				OR EXISTS (SELECT 1 FROM tasks tk WHERE tk.tenant_id = t.slug AND tk.created_at >= NOW() - INTERVAL '7 days')
+				OR EXISTS (SELECT 1 FROM projects p WHERE p.tenant_id = t.id AND p.created_at >= NOW() - INTERVAL '7 days')
+				OR EXISTS (SELECT 1 FROM tasks tk WHERE tk.tenant_id = t.id AND tk.created_at >= NOW() - INTERVAL '7 days')
 			) AS active_workspace_7d
 		FROM tenants t
 		ORDER BY active_workspace_7d DESC, active_users_7d DESC, t.slug
@@ -118,7 +118,7 @@ func (s *Service) SystemTenants(c *gin.Context) {
 			t.name,
 			COALESCE(t.logo_url, ''),
 			COALESCE(t.max_sessions, 5),
-			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '24 hours') AS active_sessions_24h,
+			(SELECT COUNT(*) FROM user_sessions us WHERE us.tenant_id = t.id AND us.revoked_at IS NULL AND us.expires_at > NOW() AND us.last_seen_at >= NOW() - INTERVAL '24 hours') AS active_sessions_24h,
 			t.created_at
 		FROM tenants t
 		ORDER BY created_at DESC
@@ -237,7 +237,7 @@ func (s *Service) CreateTenant(c *gin.Context) {
 		}
 		if _, err := tx.Exec(c.Request.Context(), `
 			INSERT INTO users (tenant_id, name, email, password_hash, role, public_id)
-			VALUES ($1, $2, $3, $4, 'org_admin', $5)
+			VALUES ($1, $2, $3, $4, 'owner', $5)
 		`, item.ID, req.Name+" Admin", req.OrgAdminEmail, string(hash), publicID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "org admin creation failed"})
 			return
@@ -310,44 +310,20 @@ func (s *Service) UpdateTenant(c *gin.Context) {
 		return
 	}
 
-	var oldSlug string
-	if err := s.DB.QueryRow(c.Request.Context(), `SELECT slug FROM tenants WHERE id = $1`, id).Scan(&oldSlug); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "tenant not found"})
-		return
-	}
-
-	tx, err := s.DB.Begin(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
-		return
-	}
-	defer tx.Rollback(c.Request.Context())
-
+	// Domain tables reference tenants by id, so a slug rename is a single-row change.
 	var item systemTenant
-	err = tx.QueryRow(c.Request.Context(), `
+	err = s.DB.QueryRow(c.Request.Context(), `
 		UPDATE tenants
 		SET slug = $1, name = $2, logo_url = $3, max_sessions = $4
 		WHERE id = $5
 		RETURNING id, slug, name, COALESCE(logo_url, ''), COALESCE(max_sessions, 5), created_at
 	`, req.Slug, req.Name, logoValue, req.MaxSessions, id).Scan(&item.ID, &item.Slug, &item.Name, &item.LogoURL, &item.MaxSessions, &item.CreatedAt)
-	if err != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "tenant update failed (slug may already exist)"})
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tenant not found"})
 		return
 	}
-
-	if oldSlug != item.Slug {
-		if _, err := tx.Exec(c.Request.Context(), `UPDATE projects SET tenant_id = $1 WHERE tenant_id = $2`, item.Slug, oldSlug); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update project tenant references"})
-			return
-		}
-		if _, err := tx.Exec(c.Request.Context(), `UPDATE tasks SET tenant_id = $1 WHERE tenant_id = $2`, item.Slug, oldSlug); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task tenant references"})
-			return
-		}
-	}
-
-	if err := tx.Commit(c.Request.Context()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "tenant update failed (slug may already exist)"})
 		return
 	}
 
@@ -369,8 +345,8 @@ func (s *Service) SystemAnalytics(c *gin.Context) {
 				FROM tenants t
 				WHERE
 					EXISTS (SELECT 1 FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '7 days')
-					OR EXISTS (SELECT 1 FROM projects p WHERE p.tenant_id = t.slug AND p.created_at >= NOW() - INTERVAL '7 days')
-					OR EXISTS (SELECT 1 FROM tasks tk WHERE tk.tenant_id = t.slug AND tk.created_at >= NOW() - INTERVAL '7 days')
+					OR EXISTS (SELECT 1 FROM projects p WHERE p.tenant_id = t.id AND p.created_at >= NOW() - INTERVAL '7 days')
+					OR EXISTS (SELECT 1 FROM tasks tk WHERE tk.tenant_id = t.id AND tk.created_at >= NOW() - INTERVAL '7 days')
 			) AS active_tenants_7d
 	`).Scan(&tenantCount, &userCount, &projectCount, &taskCount, &activeUsers24h, &activeUsers7d, &activeTenants7d)
 	if err != nil {
@@ -0,0 +1,181 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var errTimesheetAlreadyRequested = errors.New("timesheet entry is already included in another approval request")
+
+type approvalLineItem struct {
+	ID          int64   `json:"id"`
+	TimesheetID *int64  `json:"timesheet_id,omitempty"`
+	UserEmail   string  `json:"user_email"`
+	ProjectID   *int64  `json:"project_id,omitempty"`
+	TaskID      *int64  `json:"task_id,omitempty"`
+	WorkDate    string  `json:"work_date"`
+	Hours       float64 `json:"hours"`
+	Billable    bool    `json:"billable"`
+	Active      bool    `json:"active"`
+}
+
+const approvalLineItemColumns = `id, approval_request_id, timesheet_id, user_email, project_id, task_id, work_date, hours::float8, billable, active`
+
+func scanApprovalLineItem(row pgx.Row, requestID *int64, item *approvalLineItem) error {
+	var workDate time.Time
+	if err := row.Scan(&item.ID, requestID, &item.TimesheetID, &item.UserEmail, &item.ProjectID, &item.TaskID, &workDate, &item.Hours, &item.Billable, &item.Active); err != nil {
+		return err
+	}
+	item.WorkDate = workDate.Format("2006-01-02")
+	return nil
+}
+
+func loadApprovalEntries(ctx context.Context, q queryer, tenantID int64, requestIDs []int64) (map[int64][]approvalLineItem, error) {
+	out := make(map[int64][]approvalLineItem, len(requestIDs))
+	if len(requestIDs) == 0 {
+		return out, nil
+	}
+	rows, err := q.Query(ctx, `
+		SELECT `+approvalLineItemColumns+`
+		FROM approval_request_entries
+		WHERE tenant_id = $1 AND approval_request_id = ANY($2)
+		ORDER BY work_date, id
+	`, tenantID, requestIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var requestID int64
+		var item approvalLineItem
+		if err := scanApprovalLineItem(rows, &requestID, &item); err != nil {
+			return nil, err
+		}
+		out[requestID] = append(out[requestID], item)
+	}
+	return out, rows.Err()
+}
+
+// timesheetRequestConflict explains why entry id cannot join a new approval
+// request, or returns "" if it can. Entries locked by an earlier approval stay
+// out even when no active request holds them any more.
+func timesheetRequestConflict(id int64, status string, inActiveRequest bool) string {
+	switch {
+	case status == timesheetLocked:
+		return fmt.Sprintf("timesheet entry %d is already approved", id)
+	case inActiveRequest:
+		return fmt.Sprintf("timesheet entry %d is already included in another approval request", id)
+	}
+	return ""
+}
+
+// approvalDeletable reports whether a request in status may be deleted.
+// Approved requests stay as the record of the entries they locked.
+func approvalDeletable(status string) bool {
+	return status != "approved"
+}
+
+// attachApprovalEntries snapshots the given timesheet entries as line items of
+// an approval request, marks draft entries submitted and recomputes the
+// request's billable hours from them.
+func attachApprovalEntries(ctx context.Context, tx pgx.Tx, tenantID, approvalID int64, timesheetIDs []int64) ([]approvalLineItem, float64, error) {
+	rows, err := tx.Query(ctx, `
+		INSERT INTO approval_request_entries (tenant_id, approval_request_id, timesheet_id, user_email, project_id, task_id, work_date, hours, billable)
+		SELECT tenant_id, $2, id, lower(created_by_email), project_id, task_id, work_date, hours, billable
+		FROM timesheets
+		WHERE tenant_id = $1 AND id = ANY($3) AND status <> 'locked'
+		ORDER BY work_date, id
+		RETURNING `+approvalLineItemColumns+`
+	`, tenantID, approvalID, timesheetIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]approvalLineItem, 0, len(timesheetIDs))
+	var billableHours float64
+	for rows.Next() {
+		var requestID int64
+		var item approvalLineItem
+		if err := scanApprovalLineItem(rows, &requestID, &item); err != nil {
+			rows.Close()
+			return nil, 0, err
+		}
+		if item.Billable {
+			billableHours += item.Hours
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, 0, errTimesheetAlreadyRequested
+		}
+		return nil, 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE timesheets
+		SET status = 'submitted', updated_at = NOW()
+		WHERE tenant_id = $1 AND id = ANY($2) AND status = 'draft'
+	`, tenantID, timesheetIDs); err != nil {
+		return nil, 0, err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE approval_requests
+		SET billable_hours = $3, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+	`, approvalID, tenantID, billableHours); err != nil {
+		return nil, 0, err
+	}
+	return items, billableHours, nil
+}
+
+// settleApprovalEntries applies the outcome of an approval request to the
+// entries it covers and to the weekly submission raised with it, if any:
+// approval locks the entries, rejection releases them back to draft so they
+// can be requested again.
+func settleApprovalEntries(ctx context.Context, tx pgx.Tx, tenantID, approvalID int64, approved bool) error {
+	if approved {
+		if _, err := tx.Exec(ctx, `
+			UPDATE timesheets
+			SET status = 'locked', updated_at = NOW()
+			WHERE tenant_id = $1 AND id IN (
+				SELECT timesheet_id FROM approval_request_entries WHERE approval_request_id = $2 AND active
+			)
+		`, tenantID, approvalID); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, `
+			WITH released AS (
+				UPDATE approval_request_entries
+				SET active = false
+				WHERE tenant_id = $1 AND approval_request_id = $2 AND active
+				RETURNING timesheet_id
+			)
+			UPDATE timesheets
+			SET status = 'draft', updated_at = NOW()
+			WHERE tenant_id = $1 AND status = 'submitted' AND id IN (SELECT timesheet_id FROM released)
+		`, tenantID, approvalID); err != nil {
+			return err
+		}
+	}
+
+	status := "rejected"
+	if approved {
+		status = "approved"
+	}
+	_, err := tx.Exec(ctx, `
+		UPDATE timesheet_submissions
+		SET status = $3,
+		    approved_at = CASE WHEN $4 THEN NOW() ELSE approved_at END,
+		    updated_at = NOW()
+		WHERE tenant_id = $1 AND approval_request_id = $2 AND status = 'submitted'
+	`, tenantID, approvalID, status, approved)
+	return err
+}
@@ -0,0 +1,32 @@
+package routes
+
+import "testing"
+
+func TestApprovedEntriesCannotBeRequestedAgain(t *testing.T) {
+	// A pending request holds its entry.
+	if msg := timesheetRequestConflict(7, "submitted", true); msg == "" {
+		t.Fatal("entry in a pending request was requestable")
+	}
+
+	// Once approved the request cannot be deleted, and its locked entry stays
+	// out of new requests even if its line item were no longer active.
+	if approvalDeletable("approved") {
+		t.Fatal("approved request was deletable")
+	}
+	for _, active := range []bool{true, false} {
+		if msg := timesheetRequestConflict(7, timesheetLocked, active); msg != "timesheet entry 7 is already approved" {
+			t.Fatalf("locked entry (active=%v): got %q", active, msg)
+		}
+	}
+
+	// Pending and rejected requests may be deleted, which releases their
+	// entries back to draft for a new request.
+	for _, status := range []string{"pending", "rejected"} {
+		if !approvalDeletable(status) {
+			t.Fatalf("%s request was not deletable", status)
+		}
+	}
+	if msg := timesheetRequestConflict(7, timesheetDraft, false); msg != "" {
+		t.Fatalf("released entry was not requestable: %q", msg)
+	}
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,11 +11,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type approvalRequestItem struct {
 	ID                int64     `json:"id"`
-	TenantID          string    `json:"tenant_id"`
+	TenantID          int64     `json:"tenant_id"`
 	ProjectID         *int64    `json:"project_id,omitempty"`
 	ProjectName       string    `json:"project_name"`
 	BillableHours     float64   `json:"billable_hours"`
@@ -28,12 +30,27 @@ type approvalRequestItem struct {
 	Approvals         []string  `json:"approvals"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
+	// Entries are the timesheet line items the request covers; requests
+	// created before line items existed have none.
+	Entries []approvalLineItem `json:"entries"`
 }
 
+// approvalEvent is the payload of approval.* events. NotifyApprover carries
+// the requester's approval_email_notifications setting to the subscriber.
+type approvalEvent struct {
+	approvalRequestItem
+	NotifyApprover bool `json:"notify_approver,omitempty"`
+}
+
+// createApprovalRequest selects the covered entries either by id or as one
+// user's entries in a date range, optionally narrowed to ProjectID.
 type createApprovalRequest struct {
-	ProjectID *int64  `json:"project_id"`
-	Note      string  `json:"note"`
-	Hours     float64 `json:"hours"`
+	ProjectID    *int64  `json:"project_id"`
+	Note         string  `json:"note"`
+	TimesheetIDs []int64 `json:"timesheet_ids"`
+	UserEmail    string  `json:"user_email"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
 }
 
 type approvalActionRequest struct {
@@ -41,7 +58,7 @@ type approvalActionRequest struct {
 }
 
 func (s *Service) ListApprovalRequests(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	rows, err := s.DB.Query(c.Request.Context(), `
 		SELECT id, tenant_id, project_id, COALESCE(project_name, ''), billable_hours::float8, requested_by_email,
 		       COALESCE(note, ''), status, approval_mode, COALESCE(approver_emails, '[]'::jsonb), current_step, required_approvals, COALESCE(approvals, '[]'::jsonb), created_at, updated_at
@@ -84,13 +101,33 @@ func (s *Service) ListApprovalRequests(c *gin.Context) {
 		item.Approvals = parseStringArrayJSON(approvalsRaw)
 		items = append(items, item)
 	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+		return
+	}
+
+	requestIDs := make([]int64, len(items))
+	for i, item := range items {
+		requestIDs[i] = item.ID
+	}
+	entries, err := loadApprovalEntries(c.Request.Context(), s.DB, tenantID, requestIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	for i := range items {
+		items[i].Entries = entries[items[i].ID]
+		if items[i].Entries == nil {
+			items[i].Entries = []approvalLineItem{}
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
 
 func (s *Service) CreateApprovalRequest(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	requester := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || requester == "" {
+	if tenantID <= 0 || requester == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
 		return
 	}
@@ -117,10 +154,112 @@ func (s *Service) CreateApprovalRequest(c *gin.Context) {
 		projectName = "Unspecified Project"
 	}
 
+	subject := strings.ToLower(strings.TrimSpace(req.UserEmail))
+	if subject == "" {
+		subject = requester
+	}
+	var from, to time.Time
+	if len(req.TimesheetIDs) == 0 {
+		var errFrom, errTo error
+		from, errFrom = time.Parse("2006-01-02", strings.TrimSpace(req.From))
+		to, errTo = time.Parse("2006-01-02", strings.TrimSpace(req.To))
+		if errFrom != nil || errTo != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "timesheet_ids or a from/to date range (YYYY-MM-DD) is required"})
+			return
+		}
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+			return
+		}
+	}
+	if subject != requester && !hasPermission(c, PermTimesheetsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only request approval for your own timesheet entries"})
+		return
+	}
+
+	routing := s.resolveApprovalRouting(c.Request.Context(), tenantID, requester)
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var timesheetIDs []int64
+	if len(req.TimesheetIDs) > 0 {
+		var msg string
+		var status int
+		timesheetIDs, status, msg = s.lockRequestedTimesheets(c, tx, tenantID, req.ProjectID, req.TimesheetIDs)
+		if msg != "" {
+			c.JSON(status, gin.H{"error": msg})
+			return
+		}
+	} else {
+		rows, err := tx.Query(c.Request.Context(), `
+			SELECT t.id
+			FROM timesheets t
+			WHERE t.tenant_id = $1 AND lower(t.created_by_email) = $2 AND t.work_date BETWEEN $3 AND $4
+			  AND ($5::bigint IS NULL OR t.project_id = $5) AND t.status <> 'locked'
+			  AND NOT EXISTS (SELECT 1 FROM approval_request_entries e WHERE e.timesheet_id = t.id AND e.active)
+			ORDER BY t.work_date, t.id
+			FOR UPDATE
+		`, tenantID, subject, from, to, req.ProjectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+		timesheetIDs, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+		if len(timesheetIDs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "no timesheet entries left to request in this range"})
+			return
+		}
+	}
+
+	out, err := insertApprovalRequest(c.Request.Context(), tx, tenantID, requester, req.ProjectID, projectName, req.Note, routing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	out.Entries, out.BillableHours, err = attachApprovalEntries(c.Request.Context(), tx, tenantID, out.ID, timesheetIDs)
+	if errors.Is(err, errTimesheetAlreadyRequested) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "approval.requested", requester, approvalEvent{approvalRequestItem: out, NotifyApprover: routing.NotifyApprover}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, out)
+}
+
+// approvalRouting is the requester's configured pipeline and the approvers a
+// new request is routed through.
+type approvalRouting struct {
+	Pipeline       string
+	Approvers      []string
+	NotifyApprover bool
+}
+
+func (s *Service) resolveApprovalRouting(ctx context.Context, tenantID int64, requester string) approvalRouting {
 	var pipeline string
 	var approvalEmails bool
 	var approversRaw []byte
-	if err := s.DB.QueryRow(c.Request.Context(), `
+	if err := s.DB.QueryRow(ctx, `
 		SELECT COALESCE(approval_pipeline, 'simple'), COALESCE(approval_email_notifications, true), COALESCE(approval_approvers, '[]'::jsonb)
 		FROM user_settings
 		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
@@ -131,7 +270,7 @@ func (s *Service) CreateApprovalRequest(c *gin.Context) {
 	}
 	configuredApprovers := uniqueEmails(parseStringArrayJSON(approversRaw))
 	if len(configuredApprovers) == 0 {
-		configuredApprovers, _ = s.listTenantOrgAdminEmails(c.Request.Context(), tenantID)
+		configuredApprovers, _ = s.listTenantOrgAdminEmails(ctx, tenantID)
 	}
 	if len(configuredApprovers) == 0 {
 		configuredApprovers = []string{requester}
@@ -139,62 +278,90 @@ func (s *Service) CreateApprovalRequest(c *gin.Context) {
 	if strings.TrimSpace(pipeline) != "multi_approval" && len(configuredApprovers) > 1 {
 		configuredApprovers = configuredApprovers[:1]
 	}
-	requiredApprovals := len(configuredApprovers)
-	if requiredApprovals < 1 {
-		requiredApprovals = 1
-	}
+	return approvalRouting{Pipeline: pipeline, Approvers: configuredApprovers, NotifyApprover: approvalEmails}
+}
 
-	hours := req.Hours
-	if hours <= 0 && req.ProjectID != nil {
-		_ = s.DB.QueryRow(c.Request.Context(), `
-			SELECT COALESCE(SUM(hours)::float8, 0)
-			FROM timesheets
-			WHERE tenant_id = $1 AND project_id = $2 AND billable = true
-		`, tenantID, *req.ProjectID).Scan(&hours)
+// lockRequestedTimesheets locks explicitly requested entries and checks that
+// each may be included: it exists, belongs to the caller (unless they manage
+// timesheets), matches projectID when set, and is neither approved already nor
+// in an active request. A non-empty message is the error to return with
+// status.
+func (s *Service) lockRequestedTimesheets(c *gin.Context, tx pgx.Tx, tenantID int64, projectID *int64, ids []int64) ([]int64, int, string) {
+	requester := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	rows, err := tx.Query(c.Request.Context(), `
+		SELECT t.id, lower(t.created_by_email), t.project_id, t.status,
+		       EXISTS (SELECT 1 FROM approval_request_entries e WHERE e.timesheet_id = t.id AND e.active)
+		FROM timesheets t
+		WHERE t.tenant_id = $1 AND t.id = ANY($2)
+		ORDER BY t.work_date, t.id
+		FOR UPDATE OF t
+	`, tenantID, ids)
+	if err != nil {
+		return nil, http.StatusInternalServerError, "query failed"
+	}
+	defer rows.Close()
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	out := make([]int64, 0, len(wanted))
+	for rows.Next() {
+		var id int64
+		var author string
+		var entryProject *int64
+		var entryStatus string
+		var requested bool
+		if err := rows.Scan(&id, &author, &entryProject, &entryStatus, &requested); err != nil {
+			return nil, http.StatusInternalServerError, "query failed"
+		}
+		switch {
+		case author != requester && !hasPermission(c, PermTimesheetsManage):
+			return nil, http.StatusForbidden, fmt.Sprintf("timesheet entry %d belongs to another user", id)
+		case projectID != nil && (entryProject == nil || *entryProject != *projectID):
+			return nil, http.StatusBadRequest, fmt.Sprintf("timesheet entry %d does not belong to this project", id)
+		}
+		if msg := timesheetRequestConflict(id, entryStatus, requested); msg != "" {
+			return nil, http.StatusConflict, msg
+		}
+		out = append(out, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, http.StatusInternalServerError, "query failed"
 	}
-	if hours < 0 {
-		hours = 0
+	if len(out) != len(wanted) {
+		return nil, http.StatusBadRequest, "timesheet entry not found for this tenant"
 	}
+	return out, 0, ""
+}
 
+func insertApprovalRequest(ctx context.Context, tx pgx.Tx, tenantID int64, requester string, projectID *int64, projectName string, note string, routing approvalRouting) (approvalRequestItem, error) {
+	requiredApprovals := len(routing.Approvers)
+	if requiredApprovals < 1 {
+		requiredApprovals = 1
+	}
 	var out approvalRequestItem
 	var approverEmailsRaw []byte
 	var approvalsRaw []byte
-	approverEmailsJSON, _ := json.Marshal(configuredApprovers)
-	if err := s.DB.QueryRow(c.Request.Context(), `
+	approverEmailsJSON, _ := json.Marshal(routing.Approvers)
+	if err := tx.QueryRow(ctx, `
 		INSERT INTO approval_requests (
 			tenant_id, project_id, project_name, billable_hours, requested_by_email, note, status, approval_mode,
 			approver_emails, current_step, response_token, required_approvals, approvals, created_at, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7, $8::jsonb, 0, '', $9, '[]'::jsonb, NOW(), NOW())
+		VALUES ($1, $2, $3, 0, $4, $5, 'pending', $6, $7::jsonb, 0, '', $8, '[]'::jsonb, NOW(), NOW())
 		RETURNING id, tenant_id, project_id, project_name, billable_hours::float8, requested_by_email, note, status, approval_mode,
 		          approver_emails, current_step, required_approvals, approvals, created_at, updated_at
-	`, tenantID, req.ProjectID, strings.TrimSpace(projectName), hours, requester, strings.TrimSpace(req.Note), pipeline, string(approverEmailsJSON), requiredApprovals).
+	`, tenantID, projectID, strings.TrimSpace(projectName), requester, strings.TrimSpace(note), routing.Pipeline, string(approverEmailsJSON), requiredApprovals).
 		Scan(&out.ID, &out.TenantID, &out.ProjectID, &out.ProjectName, &out.BillableHours, &out.RequestedByEmail, &out.Note, &out.Status, &out.ApprovalMode, &approverEmailsRaw, &out.CurrentStep, &out.RequiredApprovals, &approvalsRaw, &out.CreatedAt, &out.UpdatedAt); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
-		return
+		return out, err
 	}
 	out.ApproverEmails = parseStringArrayJSON(approverEmailsRaw)
 	out.Approvals = parseStringArrayJSON(approvalsRaw)
-
-	if approvalEmails {
-		if err := s.sendApprovalStepEmail(c.Request.Context(), out); err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{
-				"error": "approval request created but failed to send approval email: " + err.Error(),
-				"id":    out.ID,
-			})
-			return
-		}
-	}
-	_ = s.createInAppNotification(c.Request.Context(), tenantID, []string{requester}, "approval", "Approval request submitted", "Approval is pending via email pipeline.", map[string]any{
-		"approval_id": out.ID,
-		"project":     out.ProjectName,
-	})
-
-	c.JSON(http.StatusCreated, out)
+	return out, nil
 }
 
 func (s *Service) ActionApprovalRequest(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	actor := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
 	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
 	if err != nil || id <= 0 {
@@ -212,29 +379,48 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 		return
 	}
 
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
 	var item approvalRequestItem
 	var approverEmailsRaw []byte
 	var approvalsRaw []byte
-	err = s.DB.QueryRow(c.Request.Context(), `
+	// The row lock makes concurrent actions on the same request run one after
+	// the other, so the second one sees the first one's status and step.
+	err = tx.QueryRow(c.Request.Context(), `
 		SELECT id, tenant_id, project_id, project_name, billable_hours::float8, requested_by_email, COALESCE(note, ''), status, approval_mode,
 		       COALESCE(approver_emails, '[]'::jsonb), current_step, required_approvals, COALESCE(approvals, '[]'::jsonb), created_at, updated_at
 		FROM approval_requests
 		WHERE id = $1 AND tenant_id = $2
-		LIMIT 1
+		FOR UPDATE
 	`, id, tenantID).Scan(
 		&item.ID, &item.TenantID, &item.ProjectID, &item.ProjectName, &item.BillableHours, &item.RequestedByEmail,
 		&item.Note, &item.Status, &item.ApprovalMode, &approverEmailsRaw, &item.CurrentStep, &item.RequiredApprovals, &approvalsRaw, &item.CreatedAt, &item.UpdatedAt,
 	)
-	if err != nil {
+	if errors.Is(err, pgx.ErrNoRows) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "approval request not found"})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
 	if item.Status != "pending" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "approval request already closed"})
+		c.JSON(http.StatusConflict, gin.H{"error": "approval request already closed"})
 		return
 	}
 	item.ApproverEmails = parseStringArrayJSON(approverEmailsRaw)
 	item.Approvals = parseStringArrayJSON(approvalsRaw)
+	entries, err := loadApprovalEntries(c.Request.Context(), tx, tenantID, []int64{id})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	item.Entries = entries[id]
 	if len(item.ApproverEmails) == 0 || item.CurrentStep < 0 || item.CurrentStep >= len(item.ApproverEmails) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "approval routing is not configured"})
 		return
@@ -245,8 +431,21 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 		return
 	}
 
+	commit := func(eventType string) bool {
+		if err := recordEvent(c.Request.Context(), tx, tenantID, eventType, actor, approvalEvent{approvalRequestItem: item}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to record approval"})
+			return false
+		}
+		if err := tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+			return false
+		}
+		s.wakeEvents()
+		return true
+	}
+
 	if action == "reject" {
-		if _, err := s.DB.Exec(c.Request.Context(), `
+		if _, err := tx.Exec(c.Request.Context(), `
 			UPDATE approval_requests
 			SET status = 'rejected', updated_at = NOW()
 			WHERE id = $1 AND tenant_id = $2
@@ -254,9 +453,14 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject request"})
 			return
 		}
-		_ = s.createInAppNotification(c.Request.Context(), tenantID, []string{item.RequestedByEmail}, "approval", "Approval rejected", "Your approval request was rejected.", map[string]any{"approval_id": id, "project": item.ProjectName})
-		_ = s.sendMail(c.Request.Context(), item.RequestedByEmail, fmt.Sprintf("Approval Rejected | %s", item.ProjectName), fmt.Sprintf("Your approval request for %s was rejected by %s.", item.ProjectName, actor))
+		if err := settleApprovalEntries(c.Request.Context(), tx, tenantID, id, false); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reject request"})
+			return
+		}
 		item.Status = "rejected"
+		if !commit("approval.rejected") {
+			return
+		}
 		c.JSON(http.StatusOK, item)
 		return
 	}
@@ -274,7 +478,7 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 	approvalsJSON, _ := json.Marshal(item.Approvals)
 	nextStep := item.CurrentStep + 1
 	if nextStep >= item.RequiredApprovals || nextStep >= len(item.ApproverEmails) {
-		if _, err := s.DB.Exec(c.Request.Context(), `
+		if _, err := tx.Exec(c.Request.Context(), `
 			UPDATE approval_requests
 			SET status = 'approved', approvals = $1::jsonb, current_step = $2, updated_at = NOW()
 			WHERE id = $3 AND tenant_id = $4
@@ -282,16 +486,21 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete approval"})
 			return
 		}
-		_ = s.createInAppNotification(c.Request.Context(), tenantID, []string{item.RequestedByEmail}, "approval", "Approval completed", "Your request has been approved.", map[string]any{"approval_id": id, "project": item.ProjectName})
-		_ = s.sendMail(c.Request.Context(), item.RequestedByEmail, fmt.Sprintf("Approval Completed | %s", item.ProjectName), fmt.Sprintf("Your approval request for %s has been approved.", item.ProjectName))
+		if err := settleApprovalEntries(c.Request.Context(), tx, tenantID, id, true); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete approval"})
+			return
+		}
 		item.Status = "approved"
 		item.CurrentStep = nextStep
 		item.Approvals = parseStringArrayJSON(approvalsJSON)
+		if !commit("approval.approved") {
+			return
+		}
 		c.JSON(http.StatusOK, item)
 		return
 	}
 
-	if _, err := s.DB.Exec(c.Request.Context(), `
+	if _, err := tx.Exec(c.Request.Context(), `
 		UPDATE approval_requests
 		SET approvals = $1::jsonb, current_step = $2, updated_at = NOW()
 		WHERE id = $3 AND tenant_id = $4
@@ -301,22 +510,19 @@ func (s *Service) ActionApprovalRequest(c *gin.Context) {
 	}
 	item.CurrentStep = nextStep
 	item.Approvals = parseStringArrayJSON(approvalsJSON)
-	if err := s.sendApprovalStepEmail(c.Request.Context(), item); err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "approved but failed to email next approver: " + err.Error()})
+	if !commit("approval.progressed") {
 		return
 	}
-	_ = s.createInAppNotification(c.Request.Context(), tenantID, []string{item.RequestedByEmail}, "approval", "Approval progressed", "One approver approved. Workflow moved to next approver.", map[string]any{"approval_id": id, "project": item.ProjectName, "step": nextStep + 1})
 	c.JSON(http.StatusOK, item)
 }
 
-func (s *Service) listTenantOrgAdminEmails(ctx context.Context, tenantSlug string) ([]string, error) {
+func (s *Service) listTenantOrgAdminEmails(ctx context.Context, tenantID int64) ([]string, error) {
 	rows, err := s.DB.Query(ctx, `
 		SELECT lower(u.email)
 		FROM users u
-		JOIN tenants t ON t.id = u.tenant_id
-		WHERE t.slug = $1 AND u.role = 'org_admin' AND COALESCE(u.blocked, false) = false
+		WHERE u.tenant_id = $1 AND u.role IN ('owner', 'org_admin') AND COALESCE(u.blocked, false) = false
 		ORDER BY u.created_at DESC
-	`, strings.TrimSpace(tenantSlug))
+	`, tenantID)
 	if err != nil {
 		return nil, err
 	}
@@ -339,22 +545,18 @@ func (s *Service) sendApprovalStepEmail(ctx context.Context, item approvalReques
 		return fmt.Errorf("no approver configured for current step")
 	}
 	approver := item.ApproverEmails[item.CurrentStep]
-	subject := fmt.Sprintf("Approval Needed | %s", item.ProjectName)
-	message := fmt.Sprintf(
-		"Approval request pending.\n\nTenant: %s\nProject: %s\nBillable Hours: %.2f\nRequested By: %s\nPipeline: %s\nStep: %d of %d\nNote: %s\n\nAction required in system:\nLogin to PulseForge and open the Approvals module to approve/reject.",
-		item.TenantID,
-		item.ProjectName,
-		item.BillableHours,
-		item.RequestedByEmail,
-		strings.ReplaceAll(item.ApprovalMode, "_", " "),
-		item.CurrentStep+1,
-		item.RequiredApprovals,
-		item.Note,
-	)
-	if err := s.sendMail(ctx, approver, subject, message); err != nil {
-		return err
-	}
-	return nil
+	var tenantName string
+	_ = s.DB.QueryRow(ctx, `SELECT name FROM tenants WHERE id = $1`, item.TenantID).Scan(&tenantName)
+	return s.enqueueTemplate(ctx, "approval", item.TenantID, approver, "approval_needed", map[string]any{
+		"TenantName":    tenantName,
+		"ProjectName":   item.ProjectName,
+		"BillableHours": fmt.Sprintf("%.2f", item.BillableHours),
+		"RequestedBy":   item.RequestedByEmail,
+		"Pipeline":      strings.ReplaceAll(item.ApprovalMode, "_", " "),
+		"Step":          item.CurrentStep + 1,
+		"Steps":         item.RequiredApprovals,
+		"Note":          item.Note,
+	})
 }
 
 func (s *Service) DeleteApprovalRequest(c *gin.Context) {
@@ -365,7 +567,41 @@ func (s *Service) DeleteApprovalRequest(c *gin.Context) {
 		return
 	}
 
-	commandTag, err := s.DB.Exec(c.Request.Context(), `
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	// An approved request is the record behind its locked entries; deleting it
+	// would let the same hours be requested and approved again.
+	var status string
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT status
+		FROM approval_requests
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, requestID, tenantID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "approval request not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if !approvalDeletable(status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "approved requests cannot be deleted"})
+		return
+	}
+
+	// Release the request's entries first so they do not stay submitted
+	// with nothing left to approve them.
+	if err := settleApprovalEntries(c.Request.Context(), tx, tenantID, requestID, false); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	commandTag, err := tx.Exec(c.Request.Context(), `
 		DELETE FROM approval_requests
 		WHERE id = $1 AND tenant_id = $2
 	`, requestID, tenantID)
@@ -377,6 +613,10 @@ func (s *Service) DeleteApprovalRequest(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "approval request not found"})
 		return
 	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
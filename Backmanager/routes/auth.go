@@ -3,7 +3,6 @@ package routes
 import (
 	"context"
 	"crypto/rand"
-	"database/sql"
 	"errors"
 	"fmt"
 	"math/big"
@@ -33,11 +32,6 @@ type loginRequest struct {
 	Password   string `json:"password" binding:"required"`
 }
 
-type forgotPasswordRequest struct {
-	TenantSlug string `json:"tenant_slug"`
-	Email      string `json:"email" binding:"required,email"`
-}
-
 func (s *Service) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -67,7 +61,7 @@ func (s *Service) Register(c *gin.Context) {
 			return
 		}
 	}
-	role := "org_admin"
+	role := RoleOwner
 
 	var existingTenantSlug string
 	if err := s.DB.QueryRow(c.Request.Context(), `
@@ -143,14 +137,21 @@ func (s *Service) Register(c *gin.Context) {
 		return
 	}
 
-	token, err := s.issueToken(createdPublicID, req.TenantSlug, req.Email, req.Name, req.TenantName, tenantLogo, role)
+	sessionID, refreshToken, err := s.startSession(c.Request.Context(), userID, tenantID, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "session creation failed"})
+		return
+	}
+	token, err := s.issueToken(createdPublicID, sessionID, tenantID, req.TenantSlug, req.Email, req.Name, req.TenantName, tenantLogo, role)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(s.JWTTTL.Seconds()),
 		"user": gin.H{
 			"id":          createdPublicID,
 			"name":        req.Name,
@@ -162,11 +163,11 @@ func (s *Service) Register(c *gin.Context) {
 		},
 	})
 
-	s.sendAsyncNotification(
-		req.Email,
-		"Welcome to PulseForge",
-		fmt.Sprintf("Hi %s, your %s workspace is ready. Role: %s.", req.Name, req.TenantName, role),
-	)
+	s.sendAsyncNotification(tenantID, req.Email, "welcome", map[string]any{
+		"Name":       req.Name,
+		"TenantName": req.TenantName,
+		"Role":       role,
+	})
 }
 
 func (s *Service) Login(c *gin.Context) {
@@ -178,8 +179,14 @@ func (s *Service) Login(c *gin.Context) {
 
 	req.TenantSlug = normalizeSlug(req.TenantSlug)
 	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	clientIP := strings.TrimSpace(c.ClientIP())
+
+	if !s.allowAttempt(c, loginIPRule, "login:ip:"+clientIP) || !s.allowAttempt(c, loginEmailRule, "login:email:"+req.Email) {
+		return
+	}
 
 	var userID int64
+	var tenantID int64
 	var hash string
 	var role string
 	var name string
@@ -189,24 +196,26 @@ func (s *Service) Login(c *gin.Context) {
 	var tenantLogo string
 	var maxSessions int64
 	var blocked bool
-	var lastLoginAt sql.NullTime
+	var lockedUntil *time.Time
 	var err error
 	if req.TenantSlug != "" {
 		err = s.DB.QueryRow(c.Request.Context(), `
-			SELECT u.id, u.public_id, u.password_hash, u.role, u.name, t.slug, t.name, t.logo_url, COALESCE(t.max_sessions, 5), COALESCE(u.blocked, false), u.last_login_at
+			SELECT u.id, t.id, u.public_id, u.password_hash, u.role, u.name, t.slug, t.name, t.logo_url, COALESCE(t.max_sessions, 5), COALESCE(u.blocked, false),
+			       CASE WHEN u.locked_until > NOW() THEN u.locked_until END
 			FROM users u
 			JOIN tenants t ON t.id = u.tenant_id
 			WHERE t.slug = $1 AND lower(u.email) = lower($2)
-		`, req.TenantSlug, req.Email).Scan(&userID, &userPublicID, &hash, &role, &name, &tenantSlug, &tenantName, &tenantLogo, &maxSessions, &blocked, &lastLoginAt)
+		`, req.TenantSlug, req.Email).Scan(&userID, &tenantID, &userPublicID, &hash, &role, &name, &tenantSlug, &tenantName, &tenantLogo, &maxSessions, &blocked, &lockedUntil)
 	} else {
 		err = s.DB.QueryRow(c.Request.Context(), `
-			SELECT u.id, u.public_id, u.password_hash, u.role, u.name, t.slug, t.name, t.logo_url, COALESCE(t.max_sessions, 5), COALESCE(u.blocked, false), u.last_login_at
+			SELECT u.id, t.id, u.public_id, u.password_hash, u.role, u.name, t.slug, t.name, t.logo_url, COALESCE(t.max_sessions, 5), COALESCE(u.blocked, false),
+			       CASE WHEN u.locked_until > NOW() THEN u.locked_until END
 			FROM users u
 			JOIN tenants t ON t.id = u.tenant_id
 			WHERE lower(u.email) = lower($1)
 			ORDER BY u.id DESC
 			LIMIT 1
-		`, req.Email).Scan(&userID, &userPublicID, &hash, &role, &name, &tenantSlug, &tenantName, &tenantLogo, &maxSessions, &blocked, &lastLoginAt)
+		`, req.Email).Scan(&userID, &tenantID, &userPublicID, &hash, &role, &name, &tenantSlug, &tenantName, &tenantLogo, &maxSessions, &blocked, &lockedUntil)
 	}
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
@@ -224,7 +233,24 @@ func (s *Service) Login(c *gin.Context) {
 		}
 	}
 
+	// Checked before bcrypt so a locked account costs no hashing work.
+	if lockedUntil != nil {
+		c.JSON(http.StatusLocked, gin.H{"error": "account temporarily locked after repeated failed sign-ins. Try again later or contact your organization admin.", "locked_until": lockedUntil})
+		return
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password)); err != nil {
+		failures, lockedNow, err := s.recordLoginFailure(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+			return
+		}
+		if lockedNow != nil {
+			s.notifyAccountLocked(tenantID, tenantSlug, req.Email, role, clientIP, *lockedNow)
+			c.JSON(http.StatusLocked, gin.H{"error": "account temporarily locked after repeated failed sign-ins. Try again later or contact your organization admin.", "locked_until": lockedNow})
+			return
+		}
+		sleepCtx(c.Request.Context(), loginFailureDelay(failures))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
 		return
 	}
@@ -233,153 +259,111 @@ func (s *Service) Login(c *gin.Context) {
 		return
 	}
 
-	if role != "system_admin" && maxSessions > 0 {
-		isAlreadyActive := lastLoginAt.Valid && lastLoginAt.Time.After(time.Now().Add(-24*time.Hour))
-		if !isAlreadyActive {
-			var activeSessions int64
-			err = s.DB.QueryRow(c.Request.Context(), `
-				SELECT COUNT(*)
-				FROM users u
-				JOIN tenants t ON t.id = u.tenant_id
-				WHERE t.slug = $1 AND u.last_login_at >= NOW() - INTERVAL '24 hours'
-			`, tenantSlug).Scan(&activeSessions)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate organization session limit"})
-				return
-			}
-			if activeSessions >= maxSessions {
-				c.JSON(http.StatusForbidden, gin.H{"error": "organization session limit reached. Contact system admin."})
-				return
-			}
-		}
+	subject := loginSubject{
+		UserID:      userID,
+		TenantID:    tenantID,
+		PublicID:    userPublicID,
+		Email:       req.Email,
+		Name:        name,
+		Role:        role,
+		TenantSlug:  tenantSlug,
+		TenantName:  tenantName,
+		TenantLogo:  tenantLogo,
+		MaxSessions: maxSessions,
 	}
 
-	userAgent := strings.TrimSpace(c.GetHeader("User-Agent"))
-	clientIP := strings.TrimSpace(c.ClientIP())
-	_, _ = s.DB.Exec(c.Request.Context(), `
-		UPDATE users
-		SET last_login_at = NOW(), last_login_user_agent = $2, last_login_ip = $3
-		WHERE id = $1
-	`, userID, userAgent, clientIP)
-
-	token, err := s.issueToken(userPublicID, tenantSlug, req.Email, name, tenantName, tenantLogo, role)
+	mfaEnabled, err := s.userMFAEnabled(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check two-factor status"})
 		return
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user": gin.H{
-			"id":          userPublicID,
-			"name":        name,
-			"email":       req.Email,
-			"tenant_slug": tenantSlug,
-			"tenant_name": tenantName,
-			"tenant_logo": tenantLogo,
-			"role":        role,
-		},
-	})
-}
-
-func (s *Service) ForgotPassword(c *gin.Context) {
-	var req forgotPasswordRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+	if mfaEnabled {
+		challenge, err := s.startMFAChallenge(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start two-factor challenge"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":    true,
+			"challenge_token": challenge,
+			"expires_in":      int64(mfaChallengeTTL.Seconds()),
+		})
 		return
 	}
 
-	req.TenantSlug = normalizeSlug(req.TenantSlug)
-	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	s.completeLogin(c, subject)
+}
 
-	tx, err := s.DB.Begin(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
-		return
-	}
-	defer tx.Rollback(c.Request.Context())
+type loginSubject struct {
+	UserID      int64
+	TenantID    int64
+	PublicID    string
+	Email       string
+	Name        string
+	Role        string
+	TenantSlug  string
+	TenantName  string
+	TenantLogo  string
+	MaxSessions int64
+}
 
-	var userID int64
-	var name string
-	var tenantName string
-	if req.TenantSlug != "" {
-		err = tx.QueryRow(c.Request.Context(), `
-			SELECT u.id, u.name, t.name
-			FROM users u
-			JOIN tenants t ON t.id = u.tenant_id
-			WHERE t.slug = $1 AND lower(u.email) = lower($2)
-		`, req.TenantSlug, req.Email).Scan(&userID, &name, &tenantName)
-	} else {
-		err = tx.QueryRow(c.Request.Context(), `
-			SELECT u.id, u.name, t.name
-			FROM users u
-			JOIN tenants t ON t.id = u.tenant_id
-			WHERE lower(u.email) = lower($1)
-			ORDER BY u.id DESC
-			LIMIT 1
-		`, req.Email).Scan(&userID, &name, &tenantName)
-	}
-	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"status": "if the account exists, a reset email has been sent"})
-		return
+// completeLogin enforces the tenant session limit, opens a device session and
+// writes the token response. It is the last step of both password and MFA login.
+func (s *Service) completeLogin(c *gin.Context, u loginSubject) {
+	if u.Role != RoleSystemAdmin && u.MaxSessions > 0 {
+		activeSessions, err := s.countActiveSessions(c.Request.Context(), u.TenantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate organization session limit"})
+			return
+		}
+		if activeSessions >= u.MaxSessions {
+			c.JSON(http.StatusForbidden, gin.H{"error": "organization session limit reached. Contact system admin."})
+			return
+		}
 	}
 
-	resetPassword, err := generatePassword(12)
+	userAgent := strings.TrimSpace(c.GetHeader("User-Agent"))
+	clientIP := strings.TrimSpace(c.ClientIP())
+	_, _ = s.DB.Exec(c.Request.Context(), `
+		UPDATE users
+		SET last_login_at = NOW(), last_login_user_agent = $2, last_login_ip = $3, failed_login_count = 0
+		WHERE id = $1
+	`, u.UserID, userAgent, clientIP)
+
+	sessionID, refreshToken, err := s.startSession(c.Request.Context(), u.UserID, u.TenantID, userAgent, clientIP)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password generation failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "session creation failed"})
 		return
 	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(resetPassword), bcrypt.DefaultCost)
+	token, err := s.issueToken(u.PublicID, sessionID, u.TenantID, u.TenantSlug, u.Email, u.Name, u.TenantName, u.TenantLogo, u.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hashing failed"})
-		return
-	}
-	if _, err := tx.Exec(c.Request.Context(), `UPDATE users SET password_hash = $1 WHERE id = $2`, string(hash), userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "password update failed"})
-		return
-	}
-
-	subject := "PulseForge password reset"
-	message := fmt.Sprintf(
-		"Hi %s,\n\nYour new password for %s is:\n%s\n\nThis password will stay active until you change it.",
-		name,
-		tenantName,
-		resetPassword,
-	)
-	if err := s.sendMail(context.Background(), req.Email, subject, message); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if err := tx.Commit(c.Request.Context()); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "if the account exists, a reset email has been sent"})
-}
-
-func generatePassword(length int) (string, error) {
-	if length < 8 {
-		length = 8
-	}
-	const alphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789@#$%*"
-	bytes := make([]byte, length)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	out := make([]byte, length)
-	for i, b := range bytes {
-		out[i] = alphabet[int(b)%len(alphabet)]
-	}
-	return string(out), nil
+	c.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(s.JWTTTL.Seconds()),
+		"user": gin.H{
+			"id":          u.PublicID,
+			"name":        u.Name,
+			"email":       u.Email,
+			"tenant_slug": u.TenantSlug,
+			"tenant_name": u.TenantName,
+			"tenant_logo": u.TenantLogo,
+			"role":        u.Role,
+		},
+	})
 }
 
-func (s *Service) issueToken(userID, tenantSlug, email, name, tenantName, tenantLogo, role string) (string, error) {
+func (s *Service) issueToken(userID, sessionID string, tenantID int64, tenantSlug, email, name, tenantName, tenantLogo, role string) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":         userID,
-		"tenant_id":   tenantSlug,
+		"sid":         sessionID,
+		"tenant_id":   tenantID,
+		"tenant_slug": tenantSlug,
 		"tenant_name": tenantName,
 		"tenant_logo": tenantLogo,
 		"email":       email,
@@ -0,0 +1,333 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	rateScopeTenant  = "tenant"
+	rateScopeProject = "project"
+	rateScopeUser    = "user"
+	rateScopeTask    = "task"
+)
+
+type billingRate struct {
+	ID             int64      `json:"id"`
+	Scope          string     `json:"scope"`
+	ProjectID      *int64     `json:"project_id,omitempty"`
+	TaskID         *int64     `json:"task_id,omitempty"`
+	UserEmail      *string    `json:"user_email,omitempty"`
+	HourlyRate     float64    `json:"hourly_rate"`
+	Currency       string     `json:"currency"`
+	EffectiveFrom  time.Time  `json:"-"`
+	EffectiveTo    *time.Time `json:"-"`
+	From           string     `json:"effective_from"`
+	To             string     `json:"effective_to,omitempty"`
+	CreatedByEmail string     `json:"created_by_email"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type billingRateRequest struct {
+	Scope         string  `json:"scope" binding:"required"`
+	ProjectID     *int64  `json:"project_id"`
+	TaskID        *int64  `json:"task_id"`
+	UserEmail     string  `json:"user_email"`
+	HourlyRate    float64 `json:"hourly_rate" binding:"gte=0,lte=1000000"`
+	Currency      string  `json:"currency" binding:"required"`
+	EffectiveFrom string  `json:"effective_from" binding:"required"`
+	EffectiveTo   string  `json:"effective_to"`
+}
+
+// billingRateInput is a validated billingRateRequest.
+type billingRateInput struct {
+	Scope         string
+	ProjectID     *int64
+	TaskID        *int64
+	UserEmail     *string
+	HourlyRate    float64
+	Currency      string
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+}
+
+const billingRateColumns = `id, scope, project_id, task_id, user_email, hourly_rate::float8, currency, effective_from, effective_to, created_by_email, created_at`
+
+func scanBillingRate(row pgx.Row, r *billingRate) error {
+	if err := row.Scan(&r.ID, &r.Scope, &r.ProjectID, &r.TaskID, &r.UserEmail, &r.HourlyRate, &r.Currency,
+		&r.EffectiveFrom, &r.EffectiveTo, &r.CreatedByEmail, &r.CreatedAt); err != nil {
+		return err
+	}
+	r.From = r.EffectiveFrom.Format("2006-01-02")
+	if r.EffectiveTo != nil {
+		r.To = r.EffectiveTo.Format("2006-01-02")
+	}
+	return nil
+}
+
+func loadBillingRates(ctx context.Context, q queryer, tenantID int64) ([]billingRate, error) {
+	rows, err := q.Query(ctx, `
+		SELECT `+billingRateColumns+`
+		FROM billing_rates
+		WHERE tenant_id = $1
+		ORDER BY scope, effective_from, id
+	`, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]billingRate, 0)
+	for rows.Next() {
+		var r billingRate
+		if err := scanBillingRate(rows, &r); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// validateBillingRate checks a create or update payload and writes the error
+// response itself when it returns false.
+func (s *Service) validateBillingRate(c *gin.Context, tenantID int64, req billingRateRequest) (billingRateInput, bool) {
+	in := billingRateInput{
+		Scope:      strings.ToLower(strings.TrimSpace(req.Scope)),
+		HourlyRate: req.HourlyRate,
+		Currency:   strings.ToUpper(strings.TrimSpace(req.Currency)),
+	}
+	if len(in.Currency) != 3 || strings.Trim(in.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "currency must be a 3-letter ISO code"})
+		return in, false
+	}
+	from, err := time.Parse("2006-01-02", strings.TrimSpace(req.EffectiveFrom))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be YYYY-MM-DD"})
+		return in, false
+	}
+	in.EffectiveFrom = from
+	if raw := strings.TrimSpace(req.EffectiveTo); raw != "" {
+		to, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_to must be YYYY-MM-DD"})
+			return in, false
+		}
+		if to.Before(from) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_to must not be before effective_from"})
+			return in, false
+		}
+		in.EffectiveTo = &to
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.UserEmail))
+	switch in.Scope {
+	case rateScopeTenant:
+		if req.ProjectID != nil || req.TaskID != nil || email != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a tenant rate cannot name a project, task or user"})
+			return in, false
+		}
+	case rateScopeProject:
+		if req.ProjectID == nil || req.TaskID != nil || email != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a project rate needs project_id only"})
+			return in, false
+		}
+	case rateScopeUser:
+		// project_id is optional and narrows the rate to that project.
+		if email == "" || req.TaskID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a user rate needs user_email and no task_id"})
+			return in, false
+		}
+		in.UserEmail = &email
+	case rateScopeTask:
+		if req.TaskID == nil || req.ProjectID != nil || email != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a task rate needs task_id only"})
+			return in, false
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scope must be tenant, project, user or task"})
+		return in, false
+	}
+
+	if req.TaskID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2)
+		`, *req.TaskID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "task not found for this tenant"})
+			return in, false
+		}
+		in.TaskID = req.TaskID
+	}
+	if req.ProjectID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)
+		`, *req.ProjectID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
+			return in, false
+		}
+		in.ProjectID = req.ProjectID
+	}
+	return in, true
+}
+
+func billingRateIDParam(c *gin.Context) (int64, bool) {
+	rateID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || rateID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rate id"})
+		return 0, false
+	}
+	return rateID, true
+}
+
+func (s *Service) ListBillingRates(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rates, err := loadBillingRates(c.Request.Context(), s.DB, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": rates})
+}
+
+func (s *Service) CreateBillingRate(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	var req billingRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	in, ok := s.validateBillingRate(c, tenantID, req)
+	if !ok {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var item billingRate
+	err = scanBillingRate(tx.QueryRow(c.Request.Context(), `
+		INSERT INTO billing_rates (tenant_id, scope, project_id, task_id, user_email, hourly_rate, currency, effective_from, effective_to, created_by_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+billingRateColumns+`
+	`, tenantID, in.Scope, in.ProjectID, in.TaskID, in.UserEmail, in.HourlyRate, in.Currency, in.EffectiveFrom, in.EffectiveTo,
+		strings.ToLower(strings.TrimSpace(emailFromContext(c)))), &item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "billing_rate.created", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (s *Service) UpdateBillingRate(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rateID, ok := billingRateIDParam(c)
+	if !ok {
+		return
+	}
+	var req billingRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	in, ok := s.validateBillingRate(c, tenantID, req)
+	if !ok {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var item billingRate
+	err = scanBillingRate(tx.QueryRow(c.Request.Context(), `
+		UPDATE billing_rates
+		SET scope = $3, project_id = $4, task_id = $5, user_email = $6, hourly_rate = $7, currency = $8,
+		    effective_from = $9, effective_to = $10, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING `+billingRateColumns+`
+	`, rateID, tenantID, in.Scope, in.ProjectID, in.TaskID, in.UserEmail, in.HourlyRate, in.Currency, in.EffectiveFrom, in.EffectiveTo), &item)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rate not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "billing_rate.updated", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (s *Service) DeleteBillingRate(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rateID, ok := billingRateIDParam(c)
+	if !ok {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var item billingRate
+	err = scanBillingRate(tx.QueryRow(c.Request.Context(), `
+		DELETE FROM billing_rates
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING `+billingRateColumns+`
+	`, rateID, tenantID), &item)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "rate not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "billing_rate.deleted", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
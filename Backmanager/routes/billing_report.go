@@ -0,0 +1,370 @@
+package routes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	roundingNone    = "none"
+	roundingUp      = "up"
+	roundingDown    = "down"
+	roundingNearest = "nearest"
+)
+
+var billingGroupDimensions = []string{"client", "project", "user"}
+
+// billingRounding rounds each entry's hours to an increment before pricing.
+type billingRounding struct {
+	Mode             string `json:"mode"`
+	IncrementMinutes int    `json:"increment_minutes"`
+}
+
+func (r billingRounding) apply(hours float64) float64 {
+	if r.Mode == roundingNone || r.IncrementMinutes <= 0 {
+		return roundHours(hours)
+	}
+	// The epsilon keeps 0.25h from becoming 15.000000001 minutes and rounding
+	// up a whole extra increment.
+	steps := hours * 60 / float64(r.IncrementMinutes)
+	switch r.Mode {
+	case roundingUp:
+		steps = math.Ceil(steps - 1e-9)
+	case roundingDown:
+		steps = math.Floor(steps + 1e-9)
+	default:
+		steps = math.Round(steps)
+	}
+	return roundHours(steps * float64(r.IncrementMinutes) / 60)
+}
+
+// billableEntry is an approved billable timesheet row to be priced.
+type billableEntry struct {
+	TimesheetID int64
+	UserEmail   string
+	ProjectID   *int64
+	ProjectName string
+	ClientName  string
+	TaskID      *int64
+	WorkDate    time.Time
+	Hours       float64
+}
+
+// rateRank returns how specifically r matches e, higher being more specific,
+// or 0 when it does not apply to e at all.
+func rateRank(r billingRate, e billableEntry) int {
+	if e.WorkDate.Before(r.EffectiveFrom) || (r.EffectiveTo != nil && e.WorkDate.After(*r.EffectiveTo)) {
+		return 0
+	}
+	sameProject := r.ProjectID != nil && e.ProjectID != nil && *r.ProjectID == *e.ProjectID
+	switch r.Scope {
+	case rateScopeTask:
+		if r.TaskID != nil && e.TaskID != nil && *r.TaskID == *e.TaskID {
+			return 5
+		}
+	case rateScopeUser:
+		if r.UserEmail == nil || !strings.EqualFold(*r.UserEmail, e.UserEmail) {
+			return 0
+		}
+		if r.ProjectID == nil {
+			return 2
+		}
+		if sameProject {
+			return 4
+		}
+	case rateScopeProject:
+		if sameProject {
+			return 3
+		}
+	case rateScopeTenant:
+		return 1
+	}
+	return 0
+}
+
+// resolveBillingRate picks the rate that prices e: the most specific matching
+// scope, then the latest effective_from, then the newest rate.
+func resolveBillingRate(rates []billingRate, e billableEntry) *billingRate {
+	var best *billingRate
+	bestRank := 0
+	for i := range rates {
+		rank := rateRank(rates[i], e)
+		if rank == 0 {
+			continue
+		}
+		r := &rates[i]
+		if best == nil || rank > bestRank ||
+			(rank == bestRank && (r.EffectiveFrom.After(best.EffectiveFrom) ||
+				(r.EffectiveFrom.Equal(best.EffectiveFrom) && r.ID > best.ID))) {
+			best, bestRank = r, rank
+		}
+	}
+	return best
+}
+
+type billingReportLine struct {
+	TimesheetID int64    `json:"timesheet_id"`
+	WorkDate    string   `json:"work_date"`
+	TaskID      *int64   `json:"task_id,omitempty"`
+	Hours       float64  `json:"hours"`
+	BilledHours float64  `json:"billed_hours"`
+	RateID      *int64   `json:"rate_id,omitempty"`
+	RateScope   string   `json:"rate_scope,omitempty"`
+	HourlyRate  *float64 `json:"hourly_rate,omitempty"`
+	Amount      float64  `json:"amount"`
+}
+
+type billingReportGroup struct {
+	ClientName    string              `json:"client_name,omitempty"`
+	ProjectID     *int64              `json:"project_id,omitempty"`
+	ProjectName   string              `json:"project_name,omitempty"`
+	UserEmail     string              `json:"user_email,omitempty"`
+	Currency      string              `json:"currency"`
+	Hours         float64             `json:"hours"`
+	BilledHours   float64             `json:"billed_hours"`
+	UnpricedHours float64             `json:"unpriced_hours"`
+	Amount        float64             `json:"amount"`
+	Lines         []billingReportLine `json:"lines,omitempty"`
+
+	cents int64
+}
+
+type billingTotal struct {
+	Currency    string  `json:"currency"`
+	BilledHours float64 `json:"billed_hours"`
+	Amount      float64 `json:"amount"`
+}
+
+type billingReport struct {
+	From          string               `json:"from"`
+	To            string               `json:"to"`
+	GroupBy       []string             `json:"group_by"`
+	Rounding      billingRounding      `json:"rounding"`
+	Groups        []billingReportGroup `json:"groups"`
+	Totals        []billingTotal       `json:"totals"`
+	UnpricedHours float64              `json:"unpriced_hours"`
+}
+
+// buildBillingReport prices entries and groups them by the groupBy dimensions
+// and currency. Entries without a matching rate are grouped under an empty
+// currency and reported as unpriced. Amounts are summed in cents so group and
+// total amounts always equal the sum of their lines.
+func buildBillingReport(entries []billableEntry, rates []billingRate, rounding billingRounding, groupBy []string, withLines bool) billingReport {
+	dims := make(map[string]bool, len(groupBy))
+	for _, d := range groupBy {
+		dims[d] = true
+	}
+	report := billingReport{GroupBy: groupBy, Rounding: rounding, Groups: make([]billingReportGroup, 0), Totals: make([]billingTotal, 0)}
+	index := make(map[string]int)
+	totalCents := make(map[string]int64)
+	totalHours := make(map[string]float64)
+
+	for _, e := range entries {
+		billed := rounding.apply(e.Hours)
+		line := billingReportLine{TimesheetID: e.TimesheetID, WorkDate: e.WorkDate.Format("2006-01-02"), TaskID: e.TaskID, Hours: e.Hours, BilledHours: billed}
+		currency := ""
+		var cents int64
+		if rate := resolveBillingRate(rates, e); rate != nil {
+			currency = rate.Currency
+			cents = int64(math.Round(billed * rate.HourlyRate * 100))
+			line.RateID, line.RateScope = &rate.ID, rate.Scope
+			line.HourlyRate = floatPtr(rate.HourlyRate)
+			line.Amount = float64(cents) / 100
+		}
+
+		var g billingReportGroup
+		if dims["client"] {
+			g.ClientName = e.ClientName
+		}
+		if dims["project"] {
+			g.ProjectID, g.ProjectName = e.ProjectID, e.ProjectName
+		}
+		if dims["user"] {
+			g.UserEmail = e.UserEmail
+		}
+		g.Currency = currency
+		projectKey := ""
+		if g.ProjectID != nil {
+			projectKey = strconv.FormatInt(*g.ProjectID, 10)
+		}
+		key := strings.Join([]string{g.ClientName, projectKey, g.UserEmail, g.Currency}, "\x00")
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Groups)
+			index[key] = i
+			report.Groups = append(report.Groups, g)
+		}
+		group := &report.Groups[i]
+		group.Hours += e.Hours
+		if currency == "" {
+			group.UnpricedHours += billed
+			report.UnpricedHours += billed
+		} else {
+			group.BilledHours += billed
+			group.cents += cents
+			totalCents[currency] += cents
+			totalHours[currency] += billed
+		}
+		if withLines {
+			group.Lines = append(group.Lines, line)
+		}
+	}
+
+	for i := range report.Groups {
+		g := &report.Groups[i]
+		g.Hours, g.BilledHours, g.UnpricedHours = roundHours(g.Hours), roundHours(g.BilledHours), roundHours(g.UnpricedHours)
+		g.Amount = float64(g.cents) / 100
+	}
+	sort.SliceStable(report.Groups, func(a, b int) bool {
+		ga, gb := report.Groups[a], report.Groups[b]
+		if ga.ClientName != gb.ClientName {
+			return ga.ClientName < gb.ClientName
+		}
+		if ga.ProjectName != gb.ProjectName {
+			return ga.ProjectName < gb.ProjectName
+		}
+		if ga.UserEmail != gb.UserEmail {
+			return ga.UserEmail < gb.UserEmail
+		}
+		return ga.Currency < gb.Currency
+	})
+	for currency, cents := range totalCents {
+		report.Totals = append(report.Totals, billingTotal{Currency: currency, BilledHours: roundHours(totalHours[currency]), Amount: float64(cents) / 100})
+	}
+	sort.Slice(report.Totals, func(a, b int) bool { return report.Totals[a].Currency < report.Totals[b].Currency })
+	report.UnpricedHours = roundHours(report.UnpricedHours)
+	return report
+}
+
+// BillingReport prices approved billable hours for a period. Query params:
+// from and to (YYYY-MM-DD, required), group_by (comma list of client, project
+// and user; default all three), rounding (none, up, down or nearest) with
+// increment in minutes (default 15), lines=true for per-entry detail and
+// format=csv for a spreadsheet-ready export of the groups.
+func (s *Service) BillingReport(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	from, errFrom := time.Parse("2006-01-02", strings.TrimSpace(c.Query("from")))
+	to, errTo := time.Parse("2006-01-02", strings.TrimSpace(c.Query("to")))
+	if errFrom != nil || errTo != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be YYYY-MM-DD"})
+		return
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must not exceed one year"})
+		return
+	}
+
+	groupBy := billingGroupDimensions
+	if raw := strings.TrimSpace(c.Query("group_by")); raw != "" {
+		groupBy = make([]string, 0, len(billingGroupDimensions))
+		seen := make(map[string]bool)
+		for _, part := range strings.Split(raw, ",") {
+			dim := strings.ToLower(strings.TrimSpace(part))
+			if dim != "client" && dim != "project" && dim != "user" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must list client, project or user"})
+				return
+			}
+			if !seen[dim] {
+				seen[dim] = true
+				groupBy = append(groupBy, dim)
+			}
+		}
+	}
+
+	rounding := billingRounding{Mode: strings.ToLower(strings.TrimSpace(c.DefaultQuery("rounding", roundingNone))), IncrementMinutes: 15}
+	switch rounding.Mode {
+	case roundingNone:
+		rounding.IncrementMinutes = 0
+	case roundingUp, roundingDown, roundingNearest:
+		if raw := strings.TrimSpace(c.Query("increment")); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > 60 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "increment must be between 1 and 60 minutes"})
+				return
+			}
+			rounding.IncrementMinutes = n
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "rounding must be none, up, down or nearest"})
+		return
+	}
+
+	// Only entries locked by an approved request are billed.
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT ts.id, lower(ts.created_by_email), ts.project_id, COALESCE(p.name, ''), COALESCE(p.client_name, ''),
+		       ts.task_id, ts.work_date, ts.hours::float8
+		FROM timesheets ts
+		LEFT JOIN projects p ON p.id = ts.project_id AND p.tenant_id = ts.tenant_id
+		WHERE ts.tenant_id = $1 AND ts.billable AND ts.status = 'locked'
+		  AND ts.work_date >= $2 AND ts.work_date <= $3
+		ORDER BY ts.work_date, ts.id
+	`, tenantID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+	entries := make([]billableEntry, 0)
+	for rows.Next() {
+		var e billableEntry
+		if err := rows.Scan(&e.TimesheetID, &e.UserEmail, &e.ProjectID, &e.ProjectName, &e.ClientName, &e.TaskID, &e.WorkDate, &e.Hours); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	rates, err := loadBillingRates(c.Request.Context(), s.DB, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(c.Query("format")))
+	report := buildBillingReport(entries, rates, rounding, groupBy, c.Query("lines") == "true" && format != "csv")
+	report.From, report.To = from.Format("2006-01-02"), to.Format("2006-01-02")
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="billing-%s-%s.csv"`, report.From, report.To))
+		c.Status(http.StatusOK)
+		w := csv.NewWriter(c.Writer)
+		_ = w.Write([]string{"client", "project", "user", "currency", "hours", "billed_hours", "unpriced_hours", "amount"})
+		for _, g := range report.Groups {
+			_ = w.Write([]string{
+				csvText(g.ClientName), csvText(g.ProjectName), csvText(g.UserEmail), g.Currency,
+				strconv.FormatFloat(g.Hours, 'f', 2, 64),
+				strconv.FormatFloat(g.BilledHours, 'f', 2, 64),
+				strconv.FormatFloat(g.UnpricedHours, 'f', 2, 64),
+				strconv.FormatFloat(g.Amount, 'f', 2, 64),
+			})
+		}
+		w.Flush()
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// csvText neutralises a user-entered cell that a spreadsheet would otherwise
+// run as a formula.
+func csvText(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
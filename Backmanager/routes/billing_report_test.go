@@ -0,0 +1,250 @@
+package routes
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+func timePtr(v time.Time) *time.Time { return &v }
+
+func testRate(id int64, scope string, from string, rate float64, currency string) billingRate {
+	return billingRate{ID: id, Scope: scope, EffectiveFrom: mustDate(from), HourlyRate: rate, Currency: currency}
+}
+
+func TestRateRank(t *testing.T) {
+	entry := billableEntry{UserEmail: "ada@example.com", ProjectID: int64Ptr(1), TaskID: int64Ptr(10), WorkDate: mustDate("2026-03-10")}
+
+	task := testRate(1, rateScopeTask, "2026-01-01", 0, "USD")
+	task.TaskID = int64Ptr(10)
+	otherTask := task
+	otherTask.TaskID = int64Ptr(11)
+	userOnProject := testRate(2, rateScopeUser, "2026-01-01", 0, "USD")
+	userOnProject.UserEmail, userOnProject.ProjectID = stringPtr("ADA@example.com"), int64Ptr(1)
+	userOnOtherProject := userOnProject
+	userOnOtherProject.ProjectID = int64Ptr(2)
+	user := testRate(3, rateScopeUser, "2026-01-01", 0, "USD")
+	user.UserEmail = stringPtr("ada@example.com")
+	otherUser := user
+	otherUser.UserEmail = stringPtr("bob@example.com")
+	project := testRate(4, rateScopeProject, "2026-01-01", 0, "USD")
+	project.ProjectID = int64Ptr(1)
+	tenant := testRate(5, rateScopeTenant, "2026-01-01", 0, "USD")
+	notYet := testRate(6, rateScopeTenant, "2026-03-11", 0, "USD")
+	ended := testRate(7, rateScopeTenant, "2026-01-01", 0, "USD")
+	ended.EffectiveTo = timePtr(mustDate("2026-03-09"))
+	endsToday := testRate(8, rateScopeTenant, "2026-01-01", 0, "USD")
+	endsToday.EffectiveTo = timePtr(mustDate("2026-03-10"))
+
+	cases := []struct {
+		name string
+		rate billingRate
+		want int
+	}{
+		{"task", task, 5},
+		{"other task", otherTask, 0},
+		{"user on project", userOnProject, 4},
+		{"user on other project", userOnOtherProject, 0},
+		{"project", project, 3},
+		{"user", user, 2},
+		{"other user", otherUser, 0},
+		{"tenant", tenant, 1},
+		{"starts after the entry", notYet, 0},
+		{"ended before the entry", ended, 0},
+		{"ends on the entry's day", endsToday, 1},
+	}
+	for _, tc := range cases {
+		if got := rateRank(tc.rate, entry); got != tc.want {
+			t.Errorf("%s: rateRank = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestResolveBillingRate(t *testing.T) {
+	entry := billableEntry{UserEmail: "ada@example.com", ProjectID: int64Ptr(1), TaskID: int64Ptr(10), WorkDate: mustDate("2026-03-10")}
+
+	tenant := testRate(1, rateScopeTenant, "2026-01-01", 50, "USD")
+	project := testRate(2, rateScopeProject, "2026-01-01", 80, "USD")
+	project.ProjectID = int64Ptr(1)
+	user := testRate(3, rateScopeUser, "2026-01-01", 90, "USD")
+	user.UserEmail = stringPtr("ada@example.com")
+	userOnProject := user
+	userOnProject.ID, userOnProject.ProjectID, userOnProject.HourlyRate = 4, int64Ptr(1), 100
+	task := testRate(5, rateScopeTask, "2026-01-01", 120, "USD")
+	task.TaskID = int64Ptr(10)
+
+	precedence := []struct {
+		name  string
+		rates []billingRate
+		want  int64
+	}{
+		{"task beats everything", []billingRate{tenant, project, user, userOnProject, task}, 5},
+		{"user on project beats project and user", []billingRate{tenant, project, user, userOnProject}, 4},
+		{"project beats user", []billingRate{tenant, user, project}, 2},
+		{"user beats tenant", []billingRate{tenant, user}, 3},
+		{"tenant as fallback", []billingRate{tenant}, 1},
+	}
+	for _, tc := range precedence {
+		got := resolveBillingRate(tc.rates, entry)
+		if got == nil || got.ID != tc.want {
+			t.Errorf("%s: got %+v, want rate %d", tc.name, got, tc.want)
+		}
+	}
+
+	// A project's rate changes on March 1; the old one was never closed, so
+	// both apply afterwards and the later effective_from wins.
+	oldRate := testRate(10, rateScopeProject, "2026-01-01", 80, "USD")
+	oldRate.ProjectID = int64Ptr(1)
+	newRate := testRate(11, rateScopeProject, "2026-03-01", 95, "USD")
+	newRate.ProjectID = int64Ptr(1)
+	sameDay := testRate(12, rateScopeProject, "2026-03-01", 97, "USD")
+	sameDay.ProjectID = int64Ptr(1)
+	overlapping := []struct {
+		name  string
+		date  string
+		rates []billingRate
+		want  int64
+	}{
+		{"before the change", "2026-02-15", []billingRate{oldRate, newRate}, 10},
+		{"on the change", "2026-03-01", []billingRate{newRate, oldRate}, 11},
+		{"after the change", "2026-04-01", []billingRate{oldRate, newRate}, 11},
+		{"same start, newest rate wins", "2026-04-01", []billingRate{sameDay, newRate, oldRate}, 12},
+	}
+	for _, tc := range overlapping {
+		e := entry
+		e.TaskID, e.WorkDate = nil, mustDate(tc.date)
+		got := resolveBillingRate(tc.rates, e)
+		if got == nil || got.ID != tc.want {
+			t.Errorf("%s: got %+v, want rate %d", tc.name, got, tc.want)
+		}
+	}
+
+	if got := resolveBillingRate([]billingRate{project}, billableEntry{UserEmail: "ada@example.com", WorkDate: mustDate("2026-03-10")}); got != nil {
+		t.Errorf("entry without a project matched %+v", got)
+	}
+}
+
+func TestBillingRoundingApply(t *testing.T) {
+	cases := []struct {
+		mode      string
+		increment int
+		hours     float64
+		want      float64
+	}{
+		{roundingNone, 0, 1.234, 1.23},
+		{roundingUp, 0, 1.234, 1.23},
+
+		{roundingUp, 15, 0.25, 0.25},
+		{roundingUp, 15, 0.26, 0.5},
+		{roundingUp, 15, 1, 1},
+		{roundingUp, 15, 0.01, 0.25},
+		{roundingDown, 15, 0.25, 0.25},
+		{roundingDown, 15, 0.49, 0.25},
+		{roundingDown, 15, 0.5, 0.5},
+		{roundingDown, 15, 0.1, 0},
+		{roundingNearest, 15, 0.25, 0.25},
+		{roundingNearest, 15, 0.3, 0.25},
+		{roundingNearest, 15, 0.375, 0.5},
+		{roundingNearest, 15, 0.45, 0.5},
+
+		// 0.1h is six minutes exactly even though 0.1 is not exact in binary.
+		{roundingUp, 6, 0.1, 0.1},
+		{roundingDown, 6, 0.3, 0.3},
+		{roundingUp, 60, 7.5, 8},
+		{roundingUp, 10, 0.7, 0.83},
+	}
+	for _, tc := range cases {
+		r := billingRounding{Mode: tc.mode, IncrementMinutes: tc.increment}
+		if got := r.apply(tc.hours); got != tc.want {
+			t.Errorf("%s/%d apply(%v) = %v, want %v", tc.mode, tc.increment, tc.hours, got, tc.want)
+		}
+	}
+}
+
+func TestBuildBillingReport(t *testing.T) {
+	usd := testRate(1, rateScopeProject, "2026-01-01", 33.33, "USD")
+	usd.ProjectID = int64Ptr(1)
+	eur := testRate(2, rateScopeProject, "2026-01-01", 80, "EUR")
+	eur.ProjectID = int64Ptr(2)
+	rates := []billingRate{usd, eur}
+
+	entries := []billableEntry{
+		{TimesheetID: 1, UserEmail: "ada@example.com", ProjectID: int64Ptr(1), ProjectName: "Site", ClientName: "Acme", WorkDate: mustDate("2026-03-02"), Hours: 0.1},
+		{TimesheetID: 2, UserEmail: "bob@example.com", ProjectID: int64Ptr(1), ProjectName: "Site", ClientName: "Acme", WorkDate: mustDate("2026-03-02"), Hours: 0.1},
+		{TimesheetID: 3, UserEmail: "ada@example.com", ProjectID: int64Ptr(1), ProjectName: "Site", ClientName: "Acme", WorkDate: mustDate("2026-03-03"), Hours: 0.1},
+		{TimesheetID: 4, UserEmail: "ada@example.com", ProjectID: int64Ptr(2), ProjectName: "App", ClientName: "Beta", WorkDate: mustDate("2026-03-03"), Hours: 1.1},
+		{TimesheetID: 5, UserEmail: "ada@example.com", ProjectID: int64Ptr(3), ProjectName: "Internal", ClientName: "Acme", WorkDate: mustDate("2026-03-04"), Hours: 2.2},
+	}
+
+	report := buildBillingReport(entries, rates, billingRounding{Mode: roundingUp, IncrementMinutes: 15}, []string{"client", "project"}, true)
+
+	// Each 0.1h entry rounds up to 0.25h and is priced on its own, so the
+	// group is the sum of its three 8.33 lines, not 0.75h x 33.33 = 25.00.
+	want := []struct {
+		client, project, currency string
+		billed, unpriced, amount  float64
+		lines                     int
+	}{
+		{"Acme", "Internal", "", 0, 2.25, 0, 1},
+		{"Acme", "Site", "USD", 0.75, 0, 24.99, 3},
+		{"Beta", "App", "EUR", 1.25, 0, 100, 1},
+	}
+	if len(report.Groups) != len(want) {
+		t.Fatalf("got %d groups: %+v", len(report.Groups), report.Groups)
+	}
+	for i, w := range want {
+		g := report.Groups[i]
+		if g.ClientName != w.client || g.ProjectName != w.project || g.Currency != w.currency ||
+			g.BilledHours != w.billed || g.UnpricedHours != w.unpriced || g.Amount != w.amount || len(g.Lines) != w.lines {
+			t.Errorf("group %d = %+v, want %+v", i, g, w)
+		}
+		if g.UserEmail != "" {
+			t.Errorf("group %d kept user %q although user is not a dimension", i, g.UserEmail)
+		}
+	}
+
+	if len(report.Totals) != 2 ||
+		report.Totals[0] != (billingTotal{Currency: "EUR", BilledHours: 1.25, Amount: 100}) ||
+		report.Totals[1] != (billingTotal{Currency: "USD", BilledHours: 0.75, Amount: 24.99}) {
+		t.Errorf("totals = %+v", report.Totals)
+	}
+	if report.UnpricedHours != 2.25 {
+		t.Errorf("unpriced hours = %v, want 2.25", report.UnpricedHours)
+	}
+
+	byUser := buildBillingReport(entries[:3], rates, billingRounding{Mode: roundingNone}, []string{"user"}, false)
+	if len(byUser.Groups) != 2 || byUser.Groups[0].UserEmail != "ada@example.com" || byUser.Groups[0].Amount != 6.66 ||
+		byUser.Groups[1].UserEmail != "bob@example.com" || byUser.Groups[1].Amount != 3.33 || byUser.Groups[0].Lines != nil {
+		t.Errorf("by user = %+v", byUser.Groups)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	cases := map[string]string{
+		"Acme":               "Acme",
+		"":                   "",
+		"=HYPERLINK(\"x\")":  "'=HYPERLINK(\"x\")",
+		"+1 555":             "'+1 555",
+		"-2+3":               "'-2+3",
+		"@SUM(A1)":           "'@SUM(A1)",
+		"\tcmd":              "'\tcmd",
+		"ada@example.com":    "ada@example.com",
+		"Acme = Best Client": "Acme = Best Client",
+	}
+	for in, want := range cases {
+		if got := csvText(in); got != want {
+			t.Errorf("csvText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
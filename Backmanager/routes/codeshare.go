@@ -11,7 +11,7 @@ import (
 
 type codeShare struct {
 	ID          int64      `json:"id"`
-	TenantID    string     `json:"tenant_id"`
+	TenantID    int64      `json:"tenant_id"`
 	AuthorEmail string     `json:"author_email"`
 	Title       string     `json:"title"`
 	Body        string     `json:"body"`
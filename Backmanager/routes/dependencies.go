@@ -0,0 +1,248 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	dependencyFinishToStart = "FS"
+	dependencyStartToStart  = "SS"
+)
+
+type taskDependency struct {
+	ID            int64     `json:"id"`
+	ProjectID     int64     `json:"project_id"`
+	PredecessorID int64     `json:"predecessor_id"`
+	SuccessorID   int64     `json:"successor_id"`
+	Type          string    `json:"type"`
+	LagDays       int       `json:"lag_days"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type createDependencyRequest struct {
+	PredecessorID int64  `json:"predecessor_id" binding:"required,gt=0"`
+	SuccessorID   int64  `json:"successor_id" binding:"required,gt=0"`
+	Type          string `json:"type"`
+	LagDays       int    `json:"lag_days" binding:"min=-3650,max=3650"`
+}
+
+func loadDependencies(ctx context.Context, q queryer, tenantID, projectID int64) ([]taskDependency, error) {
+	rows, err := q.Query(ctx, `
+		SELECT id, project_id, predecessor_id, successor_id, kind, lag_days, created_at
+		FROM task_dependencies
+		WHERE tenant_id = $1 AND project_id = $2
+		ORDER BY id
+	`, tenantID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make([]taskDependency, 0)
+	for rows.Next() {
+		var d taskDependency
+		if err := rows.Scan(&d.ID, &d.ProjectID, &d.PredecessorID, &d.SuccessorID, &d.Type, &d.LagDays, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// dependencyCreatesCycle reports whether adding pred -> succ to deps would
+// let succ reach pred again.
+func dependencyCreatesCycle(deps []taskDependency, pred, succ int64) bool {
+	next := make(map[int64][]int64, len(deps))
+	for _, d := range deps {
+		next[d.PredecessorID] = append(next[d.PredecessorID], d.SuccessorID)
+	}
+	seen := map[int64]bool{succ: true}
+	stack := []int64{succ}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == pred {
+			return true
+		}
+		for _, n := range next[id] {
+			if !seen[n] {
+				seen[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return false
+}
+
+func projectIDParam(c *gin.Context) (int64, bool) {
+	projectID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || projectID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+		return 0, false
+	}
+	return projectID, true
+}
+
+func (s *Service) ListDependencies(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	projectID, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	deps, err := loadDependencies(c.Request.Context(), s.DB, tenantID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": deps})
+}
+
+func (s *Service) CreateDependency(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	projectID, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	var req createDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.Type = strings.ToUpper(strings.TrimSpace(req.Type))
+	if req.Type == "" {
+		req.Type = dependencyFinishToStart
+	}
+	if req.Type != dependencyFinishToStart && req.Type != dependencyStartToStart {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be FS or SS"})
+		return
+	}
+	if req.PredecessorID == req.SuccessorID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a task cannot depend on itself"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	// Locking the project serialises dependency changes, so two concurrent
+	// inserts cannot each pass the cycle check and together form a cycle.
+	var locked int64
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT id
+		FROM projects
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, projectID, tenantID).Scan(&locked); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+	var matched int
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT COUNT(*)
+		FROM tasks
+		WHERE tenant_id = $1 AND project_id = $2 AND id IN ($3, $4)
+	`, tenantID, projectID, req.PredecessorID, req.SuccessorID).Scan(&matched); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if matched != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "both tasks must belong to this project"})
+		return
+	}
+	deps, err := loadDependencies(c.Request.Context(), tx, tenantID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	for _, d := range deps {
+		if d.PredecessorID == req.PredecessorID && d.SuccessorID == req.SuccessorID {
+			c.JSON(http.StatusConflict, gin.H{"error": "dependency already exists"})
+			return
+		}
+	}
+	if dependencyCreatesCycle(deps, req.PredecessorID, req.SuccessorID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "dependency would create a cycle"})
+		return
+	}
+
+	var item taskDependency
+	err = tx.QueryRow(c.Request.Context(), `
+		INSERT INTO task_dependencies (tenant_id, project_id, predecessor_id, successor_id, kind, lag_days)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, project_id, predecessor_id, successor_id, kind, lag_days, created_at
+	`, tenantID, projectID, req.PredecessorID, req.SuccessorID, req.Type, req.LagDays).
+		Scan(&item.ID, &item.ProjectID, &item.PredecessorID, &item.SuccessorID, &item.Type, &item.LagDays, &item.CreatedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "dependency.created", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (s *Service) DeleteDependency(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	projectID, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+	dependencyID, err := strconv.ParseInt(strings.TrimSpace(c.Param("dependency_id")), 10, 64)
+	if err != nil || dependencyID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dependency id"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var item taskDependency
+	err = tx.QueryRow(c.Request.Context(), `
+		DELETE FROM task_dependencies
+		WHERE id = $1 AND project_id = $2 AND tenant_id = $3
+		RETURNING id, project_id, predecessor_id, successor_id, kind, lag_days, created_at
+	`, dependencyID, projectID, tenantID).
+		Scan(&item.ID, &item.ProjectID, &item.PredecessorID, &item.SuccessorID, &item.Type, &item.LagDays, &item.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dependency not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "dependency.deleted", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
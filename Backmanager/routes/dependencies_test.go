@@ -0,0 +1,37 @@
+package routes
+
+import "testing"
+
+func TestDependencyCreatesCycle(t *testing.T) {
+	chain := []taskDependency{
+		dependency(1, 2, dependencyFinishToStart, 0),
+		dependency(2, 3, dependencyStartToStart, 0),
+	}
+	diamond := []taskDependency{
+		dependency(1, 2, dependencyFinishToStart, 0),
+		dependency(1, 3, dependencyFinishToStart, 0),
+		dependency(2, 4, dependencyFinishToStart, 0),
+		dependency(3, 4, dependencyFinishToStart, 0),
+	}
+	cases := []struct {
+		name       string
+		deps       []taskDependency
+		pred, succ int64
+		want       bool
+	}{
+		{"self dependency", nil, 1, 1, true},
+		{"direct reverse edge", chain, 2, 1, true},
+		{"closes through an existing path", chain, 3, 1, true},
+		{"shortcut along the path", chain, 1, 3, false},
+		{"unrelated task", chain, 5, 1, false},
+		{"diamond sink back to source", diamond, 4, 1, true},
+		{"diamond sink back to one branch", diamond, 4, 2, true},
+		{"across the diamond branches", diamond, 2, 3, false},
+		{"across the branches the other way", diamond, 3, 2, false},
+	}
+	for _, tc := range cases {
+		if got := dependencyCreatesCycle(tc.deps, tc.pred, tc.succ); got != tc.want {
+			t.Errorf("%s: dependencyCreatesCycle(%d -> %d) = %v, want %v", tc.name, tc.pred, tc.succ, got, tc.want)
+		}
+	}
+}
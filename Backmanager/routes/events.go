@@ -0,0 +1,266 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	eventBatchSize = 100
+	// eventMaxFailures is how many times a subscriber retries one event before
+	// logging it and moving on, so a poison event cannot stall the stream.
+	eventMaxFailures = 5
+)
+
+// domainEvent is a row of the events table as handed to subscribers.
+type domainEvent struct {
+	ID         int64           `json:"id"`
+	TenantID   int64           `json:"tenant_id"`
+	Type       string          `json:"type"`
+	ActorEmail string          `json:"actor_email"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+	txid       int64
+}
+
+// eventSubscriber consumes the event stream. Delivery is at-least-once: a
+// crash between handle and the cursor update replays the batch, so handlers
+// should tolerate seeing an event twice.
+type eventSubscriber struct {
+	name   string
+	handle func(ctx context.Context, ev domainEvent) error
+}
+
+type subscriberState struct {
+	Name        string    `json:"name"`
+	LastEventID int64     `json:"last_event_id"`
+	Lag         int64     `json:"lag"`
+	Failures    int       `json:"failures"`
+	LastError   string    `json:"last_error,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (s *Service) eventSubscribers() []eventSubscriber {
+	return []eventSubscriber{
+		{name: "notifications", handle: s.handleNotificationEvent},
+		{name: "webhooks", handle: s.handleWebhookEvent},
+		{name: "audit", handle: s.handleAuditEvent},
+	}
+}
+
+// recordEvent writes a domain event inside tx. Subscribers only see it once tx
+// commits, so a rolled-back change produces no side effects. Call wakeEvents
+// after the commit.
+func recordEvent(ctx context.Context, tx pgx.Tx, tenantID int64, eventType, actor string, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO events (tenant_id, type, actor_email, payload)
+		VALUES ($1, $2, lower($3), $4::jsonb)
+	`, tenantID, eventType, actor, string(raw))
+	return err
+}
+
+func (s *Service) wakeEvents() {
+	select {
+	case s.eventWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunEventDispatcher feeds committed events to every subscriber until ctx is
+// cancelled. Each subscriber advances its own cursor, so a slow or failing
+// webhook fan-out does not hold back notifications.
+func (s *Service) RunEventDispatcher(ctx context.Context, interval time.Duration) {
+	subs := s.eventSubscribers()
+	if err := s.registerSubscribers(ctx, subs); err != nil {
+		log.Printf("event dispatcher: %v", err)
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.eventWake:
+		}
+
+		more := false
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		for _, sub := range subs {
+			wg.Add(1)
+			go func(sub eventSubscriber) {
+				defer wg.Done()
+				n, err := s.dispatchEvents(ctx, sub)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("event subscriber %s: %v", sub.name, err)
+				}
+				if n == eventBatchSize {
+					mu.Lock()
+					more = true
+					mu.Unlock()
+				}
+			}(sub)
+		}
+		wg.Wait()
+
+		next := interval
+		if more {
+			next = 0
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// registerSubscribers adds cursor rows for new subscribers positioned at the
+// current head, so a subscriber added later does not replay history.
+func (s *Service) registerSubscribers(ctx context.Context, subs []eventSubscriber) error {
+	for _, sub := range subs {
+		if _, err := s.DB.Exec(ctx, `
+			INSERT INTO event_subscribers (name, last_txid, last_event_id)
+			VALUES ($1, txid_snapshot_xmin(txid_current_snapshot()) - 1, 9223372036854775807)
+			ON CONFLICT (name) DO NOTHING
+		`, sub.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatchEvents runs one batch for sub. Only events from transactions older
+// than the current snapshot's xmin are read, ordered by (txid, id); anything
+// still in flight will sort after the cursor once it commits.
+func (s *Service) dispatchEvents(ctx context.Context, sub eventSubscriber) (int, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(context.Background())
+
+	var lastTxid, lastID int64
+	var failures int
+	err = tx.QueryRow(ctx, `
+		SELECT last_txid, last_event_id, failures
+		FROM event_subscribers
+		WHERE name = $1
+		FOR UPDATE SKIP LOCKED
+	`, sub.name).Scan(&lastTxid, &lastID, &failures)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Another replica holds this subscriber.
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, txid, tenant_id, type, actor_email, payload, created_at
+		FROM events
+		WHERE (txid, id) > ($1, $2) AND txid < txid_snapshot_xmin(txid_current_snapshot())
+		ORDER BY txid, id
+		LIMIT $3
+	`, lastTxid, lastID, eventBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	batch := make([]domainEvent, 0, eventBatchSize)
+	for rows.Next() {
+		var ev domainEvent
+		if err := rows.Scan(&ev.ID, &ev.txid, &ev.TenantID, &ev.Type, &ev.ActorEmail, &ev.Payload, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var handleErr error
+	for _, ev := range batch {
+		if err := s.handleEvent(ctx, sub, ev); err != nil {
+			failures++
+			if failures < eventMaxFailures {
+				handleErr = fmt.Errorf("event %d (%s): %w", ev.ID, ev.Type, err)
+				break
+			}
+			log.Printf("event subscriber %s: skipping event %d (%s) after %d failures: %v", sub.name, ev.ID, ev.Type, failures, err)
+		}
+		failures = 0
+		lastTxid, lastID = ev.txid, ev.ID
+	}
+
+	lastError := ""
+	if handleErr != nil {
+		lastError = handleErr.Error()
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE event_subscribers
+		SET last_txid = $2, last_event_id = $3, failures = $4, last_error = NULLIF($5, ''), updated_at = NOW()
+		WHERE name = $1
+	`, sub.name, lastTxid, lastID, failures, lastError); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	if handleErr != nil {
+		return 0, handleErr
+	}
+	return len(batch), nil
+}
+
+// handleEvent converts a subscriber panic into an error.
+func (s *Service) handleEvent(ctx context.Context, sub eventSubscriber, ev domainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return sub.handle(ctx, ev)
+}
+
+func (s *Service) SystemEventSubscribers(c *gin.Context) {
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT es.name, es.last_event_id, es.failures, COALESCE(es.last_error, ''), es.updated_at,
+		       (SELECT COUNT(*) FROM events e WHERE (e.txid, e.id) > (es.last_txid, es.last_event_id))
+		FROM event_subscribers es
+		ORDER BY es.name
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]subscriberState, 0)
+	for rows.Next() {
+		var item subscriberState
+		if err := rows.Scan(&item.Name, &item.LastEventID, &item.Failures, &item.LastError, &item.UpdatedAt, &item.Lag); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
@@ -1,17 +1,19 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type forumPost struct {
 	ID          int64     `json:"id"`
-	TenantID    string    `json:"tenant_id"`
+	TenantID    int64     `json:"tenant_id"`
 	AuthorEmail string    `json:"author_email"`
 	Title       string    `json:"title"`
 	Body        string    `json:"body"`
@@ -83,6 +85,23 @@ func (s *Service) DeleteForumPost(c *gin.Context) {
 		return
 	}
 
+	var author string
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT lower(author_email)
+		FROM forum_posts
+		WHERE id = $1 AND tenant_id = $2
+	`, postID, tenantID).Scan(&author); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "forum post not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "forum post lookup failed"})
+		return
+	}
+	if author != strings.ToLower(strings.TrimSpace(emailFromContext(c))) && !hasPermission(c, PermForumModerate) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only delete your own posts"})
+		return
+	}
+
 	commandTag, err := s.DB.Exec(c.Request.Context(), `
 		DELETE FROM forum_posts
 		WHERE id = $1 AND tenant_id = $2
@@ -0,0 +1,463 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const inviteTTL = 7 * 24 * time.Hour
+
+type invitation struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"`
+	InvitedBy  string     `json:"invited_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentAt     *time.Time `json:"sent_at,omitempty"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+type createInvitationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+type acceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+var errSeatLimitReached = errors.New("seat limit reached")
+
+func (s *Service) ListInvitations(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT
+			i.public_id,
+			i.email,
+			i.role,
+			CASE
+				WHEN i.accepted_at IS NOT NULL THEN 'accepted'
+				WHEN i.revoked_at IS NOT NULL THEN 'revoked'
+				WHEN i.expires_at <= NOW() THEN 'expired'
+				ELSE 'pending'
+			END,
+			COALESCE(u.email, ''),
+			i.created_at,
+			i.expires_at,
+			i.sent_at,
+			i.accepted_at,
+			i.revoked_at
+		FROM invitations i
+		LEFT JOIN users u ON u.id = i.invited_by
+		WHERE i.tenant_id = $1
+		ORDER BY i.created_at DESC
+		LIMIT 200
+	`, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]invitation, 0)
+	for rows.Next() {
+		var item invitation
+		if err := rows.Scan(&item.ID, &item.Email, &item.Role, &item.Status, &item.InvitedBy, &item.CreatedAt, &item.ExpiresAt, &item.SentAt, &item.AcceptedAt, &item.RevokedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (s *Service) CreateInvitation(c *gin.Context) {
+	var req createInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if role == "" {
+		role = RoleMember
+	}
+	if !isValidTenantRole(role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner, org_admin, manager, member, viewer or guest"})
+		return
+	}
+	if !canAssignRole(roleFromContext(c), "", role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot invite a user with a role at or above your own"})
+		return
+	}
+
+	var existingUserID int64
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT id
+		FROM users
+		WHERE lower(email) = lower($1)
+		LIMIT 1
+	`, email).Scan(&existingUserID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+		return
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "email lookup failed"})
+		return
+	}
+
+	// Expired invites no longer hold a seat or block a fresh invite.
+	_, _ = s.DB.Exec(c.Request.Context(), `
+		UPDATE invitations
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND lower(email) = lower($2)
+		  AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at <= NOW()
+	`, tenantID, email)
+
+	token, err := randomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+	publicID, err := randomToken(12)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if !seatAvailable(c, tx, tenantID) {
+		return
+	}
+
+	var item invitation
+	err = tx.QueryRow(c.Request.Context(), `
+		INSERT INTO invitations (public_id, tenant_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, (SELECT id FROM users WHERE tenant_id = $2 AND lower(email) = lower($6) LIMIT 1), $7)
+		RETURNING public_id, email, role, created_at, expires_at
+	`, publicID, tenantID, email, role, hashToken(token), emailFromContext(c), time.Now().Add(inviteTTL)).Scan(
+		&item.ID, &item.Email, &item.Role, &item.CreatedAt, &item.ExpiresAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, gin.H{"error": "an invitation is already pending for this email"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	item.Status = "pending"
+	item.InvitedBy = strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+
+	item.SentAt = s.sendInvitationEmail(c.Request.Context(), tenantID, publicID, email, role, token, item.ExpiresAt)
+	c.JSON(http.StatusCreated, gin.H{"item": item, "email_sent": item.SentAt != nil})
+}
+
+func (s *Service) ResendInvitation(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	publicID := strings.TrimSpace(c.Param("id"))
+	token, err := randomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	// A live invite already holds its seat; resending an expired one takes a
+	// seat again.
+	var live bool
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT expires_at > NOW()
+		FROM invitations
+		WHERE tenant_id = $1 AND public_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+		FOR UPDATE
+	`, tenantID, publicID).Scan(&live)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pending invitation not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resend invitation"})
+		return
+	}
+	if !live && !seatAvailable(c, tx, tenantID) {
+		return
+	}
+
+	// Resending rotates the token so any earlier link stops working.
+	var item invitation
+	err = tx.QueryRow(c.Request.Context(), `
+		UPDATE invitations
+		SET token_hash = $3, expires_at = $4
+		WHERE tenant_id = $1 AND public_id = $2
+		RETURNING public_id, email, role, created_at, expires_at
+	`, tenantID, publicID, hashToken(token), time.Now().Add(inviteTTL)).Scan(
+		&item.ID, &item.Email, &item.Role, &item.CreatedAt, &item.ExpiresAt,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resend invitation"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	item.Status = "pending"
+
+	item.SentAt = s.sendInvitationEmail(c.Request.Context(), tenantID, publicID, item.Email, item.Role, token, item.ExpiresAt)
+	c.JSON(http.StatusOK, gin.H{"item": item, "email_sent": item.SentAt != nil})
+}
+
+func (s *Service) RevokeInvitation(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	publicID := strings.TrimSpace(c.Param("id"))
+	commandTag, err := s.DB.Exec(c.Request.Context(), `
+		UPDATE invitations
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND public_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`, tenantID, publicID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke invitation"})
+		return
+	}
+	if commandTag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "pending invitation not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
+
+func (s *Service) AcceptInvite(c *gin.Context) {
+	var req acceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	var inviteID int64
+	var tenantID int64
+	var email string
+	var role string
+	var tenantSlug string
+	var tenantName string
+	var tenantLogo string
+	err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT i.id, i.tenant_id, lower(i.email), i.role, t.slug, t.name, COALESCE(t.logo_url, '')
+		FROM invitations i
+		JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.token_hash = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+	`, hashToken(strings.TrimSpace(req.Token))).Scan(&inviteID, &tenantID, &email, &role, &tenantSlug, &tenantName, &tenantLogo)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invitation is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "invitation lookup failed"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hashing failed"})
+		return
+	}
+	userPublicID, err := s.generateUserPublicID(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user id generation failed"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	// Claim the invite first so two concurrent accepts cannot both succeed.
+	commandTag, err := tx.Exec(c.Request.Context(), `
+		UPDATE invitations
+		SET accepted_at = NOW()
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+	`, inviteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept invitation"})
+		return
+	}
+	if commandTag.RowsAffected() == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invitation is invalid or has expired"})
+		return
+	}
+	// The claimed invite no longer counts, so this checks there is room for
+	// the user it turns into; members may have been added since it was sent.
+	if !seatAvailable(c, tx, tenantID) {
+		return
+	}
+
+	var existingUserID int64
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT id
+		FROM users
+		WHERE lower(email) = lower($1)
+		LIMIT 1
+	`, email).Scan(&existingUserID); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "email already exists"})
+		return
+	} else if !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "email lookup failed"})
+		return
+	}
+
+	var userID int64
+	err = tx.QueryRow(c.Request.Context(), `
+		INSERT INTO users (tenant_id, name, email, password_hash, role, public_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, tenantID, req.Name, email, string(hash), role, userPublicID).Scan(&userID)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "user already exists for this tenant"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `UPDATE invitations SET accepted_user_id = $1 WHERE id = $2`, userID, inviteID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to accept invitation"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+
+	sessionID, refreshToken, err := s.startSession(c.Request.Context(), userID, tenantID, c.GetHeader("User-Agent"), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "session creation failed"})
+		return
+	}
+	token, err := s.issueToken(userPublicID, sessionID, tenantID, tenantSlug, email, req.Name, tenantName, tenantLogo, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(s.JWTTTL.Seconds()),
+		"user": gin.H{
+			"id":          userPublicID,
+			"name":        req.Name,
+			"email":       email,
+			"tenant_slug": tenantSlug,
+			"tenant_name": tenantName,
+			"tenant_logo": tenantLogo,
+			"role":        role,
+		},
+	})
+}
+
+// checkSeatAvailable treats the tenant's max_sessions as its seat count: active
+// (unblocked) members plus outstanding invites may not exceed it. It locks the
+// tenant row until tx ends so concurrent invites and accepts are counted one
+// at a time.
+func checkSeatAvailable(ctx context.Context, tx pgx.Tx, tenantID int64) error {
+	var maxSeats int64
+	if err := tx.QueryRow(ctx, `
+		SELECT COALESCE(max_sessions, 5)
+		FROM tenants
+		WHERE id = $1
+		FOR UPDATE
+	`, tenantID).Scan(&maxSeats); err != nil {
+		return err
+	}
+	var used int64
+	if err := tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = $1 AND COALESCE(u.blocked, false) = false AND u.role <> 'system_admin')
+			+ (SELECT COUNT(*) FROM invitations i WHERE i.tenant_id = $1 AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW())
+	`, tenantID).Scan(&used); err != nil {
+		return err
+	}
+	if maxSeats > 0 && used >= maxSeats {
+		return errSeatLimitReached
+	}
+	return nil
+}
+
+// seatAvailable runs checkSeatAvailable and writes the error response itself
+// when it returns false.
+func seatAvailable(c *gin.Context, tx pgx.Tx, tenantID int64) bool {
+	err := checkSeatAvailable(c.Request.Context(), tx, tenantID)
+	if errors.Is(err, errSeatLimitReached) {
+		c.JSON(http.StatusConflict, gin.H{"error": "organization seat limit reached. Contact system admin."})
+		return false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to validate organization seat limit"})
+		return false
+	}
+	return true
+}
+
+// sendInvitationEmail mails the invite link and records sent_at. It returns nil
+// when delivery failed so callers can report it; the invite stays valid and can
+// be resent.
+func (s *Service) sendInvitationEmail(ctx context.Context, tenantID int64, publicID, email, role, token string, expiresAt time.Time) *time.Time {
+	var tenantName string
+	_ = s.DB.QueryRow(ctx, `SELECT name FROM tenants WHERE id = $1`, tenantID).Scan(&tenantName)
+
+	if err := s.sendTemplate(ctx, tenantID, email, "invitation", map[string]any{
+		"TenantName": tenantName,
+		"Role":       role,
+		"AcceptURL":  appURL("/auth?invite=" + url.QueryEscape(token)),
+		"ExpiresAt":  expiresAt.UTC().Format("2006-01-02 15:04 MST"),
+	}); err != nil {
+		return nil
+	}
+	sentAt := time.Now()
+	_, _ = s.DB.Exec(ctx, `UPDATE invitations SET sent_at = $1 WHERE public_id = $2`, sentAt, publicID)
+	return &sentAt
+}
+
+func appURL(path string) string {
+	base := strings.TrimRight(strings.TrimSpace(os.Getenv("APP_BASE_URL")), "/")
+	if base == "" {
+		base = "http://localhost:3000"
+	}
+	return base + path
+}
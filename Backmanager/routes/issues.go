@@ -10,7 +10,7 @@ import (
 
 type issueItem struct {
 	ID             int64     `json:"id"`
-	TenantID       string    `json:"tenant_id"`
+	TenantID       int64     `json:"tenant_id"`
 	ProjectID      *int64    `json:"project_id,omitempty"`
 	ProjectName    string    `json:"project_name,omitempty"`
 	Title          string    `json:"title"`
@@ -70,8 +70,15 @@ func (s *Service) CreateIssue(c *gin.Context) {
 		req.Severity = "medium"
 	}
 
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
 	var item issueItem
-	err := s.DB.QueryRow(c.Request.Context(), `
+	err = tx.QueryRow(c.Request.Context(), `
 		INSERT INTO issues (tenant_id, project_id, title, description, severity, status, created_by_email)
 		VALUES ($1, $2, $3, $4, $5, 'open', $6)
 		RETURNING id, tenant_id, project_id, title, description, severity, status, created_by_email, created_at
@@ -81,5 +88,15 @@ func (s *Service) CreateIssue(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
 		return
 	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "issue.created", creator, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
 	c.JSON(http.StatusCreated, item)
 }
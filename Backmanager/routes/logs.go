@@ -19,6 +19,7 @@ type systemLogItem struct {
 	Path       string    `json:"path"`
 	StatusCode int       `json:"status_code"`
 	LatencyMS  int64     `json:"latency_ms"`
+	Event      string    `json:"event,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
@@ -32,7 +33,7 @@ func (s *Service) AuditLogMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tenant := tenantFromContext(c)
+		tenant := tenantSlugFromContext(c)
 		email := emailFromContext(c)
 		role := roleFromContext(c)
 		if tenant == "" && email == "" {
@@ -55,7 +56,7 @@ func (s *Service) SystemLogs(c *gin.Context) {
 	}
 
 	rows, err := s.DB.Query(c.Request.Context(), `
-		SELECT id, COALESCE(tenant_slug, ''), COALESCE(user_email, ''), COALESCE(role, ''), method, path, status_code, latency_ms, created_at
+		SELECT id, COALESCE(tenant_slug, ''), COALESCE(user_email, ''), COALESCE(role, ''), method, path, status_code, latency_ms, COALESCE(event, ''), created_at
 		FROM system_logs
 		ORDER BY created_at DESC
 		LIMIT $1
@@ -69,7 +70,7 @@ func (s *Service) SystemLogs(c *gin.Context) {
 	items := make([]systemLogItem, 0, limit)
 	for rows.Next() {
 		var item systemLogItem
-		if err := rows.Scan(&item.ID, &item.TenantSlug, &item.UserEmail, &item.Role, &item.Method, &item.Path, &item.StatusCode, &item.LatencyMS, &item.CreatedAt); err != nil {
+		if err := rows.Scan(&item.ID, &item.TenantSlug, &item.UserEmail, &item.Role, &item.Method, &item.Path, &item.StatusCode, &item.LatencyMS, &item.Event, &item.CreatedAt); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 			return
 		}
@@ -0,0 +1,392 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"backmanager/mailer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type emailTemplateItem struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Overrides   []string `json:"overrides"`
+}
+
+type emailTemplateDetail struct {
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	Locale        string                 `json:"locale"`
+	DefaultLocale string                 `json:"default_locale"`
+	Default       mailer.TemplateSource  `json:"default"`
+	Override      *mailer.TemplateSource `json:"override"`
+	Sample        map[string]any         `json:"sample"`
+}
+
+type saveEmailTemplateRequest struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject" binding:"required"`
+	Text    string `json:"text" binding:"required"`
+	HTML    string `json:"html"`
+}
+
+type previewEmailTemplateRequest struct {
+	Locale  string         `json:"locale"`
+	Subject string         `json:"subject"`
+	Text    string         `json:"text"`
+	HTML    string         `json:"html"`
+	Data    map[string]any `json:"data"`
+}
+
+type emailBrandingRequest struct {
+	AccentColor string `json:"accent_color"`
+}
+
+// composeMail renders a named template for one recipient using the tenant's
+// branding and overrides and the recipient's locale. tenantID 0 renders with
+// platform branding and no overrides.
+func (s *Service) composeMail(ctx context.Context, tenantID int64, to, name string, data map[string]any) (mailer.Message, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return mailer.Message{}, errors.New("missing recipient email")
+	}
+	overrides, err := s.loadTemplateOverrides(ctx, tenantID, name)
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	msg, _, err := mailer.Render(mailer.RenderRequest{
+		Name:      name,
+		Locale:    s.recipientLocale(ctx, tenantID, to),
+		Brand:     s.tenantBrand(ctx, tenantID),
+		Data:      data,
+		Overrides: overrides,
+	})
+	if err != nil {
+		return mailer.Message{}, err
+	}
+	msg.To = to
+	return msg, nil
+}
+
+// enqueueTemplate renders a template and queues it in the outbox. It returns
+// errNotificationOptedOut without queueing when the recipient turned email off
+// for the template's notification type.
+func (s *Service) enqueueTemplate(ctx context.Context, category string, tenantID int64, to, name string, data map[string]any) error {
+	if !s.wantsEmail(ctx, tenantID, to, name) {
+		return errNotificationOptedOut
+	}
+	msg, err := s.composeMail(ctx, tenantID, to, name, data)
+	if err != nil {
+		return err
+	}
+	return s.enqueueMail(ctx, category, msg)
+}
+
+// sendTemplate renders a template and delivers it synchronously. Use it only
+// when the caller must report delivery; everything else goes through the
+// outbox.
+func (s *Service) sendTemplate(ctx context.Context, tenantID int64, to, name string, data map[string]any) error {
+	msg, err := s.composeMail(ctx, tenantID, to, name, data)
+	if err != nil {
+		return err
+	}
+	if s.Mailer == nil {
+		return errors.New("mail transport not configured")
+	}
+	return s.Mailer.Send(ctx, msg)
+}
+
+func (s *Service) tenantBrand(ctx context.Context, tenantID int64) mailer.Brand {
+	brand := mailer.DefaultBrand()
+	if tenantID <= 0 {
+		return brand
+	}
+	var name, logo, accent string
+	if err := s.DB.QueryRow(ctx, `
+		SELECT name, COALESCE(logo_url, ''), COALESCE(brand_color, '')
+		FROM tenants
+		WHERE id = $1
+	`, tenantID).Scan(&name, &logo, &accent); err != nil {
+		return brand
+	}
+	if strings.TrimSpace(name) != "" {
+		brand.Name = name
+	}
+	brand.LogoURL = logo
+	if mailer.ValidAccent(accent) {
+		brand.Accent = accent
+	}
+	return brand
+}
+
+// recipientLocale reads the locale from the recipient's user settings. Without
+// a tenant (platform broadcasts) the most recently updated settings row wins.
+func (s *Service) recipientLocale(ctx context.Context, tenantID int64, email string) string {
+	var locale string
+	err := s.DB.QueryRow(ctx, `
+		SELECT locale
+		FROM user_settings
+		WHERE ($1 = 0 OR tenant_id = $1) AND lower(user_email) = lower($2)
+		ORDER BY updated_at DESC NULLS LAST
+		LIMIT 1
+	`, tenantID, email).Scan(&locale)
+	if err != nil || strings.TrimSpace(locale) == "" {
+		return mailer.DefaultLocale
+	}
+	return locale
+}
+
+func (s *Service) loadTemplateOverrides(ctx context.Context, tenantID int64, name string) (map[string]mailer.TemplateSource, error) {
+	out := map[string]mailer.TemplateSource{}
+	if tenantID <= 0 {
+		return out, nil
+	}
+	rows, err := s.DB.Query(ctx, `
+		SELECT locale, subject, body_text, body_html
+		FROM email_template_overrides
+		WHERE tenant_id = $1 AND name = $2
+	`, tenantID, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var locale string
+		var src mailer.TemplateSource
+		if err := rows.Scan(&locale, &src.Subject, &src.Text, &src.HTML); err != nil {
+			return nil, err
+		}
+		out[locale] = src
+	}
+	return out, rows.Err()
+}
+
+func templateLocaleParam(raw string) (string, bool) {
+	locale := mailer.NormalizeLocale(raw)
+	if locale == "" {
+		return mailer.DefaultLocale, true
+	}
+	return locale, mailer.SupportedLocale(locale)
+}
+
+func (s *Service) ListEmailTemplates(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT name, locale
+		FROM email_template_overrides
+		WHERE tenant_id = $1
+		ORDER BY name, locale
+	`, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+	overridden := map[string][]string{}
+	for rows.Next() {
+		var name, locale string
+		if err := rows.Scan(&name, &locale); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		overridden[name] = append(overridden[name], locale)
+	}
+
+	catalog := mailer.Catalog()
+	items := make([]emailTemplateItem, 0, len(catalog))
+	for _, info := range catalog {
+		locales := overridden[info.Name]
+		if locales == nil {
+			locales = []string{}
+		}
+		items = append(items, emailTemplateItem{Name: info.Name, Description: info.Description, Overrides: locales})
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "locales": mailer.Locales()})
+}
+
+func (s *Service) GetEmailTemplate(c *gin.Context) {
+	info, ok := mailer.LookupTemplate(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+	locale, ok := templateLocaleParam(c.Query("locale"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale"})
+		return
+	}
+	def, defLocale, _ := mailer.DefaultSource(info.Name, locale)
+	out := emailTemplateDetail{
+		Name:          info.Name,
+		Description:   info.Description,
+		Locale:        locale,
+		DefaultLocale: defLocale,
+		Default:       def,
+		Sample:        info.Sample,
+	}
+
+	var override mailer.TemplateSource
+	err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT subject, body_text, body_html
+		FROM email_template_overrides
+		WHERE tenant_id = $1 AND name = $2 AND locale = $3
+	`, tenantFromContext(c), info.Name, locale).Scan(&override.Subject, &override.Text, &override.HTML)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if err == nil {
+		out.Override = &override
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+func (s *Service) SaveEmailTemplate(c *gin.Context) {
+	info, ok := mailer.LookupTemplate(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+	var req saveEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subject and text are required"})
+		return
+	}
+	locale, ok := templateLocaleParam(req.Locale)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale"})
+		return
+	}
+	src := mailer.TemplateSource{
+		Subject: strings.TrimSpace(req.Subject),
+		Text:    strings.TrimSpace(req.Text),
+		HTML:    strings.TrimSpace(req.HTML),
+	}
+	if err := mailer.ValidateSource(info.Name, src); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template error: " + err.Error()})
+		return
+	}
+
+	if _, err := s.DB.Exec(c.Request.Context(), `
+		INSERT INTO email_template_overrides (tenant_id, name, locale, subject, body_text, body_html, updated_by_email, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		ON CONFLICT (tenant_id, name, locale) DO UPDATE SET
+			subject = EXCLUDED.subject,
+			body_text = EXCLUDED.body_text,
+			body_html = EXCLUDED.body_html,
+			updated_by_email = EXCLUDED.updated_by_email,
+			updated_at = NOW()
+	`, tenantFromContext(c), info.Name, locale, src.Subject, src.Text, src.HTML, strings.ToLower(emailFromContext(c))); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "saved", "name": info.Name, "locale": locale})
+}
+
+func (s *Service) DeleteEmailTemplate(c *gin.Context) {
+	info, ok := mailer.LookupTemplate(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+	locale, ok := templateLocaleParam(c.Query("locale"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale"})
+		return
+	}
+	tag, err := s.DB.Exec(c.Request.Context(), `
+		DELETE FROM email_template_overrides
+		WHERE tenant_id = $1 AND name = $2 AND locale = $3
+	`, tenantFromContext(c), info.Name, locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "override not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "reverted to default"})
+}
+
+// PreviewEmailTemplate renders a template with sample data. When subject and
+// text are supplied they are rendered as an unsaved draft; otherwise the
+// tenant's stored override (or the default) is used. data entries replace the
+// matching sample values.
+func (s *Service) PreviewEmailTemplate(c *gin.Context) {
+	info, ok := mailer.LookupTemplate(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+	var req previewEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	locale, ok := templateLocaleParam(req.Locale)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale"})
+		return
+	}
+	tenantID := tenantFromContext(c)
+
+	overrides, err := s.loadTemplateOverrides(c.Request.Context(), tenantID, info.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if strings.TrimSpace(req.Subject) != "" || strings.TrimSpace(req.Text) != "" {
+		overrides[locale] = mailer.TemplateSource{Subject: req.Subject, Text: req.Text, HTML: req.HTML}
+	}
+	data := make(map[string]any, len(info.Sample)+len(req.Data))
+	for k, v := range info.Sample {
+		data[k] = v
+	}
+	for k, v := range req.Data {
+		data[k] = v
+	}
+
+	msg, rendered, err := mailer.Render(mailer.RenderRequest{
+		Name:      info.Name,
+		Locale:    locale,
+		Brand:     s.tenantBrand(c.Request.Context(), tenantID),
+		Data:      data,
+		Overrides: overrides,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template error: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"locale": rendered, "subject": msg.Subject, "text": msg.Text, "html": msg.HTML})
+}
+
+func (s *Service) GetEmailBranding(c *gin.Context) {
+	brand := s.tenantBrand(c.Request.Context(), tenantFromContext(c))
+	c.JSON(http.StatusOK, gin.H{"name": brand.Name, "logo_url": brand.LogoURL, "accent_color": brand.Accent})
+}
+
+// UpdateEmailBranding sets the accent color. The logo comes from the tenant's
+// logo_url; an empty accent_color restores the default.
+func (s *Service) UpdateEmailBranding(c *gin.Context) {
+	var req emailBrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	accent := strings.ToLower(strings.TrimSpace(req.AccentColor))
+	if accent != "" && !mailer.ValidAccent(accent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "accent_color must be a #rrggbb color"})
+		return
+	}
+	if _, err := s.DB.Exec(c.Request.Context(), `UPDATE tenants SET brand_color = NULLIF($1, '') WHERE id = $2`, accent, tenantFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update branding"})
+		return
+	}
+	s.GetEmailBranding(c)
+}
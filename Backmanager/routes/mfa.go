@@ -0,0 +1,453 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	mfaChallengeTTL         = 5 * time.Minute
+	mfaChallengeMaxAttempts = 5
+	mfaRecoveryCodeCount    = 10
+	mfaIssuer               = "PulseForge"
+)
+
+var mfaPolicies = map[string]struct{}{
+	"optional": {},
+	"admins":   {},
+	"all":      {},
+}
+
+type mfaCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type mfaPolicyRequest struct {
+	Policy string `json:"policy" binding:"required"`
+}
+
+func (s *Service) GetMFAStatus(c *gin.Context) {
+	accountID := accountFromContext(c)
+	var enabled bool
+	var policy string
+	var remaining int64
+	err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT
+			EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL),
+			t.mfa_policy,
+			(SELECT COUNT(*) FROM user_mfa_recovery_codes r WHERE r.user_id = u.id AND r.used_at IS NULL)
+		FROM users u
+		JOIN tenants t ON t.id = u.tenant_id
+		WHERE u.id = $1
+	`, accountID).Scan(&enabled, &policy, &remaining)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load two-factor status"})
+		return
+	}
+	required := mfaRequiredFor(policy, roleFromContext(c))
+	c.JSON(http.StatusOK, gin.H{
+		"enabled":                  enabled,
+		"required":                 required,
+		"policy":                   policy,
+		"recovery_codes_remaining": remaining,
+	})
+}
+
+func (s *Service) EnrollMFA(c *gin.Context) {
+	accountID := accountFromContext(c)
+	enabled, err := s.userMFAEnabled(c.Request.Context(), accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check two-factor status"})
+		return
+	}
+	if enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "secret generation failed"})
+		return
+	}
+	// Re-enrolling before verification replaces the pending secret.
+	if _, err := s.DB.Exec(c.Request.Context(), `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled_at = NULL, last_used_counter = 0, created_at = NOW()
+	`, accountID, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start enrollment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_uri": totpURI(mfaIssuer, strings.ToLower(strings.TrimSpace(emailFromContext(c))), secret),
+	})
+}
+
+func (s *Service) VerifyMFA(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	accountID := accountFromContext(c)
+
+	var secret string
+	var enabledAt *time.Time
+	err := s.DB.QueryRow(c.Request.Context(), `SELECT secret, enabled_at FROM user_mfa WHERE user_id = $1`, accountID).Scan(&secret, &enabledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start enrollment first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load enrollment"})
+		return
+	}
+	if enabledAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "two-factor authentication is already enabled"})
+		return
+	}
+	counter, ok := validateTOTP(secret, req.Code, time.Now())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification code"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE user_mfa
+		SET enabled_at = NOW(), last_used_counter = $2
+		WHERE user_id = $1
+	`, accountID, counter); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to enable two-factor authentication"})
+		return
+	}
+	codes, err := replaceRecoveryCodes(c.Request.Context(), tx, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "enabled", "recovery_codes": codes})
+}
+
+func (s *Service) DisableMFA(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	accountID := accountFromContext(c)
+
+	var policy string
+	if err := s.DB.QueryRow(c.Request.Context(), `SELECT mfa_policy FROM tenants WHERE id = $1`, tenantFromContext(c)).Scan(&policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load organization policy"})
+		return
+	}
+	if mfaRequiredFor(policy, roleFromContext(c)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "your organization requires two-factor authentication"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	ok, err := verifyMFACode(c.Request.Context(), tx, accountID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify code"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification code"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, accountID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable two-factor authentication"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `DELETE FROM user_mfa WHERE user_id = $1`, accountID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to disable two-factor authentication"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "disabled"})
+}
+
+func (s *Service) RegenerateRecoveryCodes(c *gin.Context) {
+	var req mfaCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	accountID := accountFromContext(c)
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	ok, err := verifyMFACode(c.Request.Context(), tx, accountID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify code"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification code"})
+		return
+	}
+	codes, err := replaceRecoveryCodes(c.Request.Context(), tx, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+func (s *Service) UpdateMFAPolicy(c *gin.Context) {
+	var req mfaPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	policy := strings.ToLower(strings.TrimSpace(req.Policy))
+	if _, ok := mfaPolicies[policy]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy must be optional, admins or all"})
+		return
+	}
+	if _, err := s.DB.Exec(c.Request.Context(), `UPDATE tenants SET mfa_policy = $1 WHERE id = $2`, policy, tenantFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "policy": policy})
+}
+
+// CompleteMFAChallenge exchanges the challenge token from the password step
+// plus a TOTP or recovery code for a regular session.
+func (s *Service) CompleteMFAChallenge(c *gin.Context) {
+	var req mfaChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var challengeID int64
+	var userID int64
+	err = tx.QueryRow(c.Request.Context(), `
+		UPDATE mfa_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1 AND consumed_at IS NULL AND expires_at > NOW() AND attempts < $2
+		RETURNING id, user_id
+	`, hashToken(strings.TrimSpace(req.ChallengeToken)), mfaChallengeMaxAttempts).Scan(&challengeID, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "challenge expired. Sign in again."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "challenge lookup failed"})
+		return
+	}
+
+	var u loginSubject
+	var blocked bool
+	var lockedUntil *time.Time
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT u.id, t.id, u.public_id, lower(u.email), u.name, u.role, t.slug, t.name, COALESCE(t.logo_url, ''), COALESCE(t.max_sessions, 5), COALESCE(u.blocked, false),
+		       CASE WHEN u.locked_until > NOW() THEN u.locked_until END
+		FROM users u
+		JOIN tenants t ON t.id = u.tenant_id
+		WHERE u.id = $1
+	`, userID).Scan(&u.UserID, &u.TenantID, &u.PublicID, &u.Email, &u.Name, &u.Role, &u.TenantSlug, &u.TenantName, &u.TenantLogo, &u.MaxSessions, &blocked, &lockedUntil)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+		return
+	}
+	// A challenge issued before the lock cannot be used to keep guessing.
+	if lockedUntil != nil {
+		c.JSON(http.StatusLocked, gin.H{"error": "account temporarily locked after repeated failed sign-ins. Try again later or contact your organization admin.", "locked_until": lockedUntil})
+		return
+	}
+
+	ok, err := verifyMFACode(c.Request.Context(), tx, userID, req.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify code"})
+		return
+	}
+	if !ok {
+		// Keep the attempt count so the challenge burns out after repeated guesses.
+		if err := tx.Commit(c.Request.Context()); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+			return
+		}
+		// Wrong codes count toward the account lockout too, so fresh
+		// challenges from the password step do not give unlimited guesses.
+		failures, lockedNow, err := s.recordLoginFailure(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification code"})
+			return
+		}
+		if lockedNow != nil {
+			s.notifyAccountLocked(u.TenantID, u.TenantSlug, u.Email, u.Role, strings.TrimSpace(c.ClientIP()), *lockedNow)
+			c.JSON(http.StatusLocked, gin.H{"error": "account temporarily locked after repeated failed sign-ins. Try again later or contact your organization admin.", "locked_until": lockedNow})
+			return
+		}
+		sleepCtx(c.Request.Context(), loginFailureDelay(failures))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid verification code"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `UPDATE mfa_challenges SET consumed_at = NOW() WHERE id = $1`, challengeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "challenge update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusForbidden, gin.H{"error": "account access is blocked. Contact your organization admin."})
+		return
+	}
+
+	s.completeLogin(c, u)
+}
+
+// mfaRequiredFor mirrors the enrollment rule RequireActiveUser applies in SQL.
+func mfaRequiredFor(policy, role string) bool {
+	switch policy {
+	case "all":
+		return true
+	case "admins":
+		return role == RoleOwner || role == RoleAdmin || role == RoleSystemAdmin
+	}
+	return false
+}
+
+func (s *Service) userMFAEnabled(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	err := s.DB.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM user_mfa WHERE user_id = $1 AND enabled_at IS NOT NULL)
+	`, userID).Scan(&enabled)
+	return enabled, err
+}
+
+func (s *Service) startMFAChallenge(ctx context.Context, userID int64) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	_, err = s.DB.Exec(ctx, `
+		INSERT INTO mfa_challenges (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userID, hashToken(token), time.Now().Add(mfaChallengeTTL))
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// verifyMFACode accepts a current TOTP code or an unused recovery code. TOTP
+// counters must advance past the last accepted one, and recovery codes are
+// consumed, so neither can be replayed.
+func verifyMFACode(ctx context.Context, tx pgx.Tx, userID int64, code string) (bool, error) {
+	var secret string
+	var lastCounter int64
+	err := tx.QueryRow(ctx, `
+		SELECT secret, last_used_counter
+		FROM user_mfa
+		WHERE user_id = $1 AND enabled_at IS NOT NULL
+		FOR UPDATE
+	`, userID).Scan(&secret, &lastCounter)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if counter, ok := validateTOTP(secret, code, time.Now()); ok {
+		if counter <= lastCounter {
+			return false, nil
+		}
+		_, err := tx.Exec(ctx, `UPDATE user_mfa SET last_used_counter = $2 WHERE user_id = $1`, userID, counter)
+		return err == nil, err
+	}
+
+	cmd, err := tx.Exec(ctx, `
+		UPDATE user_mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID int64) ([]string, error) {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, mfaRecoveryCodeCount)
+	for i := 0; i < mfaRecoveryCodeCount; i++ {
+		raw, err := newTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(raw[:5] + "-" + raw[5:10])
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_mfa_recovery_codes (user_id, code_hash)
+			VALUES ($1, $2)
+		`, userID, hashToken(normalizeRecoveryCode(code))); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
@@ -0,0 +1,181 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	channelAll   = "all"
+	channelInApp = "in_app"
+	channelEmail = "email"
+	channelNone  = "none"
+)
+
+// notificationTypes are the types a user can route. Security and account mail
+// (welcome, lockout, password changes, invitations) has no type and is always
+// sent.
+var notificationTypes = []string{"approval", "project", "task", "summary"}
+
+// templateNotificationType maps email templates to the notification type whose
+// preference governs them.
+var templateNotificationType = map[string]string{
+	"approval_needed":    "approval",
+	"approval_rejected":  "approval",
+	"approval_completed": "approval",
+	"project_created":    "project",
+	"project_reminder":   "project",
+	"overdue_alert":      "project",
+	"task_created":       "task",
+	"reminder_summary":   "summary",
+	"daily_digest":       "summary",
+}
+
+// errNotificationOptedOut is returned by enqueueTemplate when the recipient
+// turned email off for the template's notification type.
+var errNotificationOptedOut = errors.New("recipient opted out of this notification")
+
+func validChannel(ch string) bool {
+	switch ch {
+	case channelAll, channelInApp, channelEmail, channelNone:
+		return true
+	}
+	return false
+}
+
+func channelAllows(ch, want string) bool {
+	switch ch {
+	case "", channelAll:
+		return true
+	case channelNone:
+		return false
+	}
+	return ch == want
+}
+
+// notificationChannels returns the configured channel for notifType per
+// recipient. Recipients without a preference are absent from the map. A
+// lookup error is treated as "no preferences" so delivery is never lost to a
+// transient failure.
+func (s *Service) notificationChannels(ctx context.Context, tenantID int64, recipients []string, notifType string) map[string]string {
+	out := make(map[string]string)
+	notifType = strings.TrimSpace(notifType)
+	if tenantID <= 0 || notifType == "" || len(recipients) == 0 {
+		return out
+	}
+	rows, err := s.DB.Query(ctx, `
+		SELECT lower(user_email), notification_channels->>$3
+		FROM user_settings
+		WHERE tenant_id = $1 AND lower(user_email) = ANY($2) AND notification_channels ? $3
+	`, tenantID, uniqueEmails(recipients), notifType)
+	if err != nil {
+		return out
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var email, ch string
+		if err := rows.Scan(&email, &ch); err != nil {
+			return out
+		}
+		out[email] = ch
+	}
+	return out
+}
+
+func (s *Service) wantsEmail(ctx context.Context, tenantID int64, to, template string) bool {
+	notifType := templateNotificationType[template]
+	if notifType == "" {
+		return true
+	}
+	ch := s.notificationChannels(ctx, tenantID, []string{to}, notifType)[strings.ToLower(strings.TrimSpace(to))]
+	return channelAllows(ch, channelEmail)
+}
+
+func (s *Service) GetNotificationPreferences(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
+		return
+	}
+
+	var raw []byte
+	err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT notification_channels
+		FROM user_settings
+		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
+	`, tenantID, email).Scan(&raw)
+	stored := map[string]string{}
+	if err == nil {
+		_ = json.Unmarshal(raw, &stored)
+	}
+
+	channels := make(map[string]string, len(notificationTypes))
+	for _, t := range notificationTypes {
+		channels[t] = channelAll
+		if ch := stored[t]; validChannel(ch) {
+			channels[t] = ch
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"channels": channels,
+		"types":    notificationTypes,
+		"options":  []string{channelAll, channelInApp, channelEmail, channelNone},
+	})
+}
+
+func (s *Service) UpdateNotificationPreferences(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
+		return
+	}
+
+	var req struct {
+		Channels map[string]string `json:"channels" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	known := make(map[string]bool, len(notificationTypes))
+	for _, t := range notificationTypes {
+		known[t] = true
+	}
+	channels := make(map[string]string, len(req.Channels))
+	for t, ch := range req.Channels {
+		t = strings.ToLower(strings.TrimSpace(t))
+		ch = strings.ToLower(strings.TrimSpace(ch))
+		if !known[t] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown notification type: " + t})
+			return
+		}
+		if !validChannel(ch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "channel must be all, in_app, email or none"})
+			return
+		}
+		if ch != channelAll {
+			channels[t] = ch
+		}
+	}
+	channelsJSON, _ := json.Marshal(channels)
+
+	if _, err := s.DB.Exec(c.Request.Context(), `
+		INSERT INTO user_settings (tenant_id, user_email, notification_channels)
+		VALUES ($1, $2, $3::jsonb)
+		ON CONFLICT (tenant_id, user_email) DO UPDATE SET
+			notification_channels = EXCLUDED.notification_channels,
+			updated_at = NOW()
+	`, tenantID, email, string(channelsJSON)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "saved"})
+}
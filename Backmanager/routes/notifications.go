@@ -13,13 +13,14 @@ import (
 
 type notificationItem struct {
 	ID             string          `json:"id"`
-	TenantID       string          `json:"tenant_id"`
+	TenantID       int64           `json:"tenant_id"`
 	RecipientEmail string          `json:"recipient_email"`
 	Type           string          `json:"type"`
 	Title          string          `json:"title"`
 	Detail         string          `json:"detail"`
 	Meta           json.RawMessage `json:"meta,omitempty"`
 	ReadAt         *time.Time      `json:"read_at,omitempty"`
+	ArchivedAt     *time.Time      `json:"archived_at,omitempty"`
 	CreatedAt      time.Time       `json:"created_at"`
 }
 
@@ -28,12 +29,16 @@ type reminderSummary struct {
 	AssignedPendingProjects int64 `json:"assigned_pending_projects"`
 	OverdueProjects         int64 `json:"overdue_projects"`
 	OpenTasks               int64 `json:"open_tasks"`
+	OverdueTasks            int64 `json:"overdue_tasks"`
 }
 
+// ListNotifications pages newest-first by id. Pass the returned next_cursor
+// as ?cursor= for the next page; type, status (unread|read) and archived=true
+// narrow the results. The synthetic work summary is only on the first page.
 func (s *Service) ListNotifications(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || recipient == "" {
+	if tenantID <= 0 || recipient == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
 		return
 	}
@@ -45,14 +50,34 @@ func (s *Service) ListNotifications(c *gin.Context) {
 			limit = n
 		}
 	}
+	var cursor int64
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		cursor = n
+	}
+	notifType := strings.ToLower(strings.TrimSpace(c.Query("type")))
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && status != "unread" && status != "read" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be unread or read"})
+		return
+	}
+	archived := c.Query("archived") == "true"
 
 	rows, err := s.DB.Query(c.Request.Context(), `
-		SELECT id, tenant_id, recipient_email, type, title, detail, COALESCE(meta, '{}'::jsonb), read_at, created_at
+		SELECT id, tenant_id, recipient_email, type, title, detail, COALESCE(meta, '{}'::jsonb), read_at, archived_at, created_at
 		FROM notifications
 		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2)
-		ORDER BY created_at DESC, id DESC
-		LIMIT $3
-	`, tenantID, recipient, limit)
+		  AND ($3 = 0 OR id < $3)
+		  AND ($4 = '' OR type = $4)
+		  AND ($5 = '' OR ($5 = 'unread' AND read_at IS NULL) OR ($5 = 'read' AND read_at IS NOT NULL))
+		  AND (archived_at IS NOT NULL) = $6
+		ORDER BY id DESC
+		LIMIT $7
+	`, tenantID, recipient, cursor, notifType, status, archived, limit+1)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
 		return
@@ -65,7 +90,7 @@ func (s *Service) ListNotifications(c *gin.Context) {
 			id int64
 			it notificationItem
 		)
-		if err := rows.Scan(&id, &it.TenantID, &it.RecipientEmail, &it.Type, &it.Title, &it.Detail, &it.Meta, &it.ReadAt, &it.CreatedAt); err != nil {
+		if err := rows.Scan(&id, &it.TenantID, &it.RecipientEmail, &it.Type, &it.Title, &it.Detail, &it.Meta, &it.ReadAt, &it.ArchivedAt, &it.CreatedAt); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 			return
 		}
@@ -76,36 +101,78 @@ func (s *Service) ListNotifications(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 		return
 	}
-
-	summary, err := s.loadReminderSummary(c.Request.Context(), tenantID, recipient)
-	if err == nil {
-		s.maybeDispatchScheduledReminder(c.Request.Context(), tenantID, recipient, summary)
+	nextCursor := ""
+	if len(items) > limit {
+		items = items[:limit]
+		nextCursor = items[limit-1].ID
 	}
-	if err == nil {
-		items = append([]notificationItem{{
-			ID:             "summary-" + strconv.FormatInt(time.Now().UTC().Unix(), 10),
-			TenantID:       tenantID,
-			RecipientEmail: recipient,
-			Type:           "summary",
-			Title:          "Work summary reminder",
-			Detail: "Pending projects: " + strconv.FormatInt(summary.PendingProjects, 10) +
-				" | Assigned to you: " + strconv.FormatInt(summary.AssignedPendingProjects, 10) +
-				" | Overdue projects: " + strconv.FormatInt(summary.OverdueProjects, 10) +
-				" | Open tasks: " + strconv.FormatInt(summary.OpenTasks, 10),
-			CreatedAt: time.Now().UTC(),
-		}}, items...)
+
+	var summary *reminderSummary
+	if cursor == 0 && notifType == "" && status == "" && !archived {
+		if loaded, err := s.loadReminderSummary(c.Request.Context(), tenantID, recipient); err == nil {
+			summary = &loaded
+			items = append([]notificationItem{{
+				ID:             "summary-" + strconv.FormatInt(time.Now().UTC().Unix(), 10),
+				TenantID:       tenantID,
+				RecipientEmail: recipient,
+				Type:           "summary",
+				Title:          "Work summary reminder",
+				Detail: "Pending projects: " + strconv.FormatInt(loaded.PendingProjects, 10) +
+					" | Assigned to you: " + strconv.FormatInt(loaded.AssignedPendingProjects, 10) +
+					" | Overdue projects: " + strconv.FormatInt(loaded.OverdueProjects, 10) +
+					" | Your open tasks: " + strconv.FormatInt(loaded.OpenTasks, 10) +
+					" | Your overdue tasks: " + strconv.FormatInt(loaded.OverdueTasks, 10),
+				CreatedAt: time.Now().UTC(),
+			}}, items...)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"items":   items,
-		"summary": summary,
+		"items":       items,
+		"summary":     summary,
+		"next_cursor": nextCursor,
 	})
 }
 
+func (s *Service) NotificationUnreadCount(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || recipient == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
+		return
+	}
+
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT type, COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2) AND read_at IS NULL AND archived_at IS NULL
+		GROUP BY type
+	`, tenantID, recipient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	var total int64
+	byType := make(map[string]int64)
+	for rows.Next() {
+		var t string
+		var n int64
+		if err := rows.Scan(&t, &n); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		byType[t] = n
+		total += n
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": total, "by_type": byType})
+}
+
 func (s *Service) MarkNotificationRead(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || recipient == "" {
+	if tenantID <= 0 || recipient == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
 		return
 	}
@@ -133,7 +200,97 @@ func (s *Service) MarkNotificationRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "read"})
 }
 
-func (s *Service) loadReminderSummary(ctx context.Context, tenantID, recipient string) (reminderSummary, error) {
+// MarkAllNotificationsRead marks every unread notification read, optionally
+// only those of ?type=.
+func (s *Service) MarkAllNotificationsRead(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || recipient == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
+		return
+	}
+
+	cmd, err := s.DB.Exec(c.Request.Context(), `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2) AND read_at IS NULL
+		  AND ($3 = '' OR type = $3)
+	`, tenantID, recipient, strings.ToLower(strings.TrimSpace(c.Query("type"))))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "read", "updated": cmd.RowsAffected()})
+}
+
+// ArchiveNotification archives a notification, or restores it to the inbox
+// with ?restore=true. Archiving also marks it read.
+func (s *Service) ArchiveNotification(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || recipient == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	restore := c.Query("restore") == "true"
+	cmd, err := s.DB.Exec(c.Request.Context(), `
+		UPDATE notifications
+		SET archived_at = CASE WHEN $4 THEN NULL ELSE NOW() END,
+		    read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND tenant_id = $2 AND lower(recipient_email) = lower($3)
+	`, id, tenantID, recipient, restore)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+	status := "archived"
+	if restore {
+		status = "restored"
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+func (s *Service) DeleteNotification(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || recipient == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification id"})
+		return
+	}
+
+	cmd, err := s.DB.Exec(c.Request.Context(), `
+		DELETE FROM notifications
+		WHERE id = $1 AND tenant_id = $2 AND lower(recipient_email) = lower($3)
+	`, id, tenantID, recipient)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (s *Service) loadReminderSummary(ctx context.Context, tenantID int64, recipient string) (reminderSummary, error) {
 	var summary reminderSummary
 	err := s.DB.QueryRow(ctx, `
 		SELECT
@@ -153,7 +310,7 @@ func (s *Service) loadReminderSummary(ctx context.Context, tenantID, recipient s
 				SELECT COUNT(*)
 				FROM projects p
 				WHERE p.tenant_id = $1
-					AND lower(trim(p.status)) NOT IN ('done', 'completed', 'closed')
+					AND p.completed_at IS NULL
 					AND p.due_date IS NOT NULL
 					AND p.due_date < CURRENT_DATE
 			) AS overdue_projects,
@@ -161,37 +318,49 @@ func (s *Service) loadReminderSummary(ctx context.Context, tenantID, recipient s
 				SELECT COUNT(*)
 				FROM tasks t
 				WHERE t.tenant_id = $1
-					AND lower(trim(t.status)) NOT IN ('done', 'completed', 'closed')
-			) AS open_tasks
+					AND t.assignees ? lower($2)
+					AND t.completed_at IS NULL
+			) AS open_tasks,
+			(
+				SELECT COUNT(*)
+				FROM tasks t
+				WHERE t.tenant_id = $1
+					AND t.assignees ? lower($2)
+					AND t.completed_at IS NULL
+					AND t.due_date < CURRENT_DATE
+			) AS overdue_tasks
 	`, tenantID, recipient).Scan(
 		&summary.PendingProjects,
 		&summary.AssignedPendingProjects,
 		&summary.OverdueProjects,
 		&summary.OpenTasks,
+		&summary.OverdueTasks,
 	)
 	return summary, err
 }
 
-func (s *Service) countOpenProjectTasks(ctx context.Context, tenantID string, projectID int64) (int64, error) {
+func (s *Service) countOpenProjectTasks(ctx context.Context, tenantID int64, projectID int64) (int64, error) {
 	var count int64
 	err := s.DB.QueryRow(ctx, `
 		SELECT COUNT(*)
 		FROM tasks
 		WHERE tenant_id = $1
 			AND project_id = $2
-			AND lower(trim(status)) NOT IN ('done', 'completed', 'closed')
+			AND completed_at IS NULL
 	`, tenantID, projectID).Scan(&count)
 	return count, err
 }
 
 func (s *Service) notifyProjectAssignees(
 	ctx context.Context,
-	tenantID string,
+	tenantID int64,
 	projectID int64,
 	projectName string,
 	assignees []string,
 	subject string,
 	detail string,
+	dueDate string,
+	openTasks int64,
 ) {
 	recipients := uniqueEmails(assignees)
 	if len(recipients) == 0 {
@@ -204,26 +373,32 @@ func (s *Service) notifyProjectAssignees(
 		"status":       "pending",
 	})
 
-	message := "Project reminder\n\nProject: " + projectName + "\nStatus: pending\nSummary: " + detail + "\n\nPlease review and close pending work items."
+	data := map[string]any{"ProjectName": projectName, "DueDate": dueDate, "OpenTasks": openTasks}
 	for _, to := range recipients {
-		s.sendAsyncNotification(to, subject, message)
+		s.sendAsyncNotification(tenantID, to, "project_reminder", data)
 	}
 }
 
+// createInAppNotification skips recipients who routed notifType away from the
+// in-app channel.
 func (s *Service) createInAppNotification(
 	ctx context.Context,
-	tenantID string,
+	tenantID int64,
 	recipients []string,
 	notifType string,
 	title string,
 	detail string,
 	meta map[string]any,
 ) error {
-	if strings.TrimSpace(tenantID) == "" || len(recipients) == 0 {
+	if tenantID <= 0 || len(recipients) == 0 {
 		return nil
 	}
+	channels := s.notificationChannels(ctx, tenantID, recipients, notifType)
 	metaJSON, _ := json.Marshal(meta)
 	for _, recipient := range recipients {
+		if !channelAllows(channels[strings.ToLower(strings.TrimSpace(recipient))], channelInApp) {
+			continue
+		}
 		if _, err := s.DB.Exec(ctx, `
 			INSERT INTO notifications (tenant_id, recipient_email, type, title, detail, meta)
 			VALUES ($1, $2, $3, $4, $5, $6::jsonb)
@@ -250,94 +425,3 @@ func uniqueEmails(items []string) []string {
 	}
 	return out
 }
-
-func (s *Service) maybeDispatchScheduledReminder(ctx context.Context, tenantID, recipient string, summary reminderSummary) {
-	var (
-		timezone         string
-		frequency        string
-		reminderDaysRaw  []byte
-		reminderTime     string
-		remindersEnabled bool
-		lastSentAt       *time.Time
-	)
-	err := s.DB.QueryRow(ctx, `
-		SELECT timezone, reminder_frequency, reminder_days, reminder_time, reminders_enabled, last_reminder_sent_at
-		FROM user_settings
-		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
-	`, tenantID, recipient).Scan(&timezone, &frequency, &reminderDaysRaw, &reminderTime, &remindersEnabled, &lastSentAt)
-	if err != nil || !remindersEnabled {
-		return
-	}
-
-	days := parseStringArrayJSON(reminderDaysRaw)
-	loc := time.UTC
-	if tz := strings.TrimSpace(timezone); tz != "" {
-		if loaded, err := time.LoadLocation(tz); err == nil {
-			loc = loaded
-		}
-	}
-	now := time.Now().In(loc)
-	targetTime, err := time.Parse("15:04", strings.TrimSpace(reminderTime))
-	if err != nil {
-		targetTime, _ = time.Parse("15:04", "09:00")
-	}
-	dueClock := time.Date(now.Year(), now.Month(), now.Day(), targetTime.Hour(), targetTime.Minute(), 0, 0, loc)
-	if now.Before(dueClock) {
-		return
-	}
-	if lastSentAt != nil {
-		lastLocal := lastSentAt.In(loc)
-		if lastLocal.Year() == now.Year() && lastLocal.Month() == now.Month() && lastLocal.Day() == now.Day() {
-			return
-		}
-	}
-
-	todayWeekday := strings.ToLower(now.Weekday().String())
-	shouldSend := false
-	switch strings.ToLower(strings.TrimSpace(frequency)) {
-	case "daily":
-		shouldSend = true
-	case "weekly":
-		for _, d := range days {
-			if strings.ToLower(strings.TrimSpace(d)) == todayWeekday {
-				shouldSend = true
-				break
-			}
-		}
-	case "custom":
-		for _, d := range days {
-			if strings.ToLower(strings.TrimSpace(d)) == todayWeekday {
-				shouldSend = true
-				break
-			}
-		}
-	default:
-		shouldSend = true
-	}
-	if !shouldSend {
-		return
-	}
-
-	subject := "PulseForge reminder: pending work summary"
-	message := "Hello,\n\nThis is your scheduled reminder.\n\n" +
-		"Pending projects: " + strconv.FormatInt(summary.PendingProjects, 10) + "\n" +
-		"Assigned pending projects: " + strconv.FormatInt(summary.AssignedPendingProjects, 10) + "\n" +
-		"Overdue projects: " + strconv.FormatInt(summary.OverdueProjects, 10) + "\n" +
-		"Open tasks: " + strconv.FormatInt(summary.OpenTasks, 10) + "\n\n" +
-		"Please review and close outstanding work items."
-
-	if err := s.sendMail(context.Background(), recipient, subject, message); err != nil {
-		return
-	}
-	_ = s.createInAppNotification(ctx, tenantID, []string{recipient}, "summary", "Scheduled reminder", "A scheduled summary reminder has been sent to your email.", map[string]any{
-		"pending_projects":          summary.PendingProjects,
-		"assigned_pending_projects": summary.AssignedPendingProjects,
-		"overdue_projects":          summary.OverdueProjects,
-		"open_tasks":                summary.OpenTasks,
-	})
-	_, _ = s.DB.Exec(ctx, `
-		UPDATE user_settings
-		SET last_reminder_sent_at = NOW(), updated_at = NOW()
-		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
-	`, tenantID, recipient)
-}
@@ -0,0 +1,325 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backmanager/mailer"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	outboxBatchSize   = 10
+	outboxMaxAttempts = 8
+	// outboxLease is how long a claimed message stays invisible to other
+	// workers; a worker that dies mid-send releases it when the lease lapses.
+	outboxLease       = 2 * time.Minute
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = 2 * time.Hour
+	// outboxRetention is how long sent and dead rows stay visible in the admin
+	// view before the purge job deletes them.
+	outboxRetention = 30 * 24 * time.Hour
+)
+
+type outboxMessage struct {
+	ID            int64      `json:"id"`
+	Category      string     `json:"category"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	MaxAttempts   int        `json:"max_attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// claimedMail is a message leased to one worker. LockedUntil identifies the
+// lease: results are only recorded while the row still carries it.
+type claimedMail struct {
+	ID          int64
+	Attempts    int
+	MaxAttempts int
+	LockedUntil time.Time
+	Message     mailer.Message
+}
+
+// enqueueMail stores a rendered message in email_outbox for the worker pool.
+// category is a free-form label ("system_update", "approval", ...) used when
+// filtering the admin view. Bodies can carry live reset and invite tokens, so
+// they are cleared once the row is sent or dead.
+func (s *Service) enqueueMail(ctx context.Context, category string, msg mailer.Message) error {
+	if strings.TrimSpace(msg.To) == "" {
+		return errors.New("missing recipient email")
+	}
+	_, err := s.DB.Exec(ctx, `
+		INSERT INTO email_outbox (category, recipient, subject, body_text, body_html, max_attempts)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, category, strings.TrimSpace(msg.To), msg.Subject, msg.Text, msg.HTML, outboxMaxAttempts)
+	if err != nil {
+		return err
+	}
+	s.wakeOutbox()
+	return nil
+}
+
+func (s *Service) wakeOutbox() {
+	select {
+	case s.outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunEmailWorkers drains email_outbox with n concurrent workers until ctx is
+// cancelled. Workers poll every interval and wake early when mail is enqueued.
+// Rows are claimed with FOR UPDATE SKIP LOCKED, so any number of replicas can
+// run workers against the same table.
+func (s *Service) RunEmailWorkers(ctx context.Context, n int, interval time.Duration) {
+	if n < 1 {
+		n = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.emailWorker(ctx, interval)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Service) emailWorker(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.outboxWake:
+		}
+
+		claimed, err := s.claimOutbox(ctx, outboxBatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("email outbox claim failed: %v", err)
+		}
+		for _, m := range claimed {
+			s.deliverOutbox(ctx, m)
+		}
+
+		next := interval
+		if len(claimed) == outboxBatchSize {
+			next = 0
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+// claimOutbox leases up to limit due messages. A lapsed lease on a message
+// that already used its last attempt is not retried; the row is marked dead.
+func (s *Service) claimOutbox(ctx context.Context, limit int) ([]claimedMail, error) {
+	if _, err := s.DB.Exec(ctx, `
+		UPDATE email_outbox
+		SET status = 'dead', locked_until = NULL, body_text = '', body_html = '',
+		    last_error = COALESCE(last_error, 'lease expired on the last attempt'), updated_at = NOW()
+		WHERE status = 'sending' AND locked_until < NOW() AND attempts >= max_attempts
+	`); err != nil {
+		return nil, err
+	}
+	rows, err := s.DB.Query(ctx, `
+		UPDATE email_outbox
+		SET status = 'sending', attempts = attempts + 1, locked_until = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id IN (
+			SELECT id
+			FROM email_outbox
+			WHERE (status = 'pending' AND next_attempt_at <= NOW())
+			   OR (status = 'sending' AND locked_until < NOW() AND attempts < max_attempts)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, attempts, max_attempts, locked_until, recipient, subject, body_text, body_html
+	`, limit, outboxLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]claimedMail, 0, limit)
+	for rows.Next() {
+		var m claimedMail
+		if err := rows.Scan(&m.ID, &m.Attempts, &m.MaxAttempts, &m.LockedUntil, &m.Message.To, &m.Message.Subject, &m.Message.Text, &m.Message.HTML); err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (s *Service) deliverOutbox(ctx context.Context, m claimedMail) {
+	var sendErr error
+	if s.Mailer == nil {
+		sendErr = errors.New("mail transport not configured")
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, outboxLease/2)
+		sendErr = s.Mailer.Send(sendCtx, m.Message)
+		cancel()
+	}
+
+	// Bookkeeping must land even if ctx was cancelled mid-send. Each update
+	// only applies while this worker's lease is still on the row; if the send
+	// outlasted the lease, another worker owns the message now.
+	bg := context.Background()
+	var tag pgconn.CommandTag
+	var err error
+	switch {
+	case sendErr == nil:
+		tag, err = s.DB.Exec(bg, `
+			UPDATE email_outbox
+			SET status = 'sent', sent_at = NOW(), locked_until = NULL, last_error = NULL, body_text = '', body_html = '', updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, m.ID, m.LockedUntil)
+	case m.Attempts >= m.MaxAttempts:
+		log.Printf("email outbox %d dead after %d attempts: %v", m.ID, m.Attempts, sendErr)
+		tag, err = s.DB.Exec(bg, `
+			UPDATE email_outbox
+			SET status = 'dead', locked_until = NULL, last_error = $3, body_text = '', body_html = '', updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, m.ID, m.LockedUntil, sendErr.Error())
+	default:
+		tag, err = s.DB.Exec(bg, `
+			UPDATE email_outbox
+			SET status = 'pending', locked_until = NULL, last_error = $3, next_attempt_at = $4, updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, m.ID, m.LockedUntil, sendErr.Error(), time.Now().Add(outboxBackoff(m.Attempts)))
+	}
+	if err != nil {
+		log.Printf("email outbox %d: failed to record result: %v", m.ID, err)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("email outbox %d: lease lost before the result was recorded", m.ID)
+	}
+}
+
+// runOutboxPurgeJob deletes sent and dead messages older than outboxRetention.
+func (s *Service) runOutboxPurgeJob(ctx context.Context, now time.Time) (int, error) {
+	tag, err := s.DB.Exec(ctx, `
+		DELETE FROM email_outbox
+		WHERE status IN ('sent', 'dead') AND updated_at < $1
+	`, now.Add(-outboxRetention))
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// outboxBackoff doubles from outboxBaseBackoff per attempt, capped, with up to
+// 20% jitter so a provider outage does not produce synchronized retry waves.
+func outboxBackoff(attempts int) time.Duration {
+	d := outboxMaxBackoff
+	if attempts < 20 {
+		if b := outboxBaseBackoff << (attempts - 1); b > 0 && b < outboxMaxBackoff {
+			d = b
+		}
+	}
+	return d + time.Duration(rand.Int64N(int64(d)/5+1))
+}
+
+func (s *Service) SystemEmailOutbox(c *gin.Context) {
+	limit := 100
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, category, recipient, subject, status, attempts, max_attempts, COALESCE(last_error, ''), next_attempt_at, created_at, sent_at
+		FROM email_outbox
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]outboxMessage, 0, limit)
+	for rows.Next() {
+		var item outboxMessage
+		if err := rows.Scan(&item.ID, &item.Category, &item.Recipient, &item.Subject, &item.Status, &item.Attempts, &item.MaxAttempts, &item.LastError, &item.NextAttemptAt, &item.CreatedAt, &item.SentAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+
+	counts := map[string]int64{"pending": 0, "sending": 0, "sent": 0, "dead": 0}
+	countRows, err := s.DB.Query(c.Request.Context(), `SELECT status, COUNT(*) FROM email_outbox GROUP BY status`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer countRows.Close()
+	for countRows.Next() {
+		var st string
+		var n int64
+		if err := countRows.Scan(&st, &n); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		counts[st] = n
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "counts": counts})
+}
+
+// RetryOutboxMessage puts a stuck pending message back in the queue with a
+// fresh attempt budget. Dead messages have had their bodies cleared and must
+// be sent again from where they came from.
+func (s *Service) RetryOutboxMessage(c *gin.Context) {
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid message id"})
+		return
+	}
+	var status string
+	err = s.DB.QueryRow(c.Request.Context(), `
+		UPDATE email_outbox
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), locked_until = NULL, updated_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING status
+	`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "message not found or no longer retryable"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to requeue message"})
+		return
+	}
+	s.wakeOutbox()
+	c.JSON(http.StatusOK, gin.H{"status": "queued"})
+}
@@ -0,0 +1,259 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTTL = 30 * time.Minute
+	// Per-hour caps on reset emails. The email cap is enforced silently so the
+	// response never reveals whether an account exists.
+	passwordResetPerEmail = 3
+	passwordResetPerIP    = 10
+)
+
+const passwordResetAccepted = "if the account exists, a reset email has been sent"
+
+type forgotPasswordRequest struct {
+	TenantSlug string `json:"tenant_slug"`
+	Email      string `json:"email" binding:"required,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+func (s *Service) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload", "detail": err.Error()})
+		return
+	}
+
+	req.TenantSlug = normalizeSlug(req.TenantSlug)
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	clientIP := strings.TrimSpace(c.ClientIP())
+	if !s.allowAttempt(c, resetIPRule, "reset:ip:"+clientIP) {
+		return
+	}
+
+	var perIP int64
+	var perEmail int64
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT
+			COUNT(*) FILTER (WHERE requested_ip = $1),
+			COUNT(*) FILTER (WHERE lower(email) = lower($2))
+		FROM password_reset_tokens
+		WHERE created_at > NOW() - INTERVAL '1 hour'
+		  AND (requested_ip = $1 OR lower(email) = lower($2))
+	`, clientIP, req.Email).Scan(&perIP, &perEmail); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "rate limit check failed"})
+		return
+	}
+	if perIP >= passwordResetPerIP {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "too many reset requests. Try again later."})
+		return
+	}
+	if perEmail >= passwordResetPerEmail {
+		c.JSON(http.StatusOK, gin.H{"status": passwordResetAccepted})
+		return
+	}
+
+	var userID int64
+	var tenantID int64
+	var name string
+	var tenantName string
+	var err error
+	if req.TenantSlug != "" {
+		err = s.DB.QueryRow(c.Request.Context(), `
+			SELECT u.id, t.id, u.name, t.name
+			FROM users u
+			JOIN tenants t ON t.id = u.tenant_id
+			WHERE t.slug = $1 AND lower(u.email) = lower($2)
+		`, req.TenantSlug, req.Email).Scan(&userID, &tenantID, &name, &tenantName)
+	} else {
+		err = s.DB.QueryRow(c.Request.Context(), `
+			SELECT u.id, t.id, u.name, t.name
+			FROM users u
+			JOIN tenants t ON t.id = u.tenant_id
+			WHERE lower(u.email) = lower($1)
+			ORDER BY u.id DESC
+			LIMIT 1
+		`, req.Email).Scan(&userID, &tenantID, &name, &tenantName)
+	}
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"status": passwordResetAccepted})
+		return
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+	if _, err := s.DB.Exec(c.Request.Context(), `
+		INSERT INTO password_reset_tokens (user_id, email, token_hash, requested_ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, req.Email, hashToken(token), clientIP, time.Now().Add(passwordResetTTL)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "reset request failed"})
+		return
+	}
+
+	if err := s.enqueueTemplate(c.Request.Context(), "password_reset", tenantID, req.Email, "password_reset", map[string]any{
+		"Name":           name,
+		"TenantName":     tenantName,
+		"ResetURL":       appURL("/auth?reset=" + url.QueryEscape(token)),
+		"ExpiresMinutes": int(passwordResetTTL.Minutes()),
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "reset request failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": passwordResetAccepted})
+}
+
+func (s *Service) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hashing failed"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var userID int64
+	var email string
+	var tenantID int64
+	err = tx.QueryRow(c.Request.Context(), `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email
+	`, hashToken(strings.TrimSpace(req.Token))).Scan(&userID, &email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reset link is invalid or has expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password reset failed"})
+		return
+	}
+	if err := tx.QueryRow(c.Request.Context(), `
+		UPDATE users
+		SET password_hash = $1, failed_login_count = 0, locked_until = NULL
+		WHERE id = $2
+		RETURNING tenant_id
+	`, string(hash), userID).Scan(&tenantID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password update failed"})
+		return
+	}
+	// Any other outstanding links for this account are now stale.
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password reset failed"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE user_sessions
+		SET revoked_at = NOW(), revoked_reason = 'password_reset'
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+
+	s.sendAsyncNotification(tenantID, email, "password_changed", nil)
+	c.JSON(http.StatusOK, gin.H{"status": "password updated"})
+}
+
+func (s *Service) ChangePassword(c *gin.Context) {
+	var req changePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+
+	var userID int64
+	var currentHash string
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT id, password_hash
+		FROM users
+		WHERE tenant_id = $1 AND lower(email) = lower($2)
+	`, tenantID, email).Scan(&userID, &currentHash); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(currentHash), []byte(req.CurrentPassword)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "current password is incorrect"})
+		return
+	}
+	if req.CurrentPassword == req.NewPassword {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "new password must differ from the current one"})
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password hashing failed"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if _, err := tx.Exec(c.Request.Context(), `UPDATE users SET password_hash = $1 WHERE id = $2`, string(hash), userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "password update failed"})
+		return
+	}
+	// Keep the caller signed in on this device; every other session ends.
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE user_sessions
+		SET revoked_at = NOW(), revoked_reason = 'password_changed'
+		WHERE user_id = $1 AND public_id <> $2 AND revoked_at IS NULL
+	`, userID, sessionFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke sessions"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "password updated"})
+}
@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// BucketRule describes a token bucket: Burst attempts up front, refilled at
+// Burst per Window.
+type BucketRule struct {
+	Burst  float64
+	Window time.Duration
+}
+
+func (r BucketRule) ratePerSecond() float64 {
+	return r.Burst / r.Window.Seconds()
+}
+
+var (
+	loginIPRule    = BucketRule{Burst: 20, Window: 10 * time.Minute}
+	loginEmailRule = BucketRule{Burst: 10, Window: 15 * time.Minute}
+	resetIPRule    = BucketRule{Burst: 10, Window: time.Hour}
+)
+
+// bucketIdleTTL is the longest rule window; a bucket untouched for that long is
+// full again and can be forgotten.
+const bucketIdleTTL = time.Hour
+
+// AttemptStore takes one token from the bucket at key. When the bucket is
+// empty it reports how long until the next token is available.
+type AttemptStore interface {
+	Take(ctx context.Context, key string, rule BucketRule, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// refillBucket applies elapsed-time refill and, if possible, takes a token.
+func refillBucket(tokens float64, last, now time.Time, rule BucketRule) (float64, bool, time.Duration) {
+	elapsed := now.Sub(last).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(rule.Burst, tokens+elapsed*rule.ratePerSecond())
+	}
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	wait := time.Duration((1 - tokens) / rule.ratePerSecond() * float64(time.Second))
+	return tokens, false, wait
+}
+
+type bucketState struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryAttemptStore keeps buckets in process memory. It is the default and is
+// enough for a single replica.
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	buckets map[string]bucketState
+	takes   int
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{buckets: make(map[string]bucketState)}
+}
+
+func (m *MemoryAttemptStore) Take(_ context.Context, key string, rule BucketRule, now time.Time) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.buckets[key]
+	if !ok {
+		state = bucketState{tokens: rule.Burst, last: now}
+	}
+	tokens, allowed, wait := refillBucket(state.tokens, state.last, now, rule)
+	m.buckets[key] = bucketState{tokens: tokens, last: now}
+
+	m.takes++
+	if m.takes%1000 == 0 {
+		for k, st := range m.buckets {
+			if now.Sub(st.last) > bucketIdleTTL {
+				delete(m.buckets, k)
+			}
+		}
+	}
+	return allowed, wait, nil
+}
+
+// PostgresAttemptStore shares buckets across replicas through the
+// rate_limit_buckets table.
+type PostgresAttemptStore struct {
+	DB    *pgxpool.Pool
+	takes atomic.Int64
+}
+
+func NewPostgresAttemptStore(db *pgxpool.Pool) *PostgresAttemptStore {
+	return &PostgresAttemptStore{DB: db}
+}
+
+func (p *PostgresAttemptStore) Take(ctx context.Context, key string, rule BucketRule, now time.Time) (bool, time.Duration, error) {
+	tx, err := p.DB.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO NOTHING
+	`, key, rule.Burst, now); err != nil {
+		return false, 0, err
+	}
+	var tokens float64
+	var last time.Time
+	if err := tx.QueryRow(ctx, `
+		SELECT tokens, updated_at
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE
+	`, key).Scan(&tokens, &last); err != nil {
+		return false, 0, err
+	}
+	tokens, allowed, wait := refillBucket(tokens, last, now, rule)
+	if _, err := tx.Exec(ctx, `
+		UPDATE rate_limit_buckets
+		SET tokens = $2, updated_at = $3
+		WHERE key = $1
+	`, key, tokens, now); err != nil {
+		return false, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, 0, err
+	}
+	if p.takes.Add(1)%1000 == 0 {
+		_, _ = p.DB.Exec(ctx, `DELETE FROM rate_limit_buckets WHERE updated_at < $1`, now.Add(-bucketIdleTTL))
+	}
+	return allowed, wait, nil
+}
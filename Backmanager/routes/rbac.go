@@ -0,0 +1,160 @@
+package routes
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant roles, most to least privileged. org_admin is the tenant "admin" role;
+// the identifier predates the wider role model and is kept for existing data.
+const (
+	RoleSystemAdmin = "system_admin"
+	RoleOwner       = "owner"
+	RoleAdmin       = "org_admin"
+	RoleManager     = "manager"
+	RoleMember      = "member"
+	RoleViewer      = "viewer"
+	RoleGuest       = "guest"
+)
+
+const (
+	PermUsersRead   = "users:read"
+	PermUsersManage = "users:manage"
+
+	PermSessionsManage = "sessions:manage"
+
+	PermProjectsRead   = "projects:read"
+	PermProjectsCreate = "projects:create"
+	PermProjectsUpdate = "projects:update"
+	PermProjectsDelete = "projects:delete"
+
+	PermTasksRead   = "tasks:read"
+	PermTasksCreate = "tasks:create"
+	PermTasksUpdate = "tasks:update"
+	PermTasksDelete = "tasks:delete"
+
+	PermTimesheetsRead   = "timesheets:read"
+	PermTimesheetsCreate = "timesheets:create"
+	// PermTimesheetsManage lets a user edit or delete entries logged by others.
+	PermTimesheetsManage = "timesheets:manage"
+
+	PermApprovalsRead   = "approvals:read"
+	PermApprovalsCreate = "approvals:create"
+	// PermApprovalsAct lets a user approve or reject requests routed to them.
+	PermApprovalsAct    = "approvals:act"
+	PermApprovalsDelete = "approvals:delete"
+
+	PermForumRead   = "forum:read"
+	PermForumCreate = "forum:create"
+	// PermForumModerate lets a user delete posts written by others.
+	PermForumModerate = "forum:moderate"
+
+	PermCodeSharesRead  = "codeshares:read"
+	PermCodeSharesWrite = "codeshares:write"
+
+	PermIssuesRead   = "issues:read"
+	PermIssuesCreate = "issues:create"
+
+	// PermWorkflowsManage covers task and project status workflows.
+	PermWorkflowsManage = "workflows:manage"
+	// PermBillingManage covers billing rates and billing reports.
+	PermBillingManage = "billing:manage"
+
+	// PermBrandingManage covers email branding and template overrides.
+	PermBrandingManage = "branding:manage"
+	// PermWebhooksManage covers webhook subscriptions and their delivery log.
+	PermWebhooksManage = "webhooks:manage"
+	PermAuditRead      = "audit:read"
+)
+
+var readPermissions = []string{
+	PermUsersRead, PermProjectsRead, PermTasksRead, PermTimesheetsRead, PermApprovalsRead,
+	PermForumRead, PermCodeSharesRead, PermIssuesRead,
+}
+
+var memberPermissions = append([]string{
+	PermTasksCreate, PermTasksUpdate, PermTimesheetsCreate, PermApprovalsCreate,
+	PermForumCreate, PermCodeSharesWrite, PermIssuesCreate,
+}, readPermissions...)
+
+var managerPermissions = append([]string{
+	PermProjectsCreate, PermProjectsUpdate, PermProjectsDelete, PermTasksDelete,
+	PermTimesheetsManage, PermApprovalsAct, PermApprovalsDelete, PermForumModerate, PermWorkflowsManage, PermBillingManage,
+}, memberPermissions...)
+
+var adminPermissions = append([]string{
+	PermUsersManage, PermSessionsManage, PermBrandingManage, PermWebhooksManage, PermAuditRead,
+}, managerPermissions...)
+
+var rolePermissions = map[string]map[string]struct{}{
+	RoleSystemAdmin: permissionSet(adminPermissions),
+	RoleOwner:       permissionSet(adminPermissions),
+	RoleAdmin:       permissionSet(adminPermissions),
+	RoleManager:     permissionSet(managerPermissions),
+	RoleMember:      permissionSet(memberPermissions),
+	RoleViewer:      permissionSet(readPermissions),
+	RoleGuest:       permissionSet([]string{PermProjectsRead, PermTasksRead}),
+}
+
+// roleRank orders tenant roles so admins can only manage roles below their own.
+var roleRank = map[string]int{
+	RoleGuest:       1,
+	RoleViewer:      2,
+	RoleMember:      3,
+	RoleManager:     4,
+	RoleAdmin:       5,
+	RoleOwner:       6,
+	RoleSystemAdmin: 7,
+}
+
+func permissionSet(perms []string) map[string]struct{} {
+	out := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		out[p] = struct{}{}
+	}
+	return out
+}
+
+func isValidTenantRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok && role != RoleSystemAdmin
+}
+
+// canAssignRole reports whether callerRole may move a user from fromRole (""
+// for someone not yet in the tenant) to toRole. Owners manage every tenant
+// role, their peers included; anyone else only manages roles strictly below
+// their own, so admins cannot demote other admins or create new ones.
+func canAssignRole(callerRole, fromRole, toRole string) bool {
+	caller := roleRank[callerRole]
+	if caller >= roleRank[RoleOwner] {
+		return roleRank[fromRole] <= caller && roleRank[toRole] <= caller
+	}
+	return roleRank[fromRole] < caller && roleRank[toRole] < caller
+}
+
+func roleHasPermission(role, perm string) bool {
+	perms, ok := rolePermissions[strings.TrimSpace(role)]
+	if !ok {
+		return false
+	}
+	_, ok = perms[perm]
+	return ok
+}
+
+func hasPermission(c *gin.Context, perm string) bool {
+	return roleHasPermission(roleFromContext(c), perm)
+}
+
+// RequirePermission rejects the request unless the caller's role grants perm.
+// It must run after RequireActiveUser, which loads the current role.
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasPermission(c, perm) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission denied", "permission": perm})
+			return
+		}
+		c.Next()
+	}
+}
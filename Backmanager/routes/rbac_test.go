@@ -0,0 +1,41 @@
+package routes
+
+import "testing"
+
+func TestCanAssignRole(t *testing.T) {
+	cases := []struct {
+		caller, from, to string
+		want             bool
+	}{
+		{RoleOwner, RoleAdmin, RoleMember, true},
+		{RoleOwner, RoleOwner, RoleAdmin, true},
+		{RoleOwner, RoleMember, RoleOwner, true},
+		{RoleOwner, "", RoleAdmin, true},
+		{RoleAdmin, RoleMember, RoleManager, true},
+		{RoleAdmin, RoleManager, RoleViewer, true},
+		{RoleAdmin, "", RoleManager, true},
+		{RoleAdmin, RoleAdmin, RoleMember, false},
+		{RoleAdmin, RoleMember, RoleAdmin, false},
+		{RoleAdmin, RoleOwner, RoleMember, false},
+		{RoleAdmin, "", RoleAdmin, false},
+		{RoleManager, RoleMember, RoleViewer, true},
+		{RoleManager, RoleManager, RoleMember, false},
+		{RoleSystemAdmin, RoleOwner, RoleAdmin, true},
+	}
+	for _, tc := range cases {
+		if got := canAssignRole(tc.caller, tc.from, tc.to); got != tc.want {
+			t.Errorf("canAssignRole(%q, %q, %q) = %v, want %v", tc.caller, tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestApprovalActionPermission(t *testing.T) {
+	for role, want := range map[string]bool{
+		RoleOwner: true, RoleAdmin: true, RoleManager: true,
+		RoleMember: false, RoleViewer: false, RoleGuest: false,
+	} {
+		if got := roleHasPermission(role, PermApprovalsAct); got != want {
+			t.Errorf("%s: approvals:act = %v, want %v", role, got, want)
+		}
+	}
+}
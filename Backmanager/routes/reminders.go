@@ -0,0 +1,325 @@
+package routes
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// digestClock is the local time daily digests go out; reminders use the
+// user's own reminder_time.
+const digestClock = "18:00"
+
+// reminderPrefs is one user_settings row as seen by the scheduled jobs.
+// email_summaries decides whether reminders and digests are emailed or only
+// posted in-app; overdue alerts are always emailed.
+type reminderPrefs struct {
+	SettingsID       int64
+	TenantID         int64
+	Email            string
+	Location         *time.Location
+	Frequency        string
+	Days             []string
+	ReminderTime     string
+	RemindersEnabled bool
+	DailyDigest      bool
+	OverdueAlerts    bool
+	EmailSummaries   bool
+	LastReminderAt   *time.Time
+	LastDigestAt     *time.Time
+}
+
+func (s *Service) loadReminderPrefs(ctx context.Context) ([]reminderPrefs, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT us.id, us.tenant_id, lower(us.user_email), us.timezone, us.reminder_frequency, us.reminder_days, us.reminder_time,
+		       us.reminders_enabled, us.daily_digest, us.overdue_alerts, us.email_summaries,
+		       us.last_reminder_sent_at, us.last_digest_sent_at
+		FROM user_settings us
+		JOIN users u ON u.tenant_id = us.tenant_id AND lower(u.email) = lower(us.user_email)
+		WHERE COALESCE(u.blocked, false) = false
+		  AND (us.reminders_enabled OR us.daily_digest OR us.overdue_alerts)
+		ORDER BY us.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]reminderPrefs, 0)
+	for rows.Next() {
+		var p reminderPrefs
+		var timezone string
+		var daysRaw []byte
+		if err := rows.Scan(&p.SettingsID, &p.TenantID, &p.Email, &timezone, &p.Frequency, &daysRaw, &p.ReminderTime,
+			&p.RemindersEnabled, &p.DailyDigest, &p.OverdueAlerts, &p.EmailSummaries, &p.LastReminderAt, &p.LastDigestAt); err != nil {
+			return nil, err
+		}
+		p.Days = parseStringArrayJSON(daysRaw)
+		p.Location = loadLocationOrUTC(timezone)
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func loadLocationOrUTC(name string) *time.Location {
+	if tz := strings.TrimSpace(name); tz != "" {
+		if loaded, err := time.LoadLocation(tz); err == nil {
+			return loaded
+		}
+	}
+	return time.UTC
+}
+
+// dueToday reports whether a once-a-day send at clock (HH:MM, local) is due at
+// now and has not already happened today.
+func dueToday(now time.Time, loc *time.Location, clock string, last *time.Time) bool {
+	now = now.In(loc)
+	target, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		target, _ = time.Parse("15:04", "09:00")
+	}
+	if now.Before(time.Date(now.Year(), now.Month(), now.Day(), target.Hour(), target.Minute(), 0, 0, loc)) {
+		return false
+	}
+	if last != nil {
+		l := last.In(loc)
+		if l.Year() == now.Year() && l.Month() == now.Month() && l.Day() == now.Day() {
+			return false
+		}
+	}
+	return true
+}
+
+// reminderDue applies frequency and reminder_days on top of dueToday.
+// "weekly" and "custom" both send on the listed days.
+func reminderDue(now time.Time, p reminderPrefs) bool {
+	if !p.RemindersEnabled || !dueToday(now, p.Location, p.ReminderTime, p.LastReminderAt) {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(p.Frequency)) {
+	case "weekly", "custom":
+		today := strings.ToLower(now.In(p.Location).Weekday().String())
+		for _, d := range p.Days {
+			if strings.ToLower(strings.TrimSpace(d)) == today {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// claimSend stamps column on the settings row if it still holds the value we
+// evaluated, so a job re-run after a crash cannot send twice.
+func (s *Service) claimSend(ctx context.Context, settingsID int64, column string, last *time.Time) bool {
+	var query string
+	switch column {
+	case "last_reminder_sent_at":
+		query = `UPDATE user_settings SET last_reminder_sent_at = NOW() WHERE id = $1 AND last_reminder_sent_at IS NOT DISTINCT FROM $2`
+	case "last_digest_sent_at":
+		query = `UPDATE user_settings SET last_digest_sent_at = NOW() WHERE id = $1 AND last_digest_sent_at IS NOT DISTINCT FROM $2`
+	default:
+		return false
+	}
+	tag, err := s.DB.Exec(ctx, query, settingsID, last)
+	return err == nil && tag.RowsAffected() == 1
+}
+
+func (s *Service) runReminderJob(ctx context.Context, now time.Time) (int, error) {
+	prefs, err := s.loadReminderPrefs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, p := range prefs {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		if !reminderDue(now, p) || !s.claimSend(ctx, p.SettingsID, "last_reminder_sent_at", p.LastReminderAt) {
+			continue
+		}
+		summary, err := s.loadReminderSummary(ctx, p.TenantID, p.Email)
+		if err != nil {
+			continue
+		}
+		detail := "A scheduled summary reminder is ready."
+		if p.EmailSummaries {
+			if err := s.enqueueTemplate(ctx, "reminder", p.TenantID, p.Email, "reminder_summary", map[string]any{
+				"PendingProjects":         summary.PendingProjects,
+				"AssignedPendingProjects": summary.AssignedPendingProjects,
+				"OverdueProjects":         summary.OverdueProjects,
+				"OpenTasks":               summary.OpenTasks,
+				"OverdueTasks":            summary.OverdueTasks,
+			}); err == nil {
+				detail = "A scheduled summary reminder has been sent to your email."
+			}
+		}
+		_ = s.createInAppNotification(ctx, p.TenantID, []string{p.Email}, "summary", "Scheduled reminder", detail, map[string]any{
+			"pending_projects":          summary.PendingProjects,
+			"assigned_pending_projects": summary.AssignedPendingProjects,
+			"overdue_projects":          summary.OverdueProjects,
+			"open_tasks":                summary.OpenTasks,
+			"overdue_tasks":             summary.OverdueTasks,
+		})
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *Service) runDigestJob(ctx context.Context, now time.Time) (int, error) {
+	prefs, err := s.loadReminderPrefs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, p := range prefs {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		if !p.DailyDigest || !dueToday(now, p.Location, digestClock, p.LastDigestAt) {
+			continue
+		}
+		if !s.claimSend(ctx, p.SettingsID, "last_digest_sent_at", p.LastDigestAt) {
+			continue
+		}
+		summary, err := s.loadReminderSummary(ctx, p.TenantID, p.Email)
+		if err != nil {
+			continue
+		}
+		titles, unread, err := s.recentNotificationTitles(ctx, p.TenantID, p.Email, now.Add(-24*time.Hour), 10)
+		if err != nil {
+			continue
+		}
+		if p.EmailSummaries {
+			_ = s.enqueueTemplate(ctx, "digest", p.TenantID, p.Email, "daily_digest", map[string]any{
+				"Date":                    now.In(p.Location).Format("2006-01-02"),
+				"PendingProjects":         summary.PendingProjects,
+				"AssignedPendingProjects": summary.AssignedPendingProjects,
+				"OverdueProjects":         summary.OverdueProjects,
+				"OpenTasks":               summary.OpenTasks,
+				"OverdueTasks":            summary.OverdueTasks,
+				"UnreadCount":             unread,
+				"Notifications":           titles,
+			})
+		}
+		_ = s.createInAppNotification(ctx, p.TenantID, []string{p.Email}, "summary", "Daily digest", "Your daily digest is ready.", map[string]any{
+			"unread":           unread,
+			"overdue_projects": summary.OverdueProjects,
+			"open_tasks":       summary.OpenTasks,
+			"overdue_tasks":    summary.OverdueTasks,
+		})
+		sent++
+	}
+	return sent, nil
+}
+
+func (s *Service) recentNotificationTitles(ctx context.Context, tenantID int64, email string, since time.Time, limit int) ([]string, int64, error) {
+	var unread int64
+	if err := s.DB.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2) AND read_at IS NULL AND archived_at IS NULL
+	`, tenantID, email).Scan(&unread); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.DB.Query(ctx, `
+		SELECT title
+		FROM notifications
+		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2) AND created_at >= $3
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, tenantID, email, since, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	titles := make([]string, 0, limit)
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, 0, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, unread, rows.Err()
+}
+
+// runOverdueAlertJob alerts each assignee once per project and due date. The
+// overdue_alert_log insert is the dedupe, so moving a due date re-arms it.
+func (s *Service) runOverdueAlertJob(ctx context.Context, now time.Time) (int, error) {
+	prefs, err := s.loadReminderPrefs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, p := range prefs {
+		if ctx.Err() != nil {
+			return sent, ctx.Err()
+		}
+		if !p.OverdueAlerts {
+			continue
+		}
+		localToday := now.In(p.Location).Format("2006-01-02")
+		rows, err := s.DB.Query(ctx, `
+			WITH overdue AS (
+				SELECT p.id, p.name, p.due_date
+				FROM projects p
+				WHERE p.tenant_id = $1
+				  AND p.completed_at IS NULL
+				  AND p.due_date IS NOT NULL
+				  AND p.due_date < $3::date
+				  AND EXISTS (
+					SELECT 1
+					FROM jsonb_array_elements_text(COALESCE(p.assignees, '[]'::jsonb)) AS a(value)
+					WHERE lower(trim(a.value)) = lower($2)
+				  )
+			), fresh AS (
+				INSERT INTO overdue_alert_log (tenant_id, project_id, user_email, due_date)
+				SELECT $1, o.id, lower($2), o.due_date FROM overdue o
+				ON CONFLICT DO NOTHING
+				RETURNING project_id
+			)
+			SELECT o.id, o.name, o.due_date
+			FROM overdue o
+			JOIN fresh f ON f.project_id = o.id
+		`, p.TenantID, p.Email, localToday)
+		if err != nil {
+			return sent, err
+		}
+		type overdueProject struct {
+			id   int64
+			name string
+			due  time.Time
+		}
+		projects := make([]overdueProject, 0)
+		for rows.Next() {
+			var op overdueProject
+			if err := rows.Scan(&op.id, &op.name, &op.due); err != nil {
+				rows.Close()
+				return sent, err
+			}
+			projects = append(projects, op)
+		}
+		rows.Close()
+
+		today, _ := time.Parse("2006-01-02", localToday)
+		for _, op := range projects {
+			openTasks, _ := s.countOpenProjectTasks(ctx, p.TenantID, op.id)
+			daysOverdue := int(today.Sub(op.due).Hours() / 24)
+			_ = s.enqueueTemplate(ctx, "overdue", p.TenantID, p.Email, "overdue_alert", map[string]any{
+				"ProjectName": op.name,
+				"DueDate":     op.due.Format("2006-01-02"),
+				"DaysOverdue": daysOverdue,
+				"OpenTasks":   openTasks,
+			})
+			_ = s.createInAppNotification(ctx, p.TenantID, []string{p.Email}, "project", "Project overdue: "+op.name, "This project passed its due date and is still open.", map[string]any{
+				"project_id":   op.id,
+				"project_name": op.name,
+				"due_date":     op.due.Format("2006-01-02"),
+			})
+			sent++
+		}
+	}
+	return sent, nil
+}
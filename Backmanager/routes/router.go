@@ -1,9 +1,13 @@
 package routes
 
 import (
+	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"backmanager/mailer"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,10 +16,25 @@ type Service struct {
 	JWTSecret         []byte
 	JWTIssuer         string
 	JWTTTL            time.Duration
+	RefreshTTL        time.Duration
 	SystemAdminEmails map[string]struct{}
+	// Attempts backs login and reset rate limiting. NewService defaults to an
+	// in-memory store; set a PostgresAttemptStore when running several replicas.
+	Attempts AttemptStore
+	Mailer   mailer.Mailer
+	// TimerIdleLimit is how long a running timer may go without activity
+	// before it is stopped automatically.
+	TimerIdleLimit time.Duration
+
+	outboxWake      chan struct{}
+	schedulerLeader atomic.Bool
+	notifyHub       *notificationHub
+	webhookWake     chan struct{}
+	eventWake       chan struct{}
+	webhookClient   *http.Client
 }
 
-func NewService(db *pgxpool.Pool, jwtSecret []byte, jwtIssuer string, jwtTTL time.Duration, systemAdminEmails []string) *Service {
+func NewService(db *pgxpool.Pool, jwtSecret []byte, jwtIssuer string, jwtTTL, refreshTTL time.Duration, systemAdminEmails []string) *Service {
 	systemAdmins := make(map[string]struct{}, len(systemAdminEmails))
 	for _, email := range systemAdminEmails {
 		e := strings.ToLower(strings.TrimSpace(email))
@@ -29,14 +48,22 @@ func NewService(db *pgxpool.Pool, jwtSecret []byte, jwtIssuer string, jwtTTL tim
 		JWTSecret:         jwtSecret,
 		JWTIssuer:         jwtIssuer,
 		JWTTTL:            jwtTTL,
+		RefreshTTL:        refreshTTL,
 		SystemAdminEmails: systemAdmins,
+		Attempts:          NewMemoryAttemptStore(),
+		TimerIdleLimit:    defaultTimerIdleLimit,
+		outboxWake:        make(chan struct{}, 1),
+		notifyHub:         newNotificationHub(),
+		webhookWake:       make(chan struct{}, 1),
+		eventWake:         make(chan struct{}, 1),
+		webhookClient:     newWebhookClient(false),
 	}
 }
 
 func (s *Service) roleForEmail(email string) string {
 	_, ok := s.SystemAdminEmails[strings.ToLower(strings.TrimSpace(email))]
 	if ok {
-		return "system_admin"
+		return RoleSystemAdmin
 	}
-	return "org_admin"
+	return RoleMember
 }
@@ -0,0 +1,228 @@
+package routes
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hoursPerDay converts estimated hours into schedule days for tasks without
+// both a start and a due date.
+const hoursPerDay = 8
+
+var errDependencyCycle = errors.New("task dependencies contain a cycle")
+
+type scheduledTask struct {
+	TaskID         int64      `json:"task_id"`
+	Title          string     `json:"title"`
+	Status         string     `json:"status"`
+	DurationDays   int        `json:"duration_days"`
+	EarliestStart  int        `json:"earliest_start"`
+	EarliestFinish int        `json:"earliest_finish"`
+	LatestStart    int        `json:"latest_start"`
+	LatestFinish   int        `json:"latest_finish"`
+	SlackDays      int        `json:"slack_days"`
+	Critical       bool       `json:"critical"`
+	StartDate      *time.Time `json:"start_date,omitempty"`
+	FinishDate     *time.Time `json:"finish_date,omitempty"`
+}
+
+type projectSchedule struct {
+	ProjectID      int64            `json:"project_id"`
+	StartDate      *time.Time       `json:"start_date,omitempty"`
+	DueDate        *time.Time       `json:"due_date,omitempty"`
+	FinishDate     *time.Time       `json:"finish_date,omitempty"`
+	DurationDays   int              `json:"duration_days"`
+	ExceedsDueDate bool             `json:"exceeds_due_date"`
+	OverrunDays    int              `json:"overrun_days"`
+	CriticalPath   []int64          `json:"critical_path"`
+	Tasks          []scheduledTask  `json:"tasks"`
+	Dependencies   []taskDependency `json:"dependencies"`
+}
+
+// taskDurationDays is the due-start span when both dates are set, otherwise
+// the estimate at hoursPerDay, otherwise one day.
+func taskDurationDays(t Task) int {
+	if t.StartDate != nil && t.DueDate != nil {
+		return int(t.DueDate.Sub(*t.StartDate).Hours()/24) + 1
+	}
+	if t.EstimatedHours != nil && *t.EstimatedHours > 0 {
+		return int(math.Ceil(*t.EstimatedHours / hoursPerDay))
+	}
+	return 1
+}
+
+// computeSchedule runs the critical path method over tasks. Offsets are whole
+// days from projectStart; a task's own start_date acts as a start-no-earlier-
+// than constraint. The result's dates are left nil when projectStart is nil.
+func computeSchedule(projectStart *time.Time, tasks []Task, deps []taskDependency) ([]scheduledTask, []int64, int, error) {
+	index := make(map[int64]int, len(tasks))
+	out := make([]scheduledTask, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+		out[i] = scheduledTask{TaskID: t.ID, Title: t.Title, Status: t.Status, DurationDays: taskDurationDays(t)}
+	}
+
+	preds := make([][]taskDependency, len(tasks))
+	succs := make([][]taskDependency, len(tasks))
+	indegree := make([]int, len(tasks))
+	for _, d := range deps {
+		p, okP := index[d.PredecessorID]
+		s, okS := index[d.SuccessorID]
+		if !okP || !okS {
+			continue
+		}
+		preds[s] = append(preds[s], d)
+		succs[p] = append(succs[p], d)
+		indegree[s]++
+	}
+
+	order := make([]int, 0, len(tasks))
+	for i := range tasks {
+		if indegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for k := 0; k < len(order); k++ {
+		for _, d := range succs[order[k]] {
+			s := index[d.SuccessorID]
+			indegree[s]--
+			if indegree[s] == 0 {
+				order = append(order, s)
+			}
+		}
+	}
+	if len(order) != len(tasks) {
+		return nil, nil, 0, errDependencyCycle
+	}
+
+	finish := 0
+	for _, i := range order {
+		es := 0
+		if projectStart != nil && tasks[i].StartDate != nil {
+			es = max(es, int(tasks[i].StartDate.Sub(*projectStart).Hours()/24))
+		}
+		for _, d := range preds[i] {
+			p := out[index[d.PredecessorID]]
+			if d.Type == dependencyStartToStart {
+				es = max(es, p.EarliestStart+d.LagDays)
+			} else {
+				es = max(es, p.EarliestFinish+d.LagDays)
+			}
+		}
+		out[i].EarliestStart = es
+		out[i].EarliestFinish = es + out[i].DurationDays
+		finish = max(finish, out[i].EarliestFinish)
+	}
+
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		lf := finish
+		for _, d := range succs[i] {
+			s := out[index[d.SuccessorID]]
+			if d.Type == dependencyStartToStart {
+				lf = min(lf, s.LatestStart-d.LagDays+out[i].DurationDays)
+			} else {
+				lf = min(lf, s.LatestStart-d.LagDays)
+			}
+		}
+		out[i].LatestFinish = lf
+		out[i].LatestStart = lf - out[i].DurationDays
+		out[i].SlackDays = out[i].LatestStart - out[i].EarliestStart
+		out[i].Critical = out[i].SlackDays <= 0
+	}
+
+	if projectStart != nil {
+		for i := range out {
+			start := projectStart.AddDate(0, 0, out[i].EarliestStart)
+			end := projectStart.AddDate(0, 0, out[i].EarliestFinish-1)
+			out[i].StartDate, out[i].FinishDate = &start, &end
+		}
+	}
+
+	critical := make([]int64, 0)
+	for _, i := range order {
+		if out[i].Critical {
+			critical = append(critical, out[i].TaskID)
+		}
+	}
+	sort.SliceStable(critical, func(a, b int) bool {
+		return out[index[critical[a]]].EarliestStart < out[index[critical[b]]].EarliestStart
+	})
+	return out, critical, finish, nil
+}
+
+// ProjectSchedule returns earliest/latest start, slack and the critical path
+// for the project's tasks. Offsets in the response are days from the project
+// start date.
+func (s *Service) ProjectSchedule(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	projectID, ok := projectIDParam(c)
+	if !ok {
+		return
+	}
+
+	var schedule projectSchedule
+	schedule.ProjectID = projectID
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT start_date, due_date
+		FROM projects
+		WHERE id = $1 AND tenant_id = $2
+	`, projectID, tenantID).Scan(&schedule.StartDate, &schedule.DueDate); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	}
+
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT `+taskColumns+`
+		FROM tasks
+		WHERE tenant_id = $1 AND project_id = $2
+		ORDER BY id
+	`, tenantID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+	tasks := make([]Task, 0)
+	for rows.Next() {
+		var item Task
+		if err := scanTask(rows, &item); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		tasks = append(tasks, item)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+		return
+	}
+
+	deps, err := loadDependencies(c.Request.Context(), s.DB, tenantID, projectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	scheduled, critical, finish, err := computeSchedule(schedule.StartDate, tasks, deps)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	schedule.Tasks = scheduled
+	schedule.CriticalPath = critical
+	schedule.Dependencies = deps
+	schedule.DurationDays = finish
+	if schedule.StartDate != nil && finish > 0 {
+		end := schedule.StartDate.AddDate(0, 0, finish-1)
+		schedule.FinishDate = &end
+		if schedule.DueDate != nil && end.After(*schedule.DueDate) {
+			schedule.ExceedsDueDate = true
+			schedule.OverrunDays = int(end.Sub(*schedule.DueDate).Hours() / 24)
+		}
+	}
+	c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func scheduleTask(id int64, days int) Task {
+	hours := float64(days * hoursPerDay)
+	return Task{ID: id, EstimatedHours: &hours}
+}
+
+func dependency(pred, succ int64, kind string, lag int) taskDependency {
+	return taskDependency{PredecessorID: pred, SuccessorID: succ, Type: kind, LagDays: lag}
+}
+
+// scheduleRow is earliest start, earliest finish, latest start, latest finish
+// and slack, in days.
+type scheduleRow [5]int
+
+func TestComputeSchedule(t *testing.T) {
+	cases := []struct {
+		name     string
+		tasks    []Task
+		deps     []taskDependency
+		want     map[int64]scheduleRow
+		critical []int64
+		finish   int
+	}{
+		{
+			name:     "finish to start with lag",
+			tasks:    []Task{scheduleTask(1, 2), scheduleTask(2, 3)},
+			deps:     []taskDependency{dependency(1, 2, dependencyFinishToStart, 2)},
+			want:     map[int64]scheduleRow{1: {0, 2, 0, 2, 0}, 2: {4, 7, 4, 7, 0}},
+			critical: []int64{1, 2},
+			finish:   7,
+		},
+		{
+			name:     "finish to start with lead",
+			tasks:    []Task{scheduleTask(1, 4), scheduleTask(2, 2)},
+			deps:     []taskDependency{dependency(1, 2, dependencyFinishToStart, -2)},
+			want:     map[int64]scheduleRow{1: {0, 4, 0, 4, 0}, 2: {2, 4, 2, 4, 0}},
+			critical: []int64{1, 2},
+			finish:   4,
+		},
+		{
+			name:     "start to start with lag",
+			tasks:    []Task{scheduleTask(1, 5), scheduleTask(2, 2)},
+			deps:     []taskDependency{dependency(1, 2, dependencyStartToStart, 1)},
+			want:     map[int64]scheduleRow{1: {0, 5, 0, 5, 0}, 2: {1, 3, 3, 5, 2}},
+			critical: []int64{1},
+			finish:   5,
+		},
+		{
+			name:     "lead before the project start is clamped",
+			tasks:    []Task{scheduleTask(1, 3), scheduleTask(2, 3)},
+			deps:     []taskDependency{dependency(1, 2, dependencyStartToStart, -2)},
+			want:     map[int64]scheduleRow{1: {0, 3, 0, 3, 0}, 2: {0, 3, 0, 3, 0}},
+			critical: []int64{1, 2},
+			finish:   3,
+		},
+		{
+			// 1 feeds 2 (2 days) and 3 (4 days), which both feed 4. Tasks are
+			// listed out of order on purpose.
+			name:  "diamond",
+			tasks: []Task{scheduleTask(4, 1), scheduleTask(2, 2), scheduleTask(1, 1), scheduleTask(3, 4)},
+			deps: []taskDependency{
+				dependency(1, 2, dependencyFinishToStart, 0),
+				dependency(1, 3, dependencyFinishToStart, 0),
+				dependency(2, 4, dependencyFinishToStart, 0),
+				dependency(3, 4, dependencyFinishToStart, 0),
+			},
+			want:     map[int64]scheduleRow{1: {0, 1, 0, 1, 0}, 2: {1, 3, 3, 5, 2}, 3: {1, 5, 1, 5, 0}, 4: {5, 6, 5, 6, 0}},
+			critical: []int64{1, 3, 4},
+			finish:   6,
+		},
+		{
+			// The short branch gets a lag long enough to become the critical one.
+			name:  "diamond with a lagged branch",
+			tasks: []Task{scheduleTask(1, 1), scheduleTask(2, 2), scheduleTask(3, 4), scheduleTask(4, 1)},
+			deps: []taskDependency{
+				dependency(1, 2, dependencyFinishToStart, 3),
+				dependency(1, 3, dependencyFinishToStart, 0),
+				dependency(2, 4, dependencyFinishToStart, 0),
+				dependency(3, 4, dependencyStartToStart, 2),
+			},
+			want:     map[int64]scheduleRow{1: {0, 1, 0, 1, 0}, 2: {4, 6, 4, 6, 0}, 3: {1, 5, 3, 7, 2}, 4: {6, 7, 6, 7, 0}},
+			critical: []int64{1, 2, 4},
+			finish:   7,
+		},
+		{
+			name:     "dependencies on tasks outside the project are ignored",
+			tasks:    []Task{scheduleTask(1, 2)},
+			deps:     []taskDependency{dependency(9, 1, dependencyFinishToStart, 5)},
+			want:     map[int64]scheduleRow{1: {0, 2, 0, 2, 0}},
+			critical: []int64{1},
+			finish:   2,
+		},
+	}
+	for _, tc := range cases {
+		out, critical, finish, err := computeSchedule(nil, tc.tasks, tc.deps)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		for _, st := range out {
+			got := scheduleRow{st.EarliestStart, st.EarliestFinish, st.LatestStart, st.LatestFinish, st.SlackDays}
+			if got != tc.want[st.TaskID] {
+				t.Errorf("%s: task %d = %v, want %v", tc.name, st.TaskID, got, tc.want[st.TaskID])
+			}
+			if st.Critical != (st.SlackDays == 0) {
+				t.Errorf("%s: task %d critical = %v with %d days of slack", tc.name, st.TaskID, st.Critical, st.SlackDays)
+			}
+			if st.StartDate != nil || st.FinishDate != nil {
+				t.Errorf("%s: task %d has dates without a project start", tc.name, st.TaskID)
+			}
+		}
+		if !slices.Equal(critical, tc.critical) || finish != tc.finish {
+			t.Errorf("%s: critical path %v finishing on day %d, want %v on day %d", tc.name, critical, finish, tc.critical, tc.finish)
+		}
+	}
+}
+
+func TestComputeScheduleDates(t *testing.T) {
+	projectStart := mustDate("2026-03-02")
+	// Task 1 may not start before March 4; task 2 starts a day before it.
+	first := scheduleTask(1, 3)
+	first.StartDate = timePtr(mustDate("2026-03-04"))
+	second := scheduleTask(2, 2)
+	out, critical, finish, err := computeSchedule(&projectStart, []Task{first, second}, []taskDependency{dependency(1, 2, dependencyStartToStart, -1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int64][2]string{1: {"2026-03-04", "2026-03-06"}, 2: {"2026-03-03", "2026-03-04"}}
+	for _, st := range out {
+		got := [2]string{st.StartDate.Format("2006-01-02"), st.FinishDate.Format("2006-01-02")}
+		if got != want[st.TaskID] {
+			t.Errorf("task %d runs %v, want %v", st.TaskID, got, want[st.TaskID])
+		}
+	}
+	if !slices.Equal(critical, []int64{1}) || finish != 5 {
+		t.Errorf("critical path %v finishing on day %d", critical, finish)
+	}
+}
+
+func TestComputeScheduleRejectsCycles(t *testing.T) {
+	tasks := []Task{scheduleTask(1, 1), scheduleTask(2, 1), scheduleTask(3, 1)}
+	deps := []taskDependency{
+		dependency(1, 2, dependencyFinishToStart, 0),
+		dependency(2, 3, dependencyFinishToStart, 0),
+		dependency(3, 1, dependencyStartToStart, 0),
+	}
+	if _, _, _, err := computeSchedule(nil, tasks, deps); !errors.Is(err, errDependencyCycle) {
+		t.Fatalf("err = %v, want errDependencyCycle", err)
+	}
+}
+
+func TestTaskDurationDays(t *testing.T) {
+	withDates := Task{StartDate: timePtr(mustDate("2026-03-02")), DueDate: timePtr(mustDate("2026-03-06"))}
+	partDay := 9.0
+	cases := []struct {
+		name string
+		task Task
+		want int
+	}{
+		{"start and due dates", withDates, 5},
+		{"estimate rounds up", Task{EstimatedHours: &partDay}, 2},
+		{"no estimate", Task{}, 1},
+	}
+	for _, tc := range cases {
+		if got := taskDurationDays(tc.task); got != tc.want {
+			t.Errorf("%s: %d days, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// schedulerLockKey is the pg advisory lock that elects the replica running
+// background jobs. Any constant works as long as every replica agrees.
+const schedulerLockKey int64 = 0x50464a4f4253
+
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	// run returns how many items (users, projects) it acted on.
+	run func(ctx context.Context, now time.Time) (int, error)
+}
+
+type jobState struct {
+	Name           string     `json:"name"`
+	IntervalSecond int64      `json:"interval_seconds"`
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastFinishedAt *time.Time `json:"last_finished_at,omitempty"`
+	LastStatus     string     `json:"last_status"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastItems      int        `json:"last_items"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	NextRunAt      time.Time  `json:"next_run_at"`
+	RunCount       int64      `json:"run_count"`
+	FailureCount   int64      `json:"failure_count"`
+	RanBy          string     `json:"ran_by,omitempty"`
+}
+
+func (s *Service) scheduledJobs() []scheduledJob {
+	return []scheduledJob{
+		{name: "reminders", interval: time.Minute, run: s.runReminderJob},
+		{name: "daily_digest", interval: 5 * time.Minute, run: s.runDigestJob},
+		{name: "overdue_alerts", interval: 15 * time.Minute, run: s.runOverdueAlertJob},
+		{name: "stale_timers", interval: 5 * time.Minute, run: s.runStaleTimerJob},
+		{name: "email_outbox_purge", interval: time.Hour, run: s.runOutboxPurgeJob},
+	}
+}
+
+func schedulerInstanceID() string {
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// RunScheduler competes for the scheduler advisory lock and, while holding it,
+// runs due jobs every tick until ctx is cancelled. The lock lives on a
+// dedicated connection taken out of the pool, so it is released when this
+// process exits or the connection drops and another replica takes over.
+func (s *Service) RunScheduler(ctx context.Context, tick time.Duration) {
+	instance := schedulerInstanceID()
+	for {
+		if err := s.leadScheduler(ctx, instance, tick); err != nil && ctx.Err() == nil {
+			log.Printf("scheduler: %v", err)
+		}
+		s.schedulerLeader.Store(false)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tick):
+		}
+	}
+}
+
+// leadScheduler returns nil without doing anything when another replica holds
+// the lock.
+func (s *Service) leadScheduler(ctx context.Context, instance string, tick time.Duration) error {
+	pooled, err := s.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, schedulerLockKey).Scan(&acquired); err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	s.schedulerLeader.Store(true)
+	log.Printf("scheduler: %s is leader", instance)
+
+	jobs := s.scheduledJobs()
+	if err := s.registerJobs(ctx, jobs); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		s.runDueJobs(ctx, jobs, instance)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		// A dead lock connection means the lock is gone too; step down.
+		if err := conn.Ping(ctx); err != nil {
+			return fmt.Errorf("lost leader connection: %w", err)
+		}
+	}
+}
+
+func (s *Service) registerJobs(ctx context.Context, jobs []scheduledJob) error {
+	for _, job := range jobs {
+		if _, err := s.DB.Exec(ctx, `
+			INSERT INTO scheduled_jobs (name, interval_seconds, next_run_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (name) DO UPDATE SET interval_seconds = EXCLUDED.interval_seconds
+		`, job.name, int64(job.interval.Seconds())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) runDueJobs(ctx context.Context, jobs []scheduledJob, instance string) {
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		var due bool
+		if err := s.DB.QueryRow(ctx, `
+			UPDATE scheduled_jobs
+			SET last_started_at = NOW(), last_status = 'running', ran_by = $2
+			WHERE name = $1 AND next_run_at <= NOW()
+			RETURNING true
+		`, job.name, instance).Scan(&due); err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) && ctx.Err() == nil {
+				log.Printf("scheduler: %s: %v", job.name, err)
+			}
+			continue
+		}
+
+		started := time.Now()
+		items, runErr := s.runJob(ctx, job, started)
+		status, lastError := "ok", ""
+		if runErr != nil {
+			status, lastError = "error", runErr.Error()
+			log.Printf("scheduler: %s failed: %v", job.name, runErr)
+		}
+		_, _ = s.DB.Exec(context.Background(), `
+			UPDATE scheduled_jobs
+			SET last_finished_at = NOW(),
+				last_status = $2,
+				last_error = NULLIF($3, ''),
+				last_items = $4,
+				last_duration_ms = $5,
+				next_run_at = $6,
+				run_count = run_count + 1,
+				failure_count = failure_count + CASE WHEN $2 = 'error' THEN 1 ELSE 0 END
+			WHERE name = $1
+		`, job.name, status, lastError, items, time.Since(started).Milliseconds(), started.Add(job.interval))
+	}
+}
+
+// runJob converts a panic in a job into an error so one bad job cannot take
+// the scheduler down.
+func (s *Service) runJob(ctx context.Context, job scheduledJob, now time.Time) (items int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.run(ctx, now)
+}
+
+func (s *Service) SystemJobs(c *gin.Context) {
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT name, interval_seconds, last_started_at, last_finished_at, COALESCE(last_status, 'never'), COALESCE(last_error, ''),
+		       last_items, last_duration_ms, next_run_at, run_count, failure_count, COALESCE(ran_by, '')
+		FROM scheduled_jobs
+		ORDER BY name
+	`)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]jobState, 0)
+	for rows.Next() {
+		var item jobState
+		if err := rows.Scan(&item.Name, &item.IntervalSecond, &item.LastStartedAt, &item.LastFinishedAt, &item.LastStatus, &item.LastError,
+			&item.LastItems, &item.LastDurationMS, &item.NextRunAt, &item.RunCount, &item.FailureCount, &item.RanBy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+		return
+	}
+
+	// Any replica can answer, so the leader comes from the database: whether
+	// some session holds the scheduler lock, and which instance last started
+	// a job under it.
+	var leaderActive bool
+	var leader string
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT
+			EXISTS (
+				SELECT 1 FROM pg_locks
+				WHERE locktype = 'advisory' AND granted AND objsubid = 1
+				  AND classid = ($1::bigint >> 32)::oid AND objid = ($1::bigint & 4294967295)::oid
+			),
+			COALESCE((SELECT ran_by FROM scheduled_jobs WHERE ran_by IS NOT NULL ORDER BY last_started_at DESC NULLS LAST LIMIT 1), '')
+	`, schedulerLockKey).Scan(&leaderActive, &leader); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if !leaderActive {
+		leader = ""
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"items":         items,
+		"leader":        leader,
+		"leader_active": leaderActive,
+		"instance":      schedulerInstanceID(),
+		"is_leader":     s.schedulerLeader.Load(),
+	})
+}
+
+// RunJobNow makes a job due immediately; the leader picks it up on its next
+// tick.
+func (s *Service) RunJobNow(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	tag, err := s.DB.Exec(c.Request.Context(), `UPDATE scheduled_jobs SET next_run_at = NOW() WHERE name = $1`, name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to schedule job"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "scheduled"})
+}
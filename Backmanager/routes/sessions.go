@@ -1,6 +1,12 @@
 package routes
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"net/http"
 	"strings"
 	"time"
@@ -9,44 +15,54 @@ import (
 )
 
 type sessionItem struct {
+	ID          string     `json:"id"`
 	UserID      string     `json:"user_id"`
 	Name        string     `json:"name"`
 	Email       string     `json:"email"`
 	Role        string     `json:"role"`
 	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+	ExpiresAt   time.Time  `json:"expires_at"`
 	Blocked     bool       `json:"blocked"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
 	DeviceLabel string     `json:"device_label"`
 	IP          string     `json:"ip"`
+	Current     bool       `json:"current"`
 }
 
 type sessionActionRequest struct {
-	Action string `json:"action"`
+	Action    string `json:"action"`
+	SessionID string `json:"session_id"`
+}
+
+type refreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 func (s *Service) ListSessions(c *gin.Context) {
-	role := strings.TrimSpace(roleFromContext(c))
-	if role != "org_admin" && role != "system_admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-		return
-	}
-	tenantSlug := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
+	currentSession := sessionFromContext(c)
 	rows, err := s.DB.Query(c.Request.Context(), `
 		SELECT
+			us.public_id,
 			COALESCE(u.public_id, ''),
 			u.name,
 			lower(u.email),
-			COALESCE(u.role, 'org_admin'),
-			u.last_login_at,
+			COALESCE(u.role, 'member'),
+			us.created_at,
+			us.last_seen_at,
+			us.expires_at,
 			COALESCE(u.blocked, false),
-			COALESCE(u.last_login_user_agent, ''),
-			COALESCE(u.last_login_ip, '')
-		FROM users u
-		JOIN tenants t ON t.id = u.tenant_id
-		WHERE t.slug = $1
-		  AND u.last_login_at IS NOT NULL
-		  AND u.last_login_at >= NOW() - INTERVAL '24 hours'
-		ORDER BY u.last_login_at DESC NULLS LAST, u.created_at DESC
-	`, tenantSlug)
+			CASE WHEN u.locked_until > NOW() THEN u.locked_until END,
+			us.user_agent,
+			us.ip
+		FROM user_sessions us
+		JOIN users u ON u.id = us.user_id
+		WHERE us.tenant_id = $1
+		  AND us.revoked_at IS NULL
+		  AND us.expires_at > NOW()
+		ORDER BY us.last_seen_at DESC, us.id DESC
+	`, tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
 		return
@@ -57,11 +73,12 @@ func (s *Service) ListSessions(c *gin.Context) {
 	for rows.Next() {
 		var item sessionItem
 		var userAgent string
-		if err := rows.Scan(&item.UserID, &item.Name, &item.Email, &item.Role, &item.LastLoginAt, &item.Blocked, &userAgent, &item.IP); err != nil {
+		if err := rows.Scan(&item.ID, &item.UserID, &item.Name, &item.Email, &item.Role, &item.LastLoginAt, &item.LastSeenAt, &item.ExpiresAt, &item.Blocked, &item.LockedUntil, &userAgent, &item.IP); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 			return
 		}
 		item.DeviceLabel = deriveDeviceLabel(userAgent)
+		item.Current = item.ID == currentSession
 		items = append(items, item)
 	}
 	c.JSON(http.StatusOK, gin.H{"items": items})
@@ -104,12 +121,7 @@ func deriveDeviceLabel(ua string) string {
 }
 
 func (s *Service) SessionAction(c *gin.Context) {
-	role := strings.TrimSpace(roleFromContext(c))
-	if role != "org_admin" && role != "system_admin" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "admin access required"})
-		return
-	}
-	tenantSlug := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	currentEmail := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
 	userID := strings.TrimSpace(c.Param("id"))
 	if userID == "" {
@@ -123,23 +135,28 @@ func (s *Service) SessionAction(c *gin.Context) {
 		return
 	}
 	action := strings.ToLower(strings.TrimSpace(req.Action))
-	if action != "terminate" && action != "block" && action != "unblock" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be terminate, block or unblock"})
+	if action != "terminate" && action != "block" && action != "unblock" && action != "unlock" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be terminate, block, unblock or unlock"})
 		return
 	}
 
-	var tenantID int64
+	var targetID int64
 	var targetEmail string
+	var targetRole string
 	if err := s.DB.QueryRow(c.Request.Context(), `
-		SELECT t.id, lower(u.email)
+		SELECT u.id, lower(u.email), COALESCE(u.role, 'member')
 		FROM users u
-		JOIN tenants t ON t.id = u.tenant_id
-		WHERE t.slug = $1 AND u.public_id = $2
+		WHERE u.tenant_id = $1 AND u.public_id = $2
 		LIMIT 1
-	`, tenantSlug, userID).Scan(&tenantID, &targetEmail); err != nil {
+	`, tenantID, userID).Scan(&targetID, &targetEmail, &targetRole); err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
 		return
 	}
+	// Session actions follow the role rules: only owners act on their peers.
+	if targetEmail != currentEmail && !canAssignRole(roleFromContext(c), targetRole, targetRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot manage a user at or above your own role"})
+		return
+	}
 	if action == "block" && targetEmail == currentEmail {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "you cannot block your own account"})
 		return
@@ -147,21 +164,242 @@ func (s *Service) SessionAction(c *gin.Context) {
 
 	switch action {
 	case "terminate":
-		if _, err := s.DB.Exec(c.Request.Context(), `UPDATE users SET last_login_at = NULL WHERE public_id = $1 AND tenant_id = $2`, userID, tenantID); err != nil {
+		sessionID := strings.TrimSpace(req.SessionID)
+		if sessionID != "" {
+			cmd, err := s.DB.Exec(c.Request.Context(), `
+				UPDATE user_sessions
+				SET revoked_at = NOW(), revoked_reason = 'terminated'
+				WHERE public_id = $1 AND user_id = $2 AND revoked_at IS NULL
+			`, sessionID, targetID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to terminate session"})
+				return
+			}
+			if cmd.RowsAffected() == 0 {
+				c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+				return
+			}
+			break
+		}
+		if err := s.revokeUserSessions(c.Request.Context(), targetID, "terminated"); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to terminate session"})
 			return
 		}
 	case "block":
-		if _, err := s.DB.Exec(c.Request.Context(), `UPDATE users SET blocked = true, last_login_at = NULL WHERE public_id = $1 AND tenant_id = $2`, userID, tenantID); err != nil {
+		if _, err := s.DB.Exec(c.Request.Context(), `UPDATE users SET blocked = true WHERE id = $1 AND tenant_id = $2`, targetID, tenantID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to block user"})
 			return
 		}
+		if err := s.revokeUserSessions(c.Request.Context(), targetID, "blocked"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke user sessions"})
+			return
+		}
 	case "unblock":
-		if _, err := s.DB.Exec(c.Request.Context(), `UPDATE users SET blocked = false WHERE public_id = $1 AND tenant_id = $2`, userID, tenantID); err != nil {
+		if _, err := s.DB.Exec(c.Request.Context(), `UPDATE users SET blocked = false WHERE id = $1 AND tenant_id = $2`, targetID, tenantID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unblock user"})
 			return
 		}
+	case "unlock":
+		if _, err := s.DB.Exec(c.Request.Context(), `
+			UPDATE users
+			SET locked_until = NULL, failed_login_count = 0
+			WHERE id = $1 AND tenant_id = $2
+		`, targetID, tenantID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock user"})
+			return
+		}
+		s.writeAuditEvent(c.Request.Context(), tenantSlugFromContext(c), targetEmail, targetRole, c.Request.Method, c.Request.URL.Path, http.StatusOK, "account_unlocked")
 	}
 
 	c.JSON(http.StatusOK, gin.H{"status": "updated"})
 }
+
+func (s *Service) RefreshSession(c *gin.Context) {
+	var req refreshSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	sessionID, _, ok := strings.Cut(strings.TrimSpace(req.RefreshToken), ".")
+	if !ok || sessionID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+
+	var (
+		rowID        int64
+		currentHash  string
+		previousHash string
+		revoked      bool
+		expired      bool
+		userPublicID string
+		email        string
+		name         string
+		role         string
+		blocked      bool
+		tenantID     int64
+		tenantSlug   string
+		tenantName   string
+		tenantLogo   string
+	)
+	err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT
+			us.id, us.refresh_token_hash, us.previous_refresh_token_hash, us.revoked_at IS NOT NULL, us.expires_at <= NOW(),
+			COALESCE(u.public_id, ''), lower(u.email), u.name, COALESCE(u.role, 'member'), COALESCE(u.blocked, false),
+			t.id, t.slug, t.name, COALESCE(t.logo_url, '')
+		FROM user_sessions us
+		JOIN users u ON u.id = us.user_id
+		JOIN tenants t ON t.id = us.tenant_id
+		WHERE us.public_id = $1
+		LIMIT 1
+	`, sessionID).Scan(&rowID, &currentHash, &previousHash, &revoked, &expired, &userPublicID, &email, &name, &role, &blocked, &tenantID, &tenantSlug, &tenantName, &tenantLogo)
+	if err != nil || revoked || expired {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "session expired or revoked"})
+		return
+	}
+
+	presentedHash := hashToken(req.RefreshToken)
+	if previousHash != "" && subtle.ConstantTimeCompare([]byte(presentedHash), []byte(previousHash)) == 1 {
+		// A rotated-out token was replayed: treat the whole session as compromised.
+		_, _ = s.DB.Exec(c.Request.Context(), `
+			UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = 'refresh_token_reuse'
+			WHERE id = $1 AND revoked_at IS NULL
+		`, rowID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "session expired or revoked"})
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(presentedHash), []byte(currentHash)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid refresh token"})
+		return
+	}
+	if blocked {
+		_ = s.revokeSession(c.Request.Context(), sessionID, "blocked")
+		c.JSON(http.StatusForbidden, gin.H{"error": "account access is blocked"})
+		return
+	}
+
+	refreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+	cmd, err := s.DB.Exec(c.Request.Context(), `
+		UPDATE user_sessions
+		SET previous_refresh_token_hash = refresh_token_hash,
+		    refresh_token_hash = $2,
+		    last_seen_at = NOW(),
+		    user_agent = $3,
+		    ip = $4
+		WHERE id = $1 AND refresh_token_hash = $5 AND revoked_at IS NULL
+	`, rowID, hashToken(refreshToken), strings.TrimSpace(c.GetHeader("User-Agent")), strings.TrimSpace(c.ClientIP()), currentHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "session update failed"})
+		return
+	}
+	if cmd.RowsAffected() == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token already used"})
+		return
+	}
+
+	token, err := s.issueToken(userPublicID, sessionID, tenantID, tenantSlug, email, name, tenantName, tenantLogo, role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "token generation failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int64(s.JWTTTL.Seconds()),
+	})
+}
+
+func (s *Service) Logout(c *gin.Context) {
+	if err := s.revokeSession(c.Request.Context(), sessionFromContext(c), "logout"); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "logout failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+// startSession records a new device session and returns its id (the JWT "sid"
+// claim) together with the first refresh token for it.
+func (s *Service) startSession(ctx context.Context, userID, tenantID int64, userAgent, ip string) (string, string, error) {
+	sessionID, err := randomToken(18)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := newRefreshToken(sessionID)
+	if err != nil {
+		return "", "", err
+	}
+	_, err = s.DB.Exec(ctx, `
+		INSERT INTO user_sessions (public_id, user_id, tenant_id, refresh_token_hash, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, sessionID, userID, tenantID, hashToken(refreshToken), strings.TrimSpace(userAgent), strings.TrimSpace(ip), time.Now().Add(s.RefreshTTL))
+	if err != nil {
+		return "", "", err
+	}
+	return sessionID, refreshToken, nil
+}
+
+func (s *Service) revokeSession(ctx context.Context, sessionID, reason string) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE user_sessions
+		SET revoked_at = NOW(), revoked_reason = $2
+		WHERE public_id = $1 AND revoked_at IS NULL
+	`, sessionID, reason)
+	return err
+}
+
+func (s *Service) revokeUserSessions(ctx context.Context, userID int64, reason string) error {
+	_, err := s.DB.Exec(ctx, `
+		UPDATE user_sessions
+		SET revoked_at = NOW(), revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID, reason)
+	return err
+}
+
+// touchSession bumps last_seen_at at most once a minute per session so the
+// per-request cost stays a cheap no-op update.
+func (s *Service) touchSession(ctx context.Context, sessionID string) {
+	_, _ = s.DB.Exec(ctx, `
+		UPDATE user_sessions
+		SET last_seen_at = NOW()
+		WHERE public_id = $1 AND last_seen_at < NOW() - INTERVAL '1 minute'
+	`, sessionID)
+}
+
+func (s *Service) countActiveSessions(ctx context.Context, tenantID int64) (int64, error) {
+	var count int64
+	err := s.DB.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM user_sessions
+		WHERE tenant_id = $1
+		  AND revoked_at IS NULL
+		  AND expires_at > NOW()
+		  AND last_seen_at >= NOW() - INTERVAL '24 hours'
+	`, tenantID).Scan(&count)
+	return count, err
+}
+
+func newRefreshToken(sessionID string) (string, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return sessionID + "." + secret, nil
+}
+
+func randomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(v string) string {
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:])
+}
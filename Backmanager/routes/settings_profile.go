@@ -6,11 +6,14 @@ import (
 	"strings"
 	"time"
 
+	"backmanager/mailer"
+
 	"github.com/gin-gonic/gin"
 )
 
 type userSettingsResponse struct {
 	Timezone          string   `json:"timezone"`
+	Locale            string   `json:"locale"`
 	WeekStartsOn      string   `json:"week_starts_on"`
 	ReminderFrequency string   `json:"reminder_frequency"`
 	ReminderDays      []string `json:"reminder_days"`
@@ -29,6 +32,7 @@ type userSettingsResponse struct {
 
 type updateUserSettingsRequest struct {
 	Timezone          string   `json:"timezone"`
+	Locale            string   `json:"locale"`
 	WeekStartsOn      string   `json:"week_starts_on"`
 	ReminderFrequency string   `json:"reminder_frequency"`
 	ReminderDays      []string `json:"reminder_days"`
@@ -64,9 +68,9 @@ type updateUserProfileRequest struct {
 }
 
 func (s *Service) GetUserSettings(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || email == "" {
+	if tenantID <= 0 || email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
 		return
 	}
@@ -84,13 +88,14 @@ func (s *Service) GetUserSettings(c *gin.Context) {
 	var reminderDaysRaw []byte
 	var approversRaw []byte
 	err := s.DB.QueryRow(c.Request.Context(), `
-		SELECT timezone, week_starts_on, reminder_frequency, reminder_days, reminder_time, reminders_enabled,
+		SELECT timezone, locale, week_starts_on, reminder_frequency, reminder_days, reminder_time, reminders_enabled,
 		       daily_digest, overdue_alerts, email_summaries, private_projects, log_retention_days, admins_can_export,
 		       COALESCE(approval_pipeline, 'simple'), COALESCE(approval_email_notifications, true), COALESCE(approval_approvers, '[]'::jsonb)
 		FROM user_settings
 		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
 	`, tenantID, email).Scan(
 		&out.Timezone,
+		&out.Locale,
 		&out.WeekStartsOn,
 		&out.ReminderFrequency,
 		&reminderDaysRaw,
@@ -119,9 +124,9 @@ func (s *Service) GetUserSettings(c *gin.Context) {
 }
 
 func (s *Service) UpdateUserSettings(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || email == "" {
+	if tenantID <= 0 || email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
 		return
 	}
@@ -135,6 +140,14 @@ func (s *Service) UpdateUserSettings(c *gin.Context) {
 	if strings.TrimSpace(req.Timezone) == "" {
 		req.Timezone = "UTC"
 	}
+	req.Locale = mailer.NormalizeLocale(req.Locale)
+	if req.Locale == "" {
+		req.Locale = mailer.DefaultLocale
+	}
+	if !mailer.SupportedLocale(req.Locale) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported locale"})
+		return
+	}
 	if strings.TrimSpace(req.WeekStartsOn) == "" {
 		req.WeekStartsOn = "monday"
 	}
@@ -171,9 +184,9 @@ func (s *Service) UpdateUserSettings(c *gin.Context) {
 		INSERT INTO user_settings (
 			tenant_id, user_email, timezone, week_starts_on, reminder_frequency, reminder_days, reminder_time,
 			reminders_enabled, daily_digest, overdue_alerts, email_summaries, private_projects, log_retention_days, admins_can_export,
-			approval_pipeline, approval_email_notifications, approval_approvers, updated_at
+			approval_pipeline, approval_email_notifications, approval_approvers, locale, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17::jsonb, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17::jsonb, $18, NOW())
 		ON CONFLICT (tenant_id, user_email) DO UPDATE SET
 			timezone = EXCLUDED.timezone,
 			week_starts_on = EXCLUDED.week_starts_on,
@@ -190,10 +203,11 @@ func (s *Service) UpdateUserSettings(c *gin.Context) {
 			approval_pipeline = EXCLUDED.approval_pipeline,
 			approval_email_notifications = EXCLUDED.approval_email_notifications,
 			approval_approvers = EXCLUDED.approval_approvers,
+			locale = EXCLUDED.locale,
 			updated_at = NOW()
 	`, tenantID, email, strings.TrimSpace(req.Timezone), strings.TrimSpace(req.WeekStartsOn), strings.TrimSpace(req.ReminderFrequency), string(daysJSON),
 		strings.TrimSpace(req.ReminderTime), req.RemindersEnabled, req.DailyDigest, req.OverdueAlerts, req.EmailSummaries, req.PrivateProjects,
-		req.LogRetentionDays, req.AdminsCanExport, req.ApprovalPipeline, req.ApprovalEmails, string(approversJSON)); err != nil {
+		req.LogRetentionDays, req.AdminsCanExport, req.ApprovalPipeline, req.ApprovalEmails, string(approversJSON), req.Locale); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save settings"})
 		return
 	}
@@ -202,9 +216,9 @@ func (s *Service) UpdateUserSettings(c *gin.Context) {
 }
 
 func (s *Service) GetUserProfile(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || email == "" {
+	if tenantID <= 0 || email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
 		return
 	}
@@ -232,9 +246,9 @@ func (s *Service) GetUserProfile(c *gin.Context) {
 	if err := s.DB.QueryRow(c.Request.Context(), `
 		SELECT
 			COALESCE(t.max_sessions, 5),
-			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.id AND u.last_login_at >= NOW() - INTERVAL '24 hours')
+			(SELECT COUNT(*) FROM user_sessions us WHERE us.tenant_id = t.id AND us.revoked_at IS NULL AND us.expires_at > NOW() AND us.last_seen_at >= NOW() - INTERVAL '24 hours')
 		FROM tenants t
-		WHERE t.slug = $1
+		WHERE t.id = $1
 		LIMIT 1
 	`, tenantID).Scan(&out.MaxSessions, &out.ActiveSessions24h); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load session usage"})
@@ -244,9 +258,9 @@ func (s *Service) GetUserProfile(c *gin.Context) {
 }
 
 func (s *Service) UpdateUserProfile(c *gin.Context) {
-	tenantID := strings.TrimSpace(tenantFromContext(c))
+	tenantID := tenantFromContext(c)
 	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-	if tenantID == "" || email == "" {
+	if tenantID <= 0 || email == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
 		return
 	}
@@ -0,0 +1,235 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// notificationChannel is the LISTEN/NOTIFY channel fed by the
+	// notifications_notify trigger.
+	notificationChannel = "notifications"
+	streamHeartbeat     = 25 * time.Second
+	streamBatchLimit    = 100
+)
+
+// notificationHub fans NOTIFY wake-ups out to the SSE connections on this
+// replica. Subscribers are keyed by tenant and lowercased email and receive a
+// bare signal; each stream then reads its new rows from the table, so a
+// dropped signal only delays delivery until the next heartbeat.
+type notificationHub struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+func newNotificationHub() *notificationHub {
+	return &notificationHub{subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+func hubKey(tenantID int64, email string) string {
+	return strconv.FormatInt(tenantID, 10) + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func (h *notificationHub) subscribe(key string) chan struct{} {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[key] == nil {
+		h.subs[key] = make(map[chan struct{}]struct{})
+	}
+	h.subs[key][ch] = struct{}{}
+	return ch
+}
+
+func (h *notificationHub) unsubscribe(key string, ch chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subs[key], ch)
+	if len(h.subs[key]) == 0 {
+		delete(h.subs, key)
+	}
+}
+
+func (h *notificationHub) publish(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[key] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+type notificationSignal struct {
+	TenantID  int64  `json:"tenant_id"`
+	Recipient string `json:"recipient"`
+}
+
+// RunNotificationListener holds a LISTEN connection and forwards every
+// notification insert to the local hub, reconnecting until ctx is cancelled.
+func (s *Service) RunNotificationListener(ctx context.Context) {
+	for {
+		if err := s.listenNotifications(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notification listener: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (s *Service) listenNotifications(ctx context.Context) error {
+	pooled, err := s.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notificationChannel); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var sig notificationSignal
+		if err := json.Unmarshal([]byte(n.Payload), &sig); err != nil {
+			continue
+		}
+		s.notifyHub.publish(hubKey(sig.TenantID, sig.Recipient))
+	}
+}
+
+// StreamNotifications is a Server-Sent Events feed of the caller's new
+// notifications. Each event's id is the notification id, so a reconnecting
+// EventSource resumes after Last-Event-ID; without one the stream starts at
+// the newest existing row.
+func (s *Service) StreamNotifications(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	recipient := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || recipient == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing notification context"})
+		return
+	}
+	ctx := c.Request.Context()
+
+	key := hubKey(tenantID, recipient)
+	wake := s.notifyHub.subscribe(key)
+	defer s.notifyHub.unsubscribe(key, wake)
+
+	lastID, err := strconv.ParseInt(strings.TrimSpace(c.GetHeader("Last-Event-ID")), 10, 64)
+	if err != nil || lastID < 0 {
+		lastID = 0
+		if raw := strings.TrimSpace(c.Query("last_event_id")); raw != "" {
+			lastID, _ = strconv.ParseInt(raw, 10, 64)
+		}
+	}
+	if lastID <= 0 {
+		if err := s.DB.QueryRow(ctx, `
+			SELECT COALESCE(MAX(id), 0)
+			FROM notifications
+			WHERE tenant_id = $1 AND lower(recipient_email) = lower($2)
+		`, tenantID, recipient).Scan(&lastID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+			return
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: 5000\n\n")
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		items, err := s.notificationsAfter(ctx, tenantID, recipient, lastID)
+		if err != nil {
+			return
+		}
+		for _, it := range items {
+			payload, _ := json.Marshal(it)
+			fmt.Fprintf(c.Writer, "id: %s\nevent: notification\ndata: %s\n\n", it.ID, payload)
+			lastID, _ = strconv.ParseInt(it.ID, 10, 64)
+		}
+		if len(items) > 0 {
+			c.Writer.Flush()
+		}
+		if len(items) == streamBatchLimit {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-wake:
+		case <-heartbeat.C:
+			// The session was checked once at connect; re-check so a revoked
+			// session or blocked user does not keep a live feed.
+			if !s.streamSessionActive(ctx, sessionFromContext(c)) {
+				fmt.Fprintf(c.Writer, "event: session_ended\ndata: {}\n\n")
+				c.Writer.Flush()
+				return
+			}
+			fmt.Fprintf(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+func (s *Service) streamSessionActive(ctx context.Context, sessionID string) bool {
+	var active bool
+	err := s.DB.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_sessions us
+			JOIN users u ON u.id = us.user_id
+			WHERE us.public_id = $1 AND us.revoked_at IS NULL AND us.expires_at > NOW() AND COALESCE(u.blocked, false) = false
+		)
+	`, sessionID).Scan(&active)
+	// A transient DB error should not drop every connected client.
+	return err != nil || active
+}
+
+func (s *Service) notificationsAfter(ctx context.Context, tenantID int64, recipient string, afterID int64) ([]notificationItem, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT id, tenant_id, recipient_email, type, title, detail, COALESCE(meta, '{}'::jsonb), read_at, created_at
+		FROM notifications
+		WHERE tenant_id = $1 AND lower(recipient_email) = lower($2) AND id > $3
+		ORDER BY id
+		LIMIT $4
+	`, tenantID, recipient, afterID, streamBatchLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]notificationItem, 0)
+	for rows.Next() {
+		var id int64
+		var it notificationItem
+		if err := rows.Scan(&id, &it.TenantID, &it.RecipientEmail, &it.Type, &it.Title, &it.Detail, &it.Meta, &it.ReadAt, &it.CreatedAt); err != nil {
+			return nil, err
+		}
+		it.ID = strconv.FormatInt(id, 10)
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
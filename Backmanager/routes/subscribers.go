@@ -0,0 +1,194 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleNotificationEvent is the "notifications" event subscriber: in-app
+// notifications and email for the events that had them inline before.
+func (s *Service) handleNotificationEvent(ctx context.Context, ev domainEvent) error {
+	switch ev.Type {
+	case "project.created":
+		var p Project
+		if err := json.Unmarshal(ev.Payload, &p); err != nil {
+			return err
+		}
+		return ignoreOptOut(s.enqueueTemplate(ctx, "notification", ev.TenantID, ev.ActorEmail, "project_created", map[string]any{"ProjectName": p.Name}))
+
+	case "project.updated":
+		var p Project
+		if err := json.Unmarshal(ev.Payload, &p); err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(p.Status), "pending") {
+			return nil
+		}
+		openTasks, _ := s.countOpenProjectTasks(ctx, ev.TenantID, p.ID)
+		detail := "Project is pending review and still has active work."
+		dueDate := ""
+		if p.DueDate != nil {
+			dueDate = p.DueDate.Format("2006-01-02")
+			detail = "Project is pending review. Due " + dueDate + ". Open tasks: " + strconv.FormatInt(openTasks, 10) + "."
+		}
+		s.notifyProjectAssignees(ctx, ev.TenantID, p.ID, p.Name, p.Assignees, "Pending project reminder: "+p.Name, detail, dueDate, openTasks)
+		return nil
+
+	case "task.created":
+		var t Task
+		if err := json.Unmarshal(ev.Payload, &t); err != nil {
+			return err
+		}
+		return ignoreOptOut(s.enqueueTemplate(ctx, "notification", ev.TenantID, ev.ActorEmail, "task_created", map[string]any{"TaskTitle": t.Title}))
+
+	case "approval.requested", "approval.progressed", "approval.approved", "approval.rejected":
+		var item approvalEvent
+		if err := json.Unmarshal(ev.Payload, &item); err != nil {
+			return err
+		}
+		return s.notifyApprovalEvent(ctx, ev, item)
+	}
+	return nil
+}
+
+func (s *Service) notifyApprovalEvent(ctx context.Context, ev domainEvent, item approvalEvent) error {
+	requester := []string{item.RequestedByEmail}
+	meta := map[string]any{"approval_id": item.ID, "project": item.ProjectName}
+	switch ev.Type {
+	case "approval.requested":
+		if item.NotifyApprover {
+			if err := ignoreOptOut(s.sendApprovalStepEmail(ctx, item.approvalRequestItem)); err != nil {
+				return err
+			}
+		}
+		return s.createInAppNotification(ctx, ev.TenantID, requester, "approval", "Approval request submitted", "Approval is pending via email pipeline.", meta)
+
+	case "approval.progressed":
+		if err := ignoreOptOut(s.sendApprovalStepEmail(ctx, item.approvalRequestItem)); err != nil {
+			return err
+		}
+		meta["step"] = item.CurrentStep + 1
+		return s.createInAppNotification(ctx, ev.TenantID, requester, "approval", "Approval progressed", "One approver approved. Workflow moved to next approver.", meta)
+
+	case "approval.approved":
+		if err := s.createInAppNotification(ctx, ev.TenantID, requester, "approval", "Approval completed", "Your request has been approved.", meta); err != nil {
+			return err
+		}
+		return ignoreOptOut(s.enqueueTemplate(ctx, "approval", ev.TenantID, item.RequestedByEmail, "approval_completed", map[string]any{"ProjectName": item.ProjectName}))
+
+	case "approval.rejected":
+		if err := s.createInAppNotification(ctx, ev.TenantID, requester, "approval", "Approval rejected", "Your approval request was rejected.", meta); err != nil {
+			return err
+		}
+		return ignoreOptOut(s.enqueueTemplate(ctx, "approval", ev.TenantID, item.RequestedByEmail, "approval_rejected", map[string]any{"ProjectName": item.ProjectName, "Actor": ev.ActorEmail}))
+	}
+	return nil
+}
+
+func ignoreOptOut(err error) error {
+	if errors.Is(err, errNotificationOptedOut) {
+		return nil
+	}
+	return err
+}
+
+type auditEntry struct {
+	ID          int64     `json:"id"`
+	EventID     int64     `json:"event_id"`
+	EventType   string    `json:"event_type"`
+	ActorEmail  string    `json:"actor_email"`
+	SubjectType string    `json:"subject_type"`
+	SubjectID   *int64    `json:"subject_id,omitempty"`
+	Summary     string    `json:"summary"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// handleAuditEvent is the "audit" event subscriber. It keeps a readable trail
+// in audit_log; the event id is unique there, so replays are no-ops.
+func (s *Service) handleAuditEvent(ctx context.Context, ev domainEvent) error {
+	var fields struct {
+		ID          *int64 `json:"id"`
+		Name        string `json:"name"`
+		Title       string `json:"title"`
+		ProjectName string `json:"project_name"`
+	}
+	_ = json.Unmarshal(ev.Payload, &fields)
+
+	subject, verb, _ := strings.Cut(ev.Type, ".")
+	label := fields.Name
+	if label == "" {
+		label = fields.Title
+	}
+	if label == "" && subject == "approval" {
+		label = fields.ProjectName
+	}
+	summary := subject + " " + verb
+	if fields.ID != nil {
+		summary = subject + " #" + strconv.FormatInt(*fields.ID, 10) + " " + verb
+	}
+	if label != "" {
+		summary += ": " + label
+	}
+
+	_, err := s.DB.Exec(ctx, `
+		INSERT INTO audit_log (tenant_id, event_id, event_type, actor_email, subject_type, subject_id, summary, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (event_id) DO NOTHING
+	`, ev.TenantID, ev.ID, ev.Type, ev.ActorEmail, subject, fields.ID, summary, ev.CreatedAt)
+	return err
+}
+
+func (s *Service) ListAuditLog(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	limit := 100
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 && n <= 500 {
+			limit = n
+		}
+	}
+	var before int64
+	if raw := strings.TrimSpace(c.Query("cursor")); raw != "" {
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+		before = n
+	}
+	subject := strings.ToLower(strings.TrimSpace(c.Query("subject_type")))
+
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, event_id, event_type, actor_email, subject_type, subject_id, summary, created_at
+		FROM audit_log
+		WHERE tenant_id = $1 AND ($2 = 0 OR id < $2) AND ($3 = '' OR subject_type = $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`, tenantID, before, subject, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]auditEntry, 0, limit)
+	for rows.Next() {
+		var item auditEntry
+		if err := rows.Scan(&item.ID, &item.EventID, &item.EventType, &item.ActorEmail, &item.SubjectType, &item.SubjectID, &item.Summary, &item.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	nextCursor := ""
+	if len(items) == limit {
+		nextCursor = strconv.FormatInt(items[len(items)-1].ID, 10)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "next_cursor": nextCursor})
+}
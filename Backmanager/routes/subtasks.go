@@ -0,0 +1,431 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type Subtask struct {
+	ID          int64      `json:"id"`
+	TaskID      int64      `json:"task_id"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Position    int        `json:"position"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type subtaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type subtaskRequest struct {
+	Title    string `json:"title" binding:"required"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee"`
+	// Position is only read on create; omit it to append.
+	Position *int `json:"position"`
+}
+
+// queryer is satisfied by both the pool and a transaction.
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+const subtaskColumns = `id, task_id, title, status, assignee_email, position, completed_at, created_at, updated_at`
+
+func scanSubtask(row pgx.Row, item *Subtask) error {
+	return row.Scan(&item.ID, &item.TaskID, &item.Title, &item.Status, &item.Assignee, &item.Position, &item.CompletedAt, &item.CreatedAt, &item.UpdatedAt)
+}
+
+func rollupSubtasks(items []Subtask) subtaskProgress {
+	progress := subtaskProgress{Total: len(items)}
+	for _, item := range items {
+		if isDoneStatus(item.Status) {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// loadSubtasks returns the subtasks of taskIDs grouped by task, in position
+// order.
+func loadSubtasks(ctx context.Context, q queryer, tenantID int64, taskIDs []int64) (map[int64][]Subtask, error) {
+	out := make(map[int64][]Subtask, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return out, nil
+	}
+	rows, err := q.Query(ctx, `
+		SELECT `+subtaskColumns+`
+		FROM task_subtasks
+		WHERE tenant_id = $1 AND task_id = ANY($2)
+		ORDER BY task_id, position, id
+	`, tenantID, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item Subtask
+		if err := scanSubtask(rows, &item); err != nil {
+			return nil, err
+		}
+		out[item.TaskID] = append(out[item.TaskID], item)
+	}
+	return out, rows.Err()
+}
+
+// attachSubtasks fills Subtasks and SubtaskProgress on each task.
+func attachSubtasks(ctx context.Context, q queryer, tenantID int64, tasks []Task) error {
+	ids := make([]int64, len(tasks))
+	for i := range tasks {
+		ids[i] = tasks[i].ID
+	}
+	byTask, err := loadSubtasks(ctx, q, tenantID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Subtasks = byTask[tasks[i].ID]
+		if tasks[i].Subtasks == nil {
+			tasks[i].Subtasks = []Subtask{}
+		}
+		tasks[i].SubtaskProgress = rollupSubtasks(tasks[i].Subtasks)
+	}
+	return nil
+}
+
+// lockTask locks the parent task row so concurrent subtask inserts and
+// reorders see consistent positions.
+func lockTask(ctx context.Context, tx pgx.Tx, tenantID, taskID int64) error {
+	var id int64
+	return tx.QueryRow(ctx, `
+		SELECT id
+		FROM tasks
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, taskID, tenantID).Scan(&id)
+}
+
+func subtaskPathIDs(c *gin.Context) (int64, int64, bool) {
+	taskID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || taskID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return 0, 0, false
+	}
+	raw := strings.TrimSpace(c.Param("subtask_id"))
+	if raw == "" {
+		return taskID, 0, true
+	}
+	subtaskID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || subtaskID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subtask id"})
+		return 0, 0, false
+	}
+	return taskID, subtaskID, true
+}
+
+// validSubtaskAssignee writes a 400 and returns false when assignee is set but
+// is not a user of the tenant.
+func (s *Service) validSubtaskAssignee(c *gin.Context, tenantID int64, assignee string) bool {
+	if assignee == "" {
+		return true
+	}
+	unknown, err := s.unknownTenantUsers(c.Request.Context(), tenantID, []string{assignee})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return false
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown assignee: " + assignee})
+		return false
+	}
+	return true
+}
+
+func (s *Service) ListSubtasks(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	taskID, _, ok := subtaskPathIDs(c)
+	if !ok {
+		return
+	}
+	var exists bool
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2)
+	`, taskID, tenantID).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	byTask, err := loadSubtasks(c.Request.Context(), s.DB, tenantID, []int64{taskID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	items := byTask[taskID]
+	if items == nil {
+		items = []Subtask{}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "progress": rollupSubtasks(items)})
+}
+
+func (s *Service) CreateSubtask(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	taskID, _, ok := subtaskPathIDs(c)
+	if !ok {
+		return
+	}
+	var req subtaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if strings.TrimSpace(req.Status) == "" {
+		req.Status = "todo"
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must not be negative"})
+		return
+	}
+	assignee := strings.ToLower(strings.TrimSpace(req.Assignee))
+	if !s.validSubtaskAssignee(c, tenantID, assignee) {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if err := lockTask(c.Request.Context(), tx, tenantID, taskID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	var position int
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT COALESCE(MAX(position) + 1, 0)
+		FROM task_subtasks
+		WHERE task_id = $1
+	`, taskID).Scan(&position); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if req.Position != nil && *req.Position < position {
+		position = *req.Position
+		if _, err := tx.Exec(c.Request.Context(), `
+			UPDATE task_subtasks
+			SET position = position + 1
+			WHERE task_id = $1 AND position >= $2
+		`, taskID, position); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+			return
+		}
+	}
+
+	var item Subtask
+	err = scanSubtask(tx.QueryRow(c.Request.Context(), `
+		INSERT INTO task_subtasks (tenant_id, task_id, title, status, assignee_email, position, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7 THEN NOW() END)
+		RETURNING `+subtaskColumns+`
+	`, tenantID, taskID, strings.TrimSpace(req.Title), strings.TrimSpace(req.Status), assignee, position, isDoneStatus(req.Status)), &item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "subtask.created", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (s *Service) UpdateSubtask(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	taskID, subtaskID, ok := subtaskPathIDs(c)
+	if !ok {
+		return
+	}
+	var req subtaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if strings.TrimSpace(req.Status) == "" {
+		req.Status = "todo"
+	}
+	assignee := strings.ToLower(strings.TrimSpace(req.Assignee))
+	if !s.validSubtaskAssignee(c, tenantID, assignee) {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var item Subtask
+	err = scanSubtask(tx.QueryRow(c.Request.Context(), `
+		UPDATE task_subtasks
+		SET title = $1, status = $2, assignee_email = $3,
+			completed_at = CASE WHEN $4 THEN COALESCE(completed_at, NOW()) END,
+			updated_at = NOW()
+		WHERE id = $5 AND task_id = $6 AND tenant_id = $7
+		RETURNING `+subtaskColumns+`
+	`, strings.TrimSpace(req.Title), strings.TrimSpace(req.Status), assignee, isDoneStatus(req.Status), subtaskID, taskID, tenantID), &item)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subtask not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "subtask.updated", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (s *Service) DeleteSubtask(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	taskID, subtaskID, ok := subtaskPathIDs(c)
+	if !ok {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var title string
+	err = tx.QueryRow(c.Request.Context(), `
+		DELETE FROM task_subtasks
+		WHERE id = $1 AND task_id = $2 AND tenant_id = $3
+		RETURNING title
+	`, subtaskID, taskID, tenantID).Scan(&title)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "subtask not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "subtask.deleted", emailFromContext(c), gin.H{"id": subtaskID, "task_id": taskID, "title": title}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+// ReorderSubtasks takes every subtask id of the task in the new order.
+func (s *Service) ReorderSubtasks(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	taskID, _, ok := subtaskPathIDs(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		IDs []int64 `json:"ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	if err := lockTask(c.Request.Context(), tx, tenantID, taskID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+	byTask, err := loadSubtasks(c.Request.Context(), tx, tenantID, []int64{taskID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	current := byTask[taskID]
+	known := make(map[int64]bool, len(current))
+	for _, item := range current {
+		known[item.ID] = true
+	}
+	if len(req.IDs) != len(current) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every subtask of the task exactly once"})
+		return
+	}
+	for _, id := range req.IDs {
+		if !known[id] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every subtask of the task exactly once"})
+			return
+		}
+		delete(known, id)
+	}
+
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE task_subtasks st
+		SET position = o.ord - 1, updated_at = NOW()
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS o(id, ord)
+		WHERE st.id = o.id AND st.task_id = $2
+	`, req.IDs, taskID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	byTask, err = loadSubtasks(c.Request.Context(), tx, tenantID, []int64{taskID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	items := byTask[taskID]
+	if items == nil {
+		items = []Subtask{}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "progress": rollupSubtasks(items)})
+}
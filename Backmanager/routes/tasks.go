@@ -4,112 +4,226 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type Task struct {
-	ID          int64     `json:"id"`
-	TaskCode    string    `json:"task_code,omitempty"`
-	TenantID    string    `json:"tenant_id"`
-	ProjectID   int64     `json:"project_id"`
-	ProjectName string    `json:"project_name,omitempty"`
-	Phase       string    `json:"phase,omitempty"`
-	Title       string    `json:"title"`
-	Status      string    `json:"status"`
-	Priority    string    `json:"priority"`
-	Subtasks    []string  `json:"subtasks"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID              int64           `json:"id"`
+	TaskCode        string          `json:"task_code,omitempty"`
+	TenantID        int64           `json:"tenant_id"`
+	ProjectID       int64           `json:"project_id"`
+	ProjectName     string          `json:"project_name,omitempty"`
+	Phase           string          `json:"phase,omitempty"`
+	Title           string          `json:"title"`
+	Description     string          `json:"description"`
+	Status          string          `json:"status"`
+	Priority        string          `json:"priority"`
+	Assignees       []string        `json:"assignees"`
+	StartDate       *time.Time      `json:"start_date,omitempty"`
+	DueDate         *time.Time      `json:"due_date,omitempty"`
+	StoryPoints     *int            `json:"story_points,omitempty"`
+	EstimatedHours  *float64        `json:"estimated_hours,omitempty"`
+	Subtasks        []Subtask       `json:"subtasks"`
+	SubtaskProgress subtaskProgress `json:"subtask_progress"`
+	CompletedAt     *time.Time      `json:"completed_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// taskPlanFields are the planning fields shared by task create and update.
+// Dates are YYYY-MM-DD; assignees must be users of the tenant.
+type taskPlanFields struct {
+	Description    string   `json:"description"`
+	Assignees      []string `json:"assignees"`
+	StartDate      string   `json:"start_date"`
+	DueDate        string   `json:"due_date"`
+	StoryPoints    *int     `json:"story_points"`
+	EstimatedHours *float64 `json:"estimated_hours"`
 }
 
 type createTaskRequest struct {
-	TaskCode  string   `json:"task_code"`
-	ProjectID int64    `json:"project_id" binding:"required,gt=0"`
-	Phase     string   `json:"phase"`
-	Title     string   `json:"title" binding:"required"`
-	Status    string   `json:"status"`
-	Priority  string   `json:"priority"`
-	Subtasks  []string `json:"subtasks"`
+	TaskCode  string `json:"task_code"`
+	ProjectID int64  `json:"project_id" binding:"required,gt=0"`
+	Phase     string `json:"phase"`
+	Title     string `json:"title" binding:"required"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	// Subtasks seeds the task's first subtasks by title. Afterwards they are
+	// managed under /tasks/:id/subtasks.
+	Subtasks []string `json:"subtasks"`
+	taskPlanFields
 }
 
 type updateTaskRequest struct {
-	TaskCode  string   `json:"task_code"`
-	ProjectID int64    `json:"project_id" binding:"required,gt=0"`
-	Phase     string   `json:"phase"`
-	Title     string   `json:"title" binding:"required"`
-	Status    string   `json:"status"`
-	Priority  string   `json:"priority"`
-	Subtasks  []string `json:"subtasks"`
+	TaskCode  string `json:"task_code"`
+	ProjectID int64  `json:"project_id" binding:"required,gt=0"`
+	Phase     string `json:"phase"`
+	Title     string `json:"title" binding:"required"`
+	Status    string `json:"status"`
+	Priority  string `json:"priority"`
+	taskPlanFields
+}
+
+type taskPlan struct {
+	Description    string
+	Assignees      []string
+	StartDate      *time.Time
+	DueDate        *time.Time
+	StoryPoints    *int
+	EstimatedHours *float64
+}
+
+// taskColumns is the column list scanTask expects, in order.
+const taskColumns = `id, COALESCE(task_code, ''), tenant_id, project_id, COALESCE(phase, ''), title, description, status, priority,
+	assignees, start_date, due_date, story_points, estimated_hours::float8, completed_at, created_at`
+
+func scanTask(row pgx.Row, item *Task, extra ...any) error {
+	var projectID sql.NullInt64
+	var assigneesRaw []byte
+	dest := []any{
+		&item.ID, &item.TaskCode, &item.TenantID, &projectID, &item.Phase, &item.Title, &item.Description, &item.Status, &item.Priority,
+		&assigneesRaw, &item.StartDate, &item.DueDate, &item.StoryPoints, &item.EstimatedHours, &item.CompletedAt, &item.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if projectID.Valid {
+		item.ProjectID = projectID.Int64
+	}
+	item.Assignees = parseStringArrayJSON(assigneesRaw)
+	item.Subtasks = []Subtask{}
+	return nil
+}
+
+func isDoneStatus(status string) bool {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "done", "completed", "closed":
+		return true
+	}
+	return false
+}
+
+// parseTaskPlan validates the planning fields. The returned message is meant
+// for a 400 response.
+func parseTaskPlan(f taskPlanFields) (taskPlan, string) {
+	plan := taskPlan{
+		Description: strings.TrimSpace(f.Description),
+		Assignees:   uniqueEmails(f.Assignees),
+	}
+	if raw := strings.TrimSpace(f.StartDate); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return plan, "start_date must be YYYY-MM-DD"
+		}
+		plan.StartDate = &d
+	}
+	if raw := strings.TrimSpace(f.DueDate); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			return plan, "due_date must be YYYY-MM-DD"
+		}
+		plan.DueDate = &d
+	}
+	if plan.StartDate != nil && plan.DueDate != nil && plan.DueDate.Before(*plan.StartDate) {
+		return plan, "due_date must not be before start_date"
+	}
+	if f.StoryPoints != nil {
+		if *f.StoryPoints < 0 || *f.StoryPoints > 1000 {
+			return plan, "story_points must be between 0 and 1000"
+		}
+		plan.StoryPoints = f.StoryPoints
+	}
+	if f.EstimatedHours != nil {
+		h := *f.EstimatedHours
+		if math.IsNaN(h) || h < 0 || h > 100000 {
+			return plan, "estimated_hours must be between 0 and 100000"
+		}
+		h = math.Round(h*100) / 100
+		plan.EstimatedHours = &h
+	}
+	return plan, ""
 }
 
-func (s *Service) EnsureTasksTable() error {
-	_, err := s.DB.Exec(context.Background(), `
-		CREATE TABLE IF NOT EXISTS tasks (
-			id BIGSERIAL PRIMARY KEY,
-			task_code TEXT NOT NULL DEFAULT '',
-			tenant_id TEXT NOT NULL,
-			project_id BIGINT REFERENCES projects(id) ON DELETE CASCADE,
-			phase TEXT NOT NULL DEFAULT '',
-			title TEXT NOT NULL,
-			status TEXT NOT NULL DEFAULT 'todo',
-			priority TEXT NOT NULL DEFAULT 'medium',
-			subtasks JSONB NOT NULL DEFAULT '[]'::jsonb,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		);
-		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS subtasks JSONB NOT NULL DEFAULT '[]'::jsonb;
-		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS task_code TEXT NOT NULL DEFAULT '';
-		ALTER TABLE tasks ADD COLUMN IF NOT EXISTS phase TEXT NOT NULL DEFAULT '';
-		DO $$
-		BEGIN
-			IF EXISTS (
-				SELECT 1
-				FROM information_schema.columns
-				WHERE table_schema = 'public' AND table_name = 'tasks' AND column_name = 'project_id' AND data_type = 'text'
-			) THEN
-				ALTER TABLE tasks
-				ALTER COLUMN project_id TYPE BIGINT
-				USING CASE
-					WHEN project_id IS NULL OR trim(project_id) = '' THEN NULL
-					WHEN project_id ~ '^[0-9]+$' THEN project_id::BIGINT
-					ELSE NULL
-				END;
-			END IF;
-		END $$;
-		DO $$
-		BEGIN
-			IF EXISTS (
-				SELECT 1
-				FROM information_schema.columns
-				WHERE table_schema = 'public' AND table_name = 'tasks' AND column_name = 'subtasks' AND data_type <> 'jsonb'
-			) THEN
-				ALTER TABLE tasks
-				ALTER COLUMN subtasks TYPE JSONB
-				USING
-					CASE
-						WHEN subtasks IS NULL THEN '[]'::jsonb
-						ELSE to_jsonb(ARRAY[subtasks::text])
-					END;
-			END IF;
-		END $$;
-		CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id ON tasks (tenant_id);
-	`)
-	return err
+// unknownTenantUsers returns the emails that do not belong to a user of the
+// tenant. emails must already be lowercased.
+func (s *Service) unknownTenantUsers(ctx context.Context, tenantID int64, emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+	rows, err := s.DB.Query(ctx, `
+		SELECT lower(email)
+		FROM users
+		WHERE tenant_id = $1 AND lower(email) = ANY($2)
+	`, tenantID, emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	found := make(map[string]bool, len(emails))
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		found[email] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var unknown []string
+	for _, email := range emails {
+		if !found[email] {
+			unknown = append(unknown, email)
+		}
+	}
+	return unknown, nil
 }
 
+// ListTasks accepts optional filters: assignee (an email, or "me"), due_from
+// and due_to (YYYY-MM-DD, inclusive) and overdue=true for open tasks past
+// their due date.
 func (s *Service) ListTasks(c *gin.Context) {
 	tenantID := tenantFromContext(c)
+	assignee := strings.ToLower(strings.TrimSpace(c.Query("assignee")))
+	if assignee == "me" {
+		assignee = strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	}
+	var dueFrom, dueTo *time.Time
+	if raw := strings.TrimSpace(c.Query("due_from")); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "due_from must be YYYY-MM-DD"})
+			return
+		}
+		dueFrom = &d
+	}
+	if raw := strings.TrimSpace(c.Query("due_to")); raw != "" {
+		d, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "due_to must be YYYY-MM-DD"})
+			return
+		}
+		dueTo = &d
+	}
+	overdue := strings.EqualFold(strings.TrimSpace(c.Query("overdue")), "true")
+
 	rows, err := s.DB.Query(c.Request.Context(), `
-		SELECT tk.id, COALESCE(tk.task_code, ''), tk.tenant_id, tk.project_id, COALESCE(tk.phase, ''), tk.title, tk.status, tk.priority, COALESCE(tk.subtasks, '[]'::jsonb), tk.created_at, COALESCE(p.name, '')
-		FROM tasks tk
-		LEFT JOIN projects p ON p.id = tk.project_id
-		WHERE tk.tenant_id = $1
-		ORDER BY tk.id ASC
-	`, tenantID)
+		SELECT `+taskColumns+`, COALESCE((SELECT p.name FROM projects p WHERE p.id = tasks.project_id), '')
+		FROM tasks
+		WHERE tenant_id = $1
+			AND ($2 = '' OR assignees ? $2)
+			AND ($3::date IS NULL OR due_date >= $3)
+			AND ($4::date IS NULL OR due_date <= $4)
+			AND (NOT $5 OR (due_date < CURRENT_DATE AND completed_at IS NULL))
+		ORDER BY id ASC
+	`, tenantID, assignee, dueFrom, dueTo, overdue)
 	// In a real app, you'd want pagination here instead of returning all tasks at once.
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
@@ -120,22 +234,20 @@ func (s *Service) ListTasks(c *gin.Context) {
 	tasks := make([]Task, 0)
 	for rows.Next() {
 		var item Task
-		var projectID sql.NullInt64
-		var subtasksRaw []byte
-		if err := rows.Scan(&item.ID, &item.TaskCode, &item.TenantID, &projectID, &item.Phase, &item.Title, &item.Status, &item.Priority, &subtasksRaw, &item.CreatedAt, &item.ProjectName); err != nil {
+		if err := scanTask(rows, &item, &item.ProjectName); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 			return
 		}
-		if projectID.Valid {
-			item.ProjectID = projectID.Int64
-		}
-		item.Subtasks = parseStringArrayJSON(subtasksRaw)
 		tasks = append(tasks, item)
 	}
 	if err := rows.Err(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 		return
 	}
+	if err := attachSubtasks(c.Request.Context(), s.DB, tenantID, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"items": tasks})
 }
 
@@ -146,9 +258,6 @@ func (s *Service) CreateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
-	if req.Status == "" {
-		req.Status = "todo"
-	}
 	if req.Priority == "" {
 		req.Priority = "medium"
 	}
@@ -160,6 +269,11 @@ func (s *Service) CreateTask(c *gin.Context) {
 			cleanSubtasks = append(cleanSubtasks, t)
 		}
 	}
+	plan, msg := parseTaskPlan(req.taskPlanFields)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
 
 	var projectName string
 	if err := s.DB.QueryRow(c.Request.Context(), `
@@ -170,33 +284,81 @@ func (s *Service) CreateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
 		return
 	}
-	subtasksJSON, err := json.Marshal(cleanSubtasks)
+	unknown, err := s.unknownTenantUsers(c.Request.Context(), tenantID, plan.Assignees)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid subtasks payload"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown assignee: " + strings.Join(unknown, ", ")})
 		return
 	}
+	workflow, err := s.loadWorkflow(c.Request.Context(), tenantID, workflowEntityTask, req.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if strings.TrimSpace(req.Status) == "" {
+		req.Status = workflow.initialState("todo")
+	}
+	if terr := workflow.checkInitial(req.Status); terr != nil {
+		c.JSON(http.StatusBadRequest, terr.response())
+		return
+	}
+	req.Status = workflow.normalize(req.Status)
+	assigneesJSON, _ := json.Marshal(plan.Assignees)
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
 
 	var item Task
-	var subtasksRaw []byte
-	err = s.DB.QueryRow(c.Request.Context(), `
-		INSERT INTO tasks (task_code, tenant_id, project_id, phase, title, status, priority, subtasks)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb)
-		RETURNING id, task_code, tenant_id, project_id, phase, title, status, priority, subtasks, created_at
-	`, strings.TrimSpace(req.TaskCode), tenantID, req.ProjectID, req.Phase, strings.TrimSpace(req.Title), req.Status, req.Priority, string(subtasksJSON)).
-		Scan(&item.ID, &item.TaskCode, &item.TenantID, &item.ProjectID, &item.Phase, &item.Title, &item.Status, &item.Priority, &subtasksRaw, &item.CreatedAt)
+	err = scanTask(tx.QueryRow(c.Request.Context(), `
+		INSERT INTO tasks (task_code, tenant_id, project_id, phase, title, status, priority,
+			description, assignees, start_date, due_date, story_points, estimated_hours, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::jsonb, $10, $11, $12, $13, CASE WHEN $14 THEN NOW() END)
+		RETURNING `+taskColumns+`
+	`, strings.TrimSpace(req.TaskCode), tenantID, req.ProjectID, req.Phase, strings.TrimSpace(req.Title), req.Status, req.Priority,
+		plan.Description, string(assigneesJSON), plan.StartDate, plan.DueDate, plan.StoryPoints, plan.EstimatedHours, workflow.isTerminal(req.Status)), &item)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
 		return
 	}
+	if err := recordStatusChange(c.Request.Context(), tx, tenantID, workflowEntityTask, item.ID, "", item.Status, emailFromContext(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
 	item.ProjectName = projectName
-	item.Subtasks = cleanSubtasks
-	c.JSON(http.StatusCreated, item)
+	if len(cleanSubtasks) > 0 {
+		if _, err := tx.Exec(c.Request.Context(), `
+			INSERT INTO task_subtasks (tenant_id, task_id, title, position)
+			SELECT $1, $2, t.title, (t.ord - 1)::int
+			FROM unnest($3::text[]) WITH ORDINALITY AS t(title, ord)
+		`, tenantID, item.ID, cleanSubtasks); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+			return
+		}
+	}
+	tasks := []Task{item}
+	if err := attachSubtasks(c.Request.Context(), tx, tenantID, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	item = tasks[0]
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "task.created", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
 
-	s.sendAsyncNotification(
-		emailFromContext(c),
-		"Task created",
-		"Your task '"+item.Title+"' was created successfully.",
-	)
+	c.JSON(http.StatusCreated, item)
 }
 
 func (s *Service) UpdateTask(c *gin.Context) {
@@ -212,20 +374,15 @@ func (s *Service) UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
-	if strings.TrimSpace(req.Status) == "" {
-		req.Status = "todo"
-	}
 	if strings.TrimSpace(req.Priority) == "" {
 		req.Priority = "medium"
 	}
 	req.Phase = strings.TrimSpace(req.Phase)
 
-	cleanSubtasks := make([]string, 0, len(req.Subtasks))
-	for _, subtask := range req.Subtasks {
-		t := strings.TrimSpace(subtask)
-		if t != "" {
-			cleanSubtasks = append(cleanSubtasks, t)
-		}
+	plan, msg := parseTaskPlan(req.taskPlanFields)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
 	}
 
 	var projectName string
@@ -237,27 +394,127 @@ func (s *Service) UpdateTask(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
 		return
 	}
-	subtasksJSON, err := json.Marshal(cleanSubtasks)
+	unknown, err := s.unknownTenantUsers(c.Request.Context(), tenantID, plan.Assignees)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if len(unknown) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown assignee: " + strings.Join(unknown, ", ")})
+		return
+	}
+	workflow, err := s.loadWorkflow(c.Request.Context(), tenantID, workflowEntityTask, req.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	assigneesJSON, _ := json.Marshal(plan.Assignees)
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var fromStatus string
+	err = tx.QueryRow(c.Request.Context(), `
+		SELECT status
+		FROM tasks
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, taskID, tenantID).Scan(&fromStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "invalid subtasks payload"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	// An omitted status leaves the task where it is.
+	if strings.TrimSpace(req.Status) == "" {
+		req.Status = fromStatus
+	}
+	present := func(field string) bool {
+		switch field {
+		case "description":
+			return plan.Description != ""
+		case "assignees":
+			return len(plan.Assignees) > 0
+		case "phase":
+			return req.Phase != ""
+		case "start_date":
+			return plan.StartDate != nil
+		case "due_date":
+			return plan.DueDate != nil
+		case "story_points":
+			return plan.StoryPoints != nil
+		case "estimated_hours":
+			return plan.EstimatedHours != nil
+		}
+		return true
+	}
+	if terr := workflow.checkTransition(fromStatus, req.Status, present); terr != nil {
+		c.JSON(http.StatusBadRequest, terr.response())
 		return
 	}
+	// An unchanged status is stored as it was, even if it predates the
+	// workflow, and leaves completed_at alone.
+	statusChanged := !strings.EqualFold(strings.TrimSpace(fromStatus), strings.TrimSpace(req.Status))
+	if statusChanged {
+		req.Status = workflow.normalize(req.Status)
+	} else {
+		req.Status = fromStatus
+	}
 
+	// completed_at keeps its original time while the task stays done and is
+	// cleared when it is reopened.
 	var item Task
-	var subtasksRaw []byte
-	err = s.DB.QueryRow(c.Request.Context(), `
+	err = scanTask(tx.QueryRow(c.Request.Context(), `
 		UPDATE tasks
-		SET task_code = $1, project_id = $2, phase = $3, title = $4, status = $5, priority = $6, subtasks = $7::jsonb
-		WHERE id = $8 AND tenant_id = $9
-		RETURNING id, task_code, tenant_id, project_id, phase, title, status, priority, subtasks, created_at
-	`, strings.TrimSpace(req.TaskCode), req.ProjectID, req.Phase, strings.TrimSpace(req.Title), strings.TrimSpace(req.Status), strings.TrimSpace(req.Priority), string(subtasksJSON), taskID, tenantID).
-		Scan(&item.ID, &item.TaskCode, &item.TenantID, &item.ProjectID, &item.Phase, &item.Title, &item.Status, &item.Priority, &subtasksRaw, &item.CreatedAt)
+		SET task_code = $1, project_id = $2, phase = $3, title = $4, status = $5, priority = $6,
+			description = $7, assignees = $8::jsonb, start_date = $9, due_date = $10, story_points = $11, estimated_hours = $12,
+			completed_at = CASE WHEN NOT $16 THEN completed_at WHEN $13 THEN COALESCE(completed_at, NOW()) END
+		WHERE id = $14 AND tenant_id = $15
+		RETURNING `+taskColumns+`
+	`, strings.TrimSpace(req.TaskCode), req.ProjectID, req.Phase, strings.TrimSpace(req.Title), strings.TrimSpace(req.Status), strings.TrimSpace(req.Priority),
+		plan.Description, string(assigneesJSON), plan.StartDate, plan.DueDate, plan.StoryPoints, plan.EstimatedHours, workflow.isTerminal(req.Status), taskID, tenantID, statusChanged), &item)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
 		return
 	}
 	item.ProjectName = projectName
-	item.Subtasks = cleanSubtasks
+	if item.Status != fromStatus {
+		if err := recordStatusChange(c.Request.Context(), tx, tenantID, workflowEntityTask, item.ID, fromStatus, item.Status, emailFromContext(c)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+			return
+		}
+	}
+	// Dependencies are per project; moving the task drops the ones it had.
+	if _, err := tx.Exec(c.Request.Context(), `
+		DELETE FROM task_dependencies
+		WHERE (predecessor_id = $1 OR successor_id = $1) AND project_id <> $2
+	`, item.ID, item.ProjectID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	tasks := []Task{item}
+	if err := attachSubtasks(c.Request.Context(), tx, tenantID, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	item = tasks[0]
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "task.updated", emailFromContext(c), item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
 	c.JSON(http.StatusOK, item)
 }
 
@@ -269,21 +526,37 @@ func (s *Service) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	// Debug logging
-	fmt.Printf("DeleteTask called: tenantID=%s, taskID=%d\n", tenantID, taskID)
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
 
-	commandTag, err := s.DB.Exec(c.Request.Context(), `
+	var title string
+	var projectID int64
+	err = tx.QueryRow(c.Request.Context(), `
 		DELETE FROM tasks
 		WHERE id = $1 AND tenant_id = $2
-	`, taskID, tenantID)
+		RETURNING title, project_id
+	`, taskID, tenantID).Scan(&title, &projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
 		return
 	}
-	if commandTag.RowsAffected() == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "task.deleted", emailFromContext(c), gin.H{"id": taskID, "title": title, "project_id": projectID}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
 		return
 	}
+	s.wakeEvents()
 
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
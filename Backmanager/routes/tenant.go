@@ -11,13 +11,37 @@ import (
 )
 
 const tenantCtxKey = "tenant_id"
+const tenantSlugCtxKey = "tenant_slug"
 const userCtxKey = "user_id"
 const roleCtxKey = "role"
 const emailCtxKey = "email"
+const sessionCtxKey = "session_id"
+const accountCtxKey = "account_id"
+
+// mfaEnrollmentPaths stay reachable while a user still has to enroll in MFA
+// under their tenant's policy.
+var mfaEnrollmentPaths = map[string]struct{}{
+	"/api/v1/auth/logout":     {},
+	"/api/v1/auth/mfa":        {},
+	"/api/v1/auth/mfa/enroll": {},
+	"/api/v1/auth/mfa/verify": {},
+}
+
+// queryTokenPaths accept the access token as ?access_token= because browser
+// EventSource cannot send an Authorization header. Keep this list short: URLs
+// end up in proxy logs.
+var queryTokenPaths = map[string]struct{}{
+	"/api/v1/notifications/stream": {},
+}
 
 func AuthMiddleware(secret []byte, issuer string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" {
+			if _, ok := queryTokenPaths[c.FullPath()]; ok && c.Query("access_token") != "" {
+				authHeader = "Bearer " + c.Query("access_token")
+			}
+		}
 		if authHeader == "" {
 			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 				"error": "missing authorization header",
@@ -50,35 +74,54 @@ func AuthMiddleware(secret []byte, issuer string) gin.HandlerFunc {
 			return
 		}
 
-		tenantID, _ := claims["tenant_id"].(string)
-		if tenantID == "" {
+		rawTenantID, _ := claims["tenant_id"].(float64)
+		tenantID := int64(rawTenantID)
+		tenantSlug, _ := claims["tenant_slug"].(string)
+		if tenantID <= 0 || tenantSlug == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "tenant claim missing"})
 			return
 		}
 
+		sessionID, _ := claims["sid"].(string)
+		if sessionID == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session claim missing"})
+			return
+		}
+
 		userID, _ := claims["sub"].(string)
 		email, _ := claims["email"].(string)
 		role, _ := claims["role"].(string)
 		if role == "" {
-			role = "org_admin"
+			role = RoleMember
 		}
 		c.Set(tenantCtxKey, tenantID)
+		c.Set(tenantSlugCtxKey, tenantSlug)
 		c.Set(userCtxKey, userID)
 		c.Set(emailCtxKey, email)
 		c.Set(roleCtxKey, role)
+		c.Set(sessionCtxKey, sessionID)
 		c.Next()
 	}
 }
 
-func tenantFromContext(c *gin.Context) string {
+func tenantFromContext(c *gin.Context) int64 {
 	v, ok := c.Get(tenantCtxKey)
 	if !ok {
-		return ""
+		return 0
 	}
-	id, _ := v.(string)
+	id, _ := v.(int64)
 	return id
 }
 
+func tenantSlugFromContext(c *gin.Context) string {
+	v, ok := c.Get(tenantSlugCtxKey)
+	if !ok {
+		return ""
+	}
+	slug, _ := v.(string)
+	return slug
+}
+
 func roleFromContext(c *gin.Context) string {
 	v, ok := c.Get(roleCtxKey)
 	if !ok {
@@ -90,7 +133,7 @@ func roleFromContext(c *gin.Context) string {
 
 func RequireSystemAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if roleFromContext(c) != "system_admin" {
+		if roleFromContext(c) != RoleSystemAdmin {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "system admin access required"})
 			return
 		}
@@ -107,22 +150,59 @@ func emailFromContext(c *gin.Context) string {
 	return email
 }
 
+// accountFromContext returns the users.id row key loaded by RequireActiveUser.
+func accountFromContext(c *gin.Context) int64 {
+	v, ok := c.Get(accountCtxKey)
+	if !ok {
+		return 0
+	}
+	id, _ := v.(int64)
+	return id
+}
+
+func sessionFromContext(c *gin.Context) string {
+	v, ok := c.Get(sessionCtxKey)
+	if !ok {
+		return ""
+	}
+	id, _ := v.(string)
+	return id
+}
+
 func RequireActiveUser(s *Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tenant := strings.TrimSpace(tenantFromContext(c))
+		tenant := tenantFromContext(c)
 		email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
-		if tenant == "" || email == "" {
+		sessionID := strings.TrimSpace(sessionFromContext(c))
+		if tenant <= 0 || email == "" || sessionID == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing user context"})
 			return
 		}
+		var accountID int64
 		var blocked bool
+		var sessionActive bool
+		var role string
+		var mfaEnrollmentRequired bool
 		err := s.DB.QueryRow(c.Request.Context(), `
-			SELECT COALESCE(u.blocked, false)
+			SELECT
+				u.id,
+				COALESCE(u.blocked, false),
+				COALESCE(u.role, 'member'),
+				(us.id IS NOT NULL AND us.revoked_at IS NULL AND us.expires_at > NOW()),
+				(
+					CASE t.mfa_policy
+						WHEN 'all' THEN true
+						WHEN 'admins' THEN u.role IN ('owner', 'org_admin', 'system_admin')
+						ELSE false
+					END
+					AND NOT EXISTS (SELECT 1 FROM user_mfa m WHERE m.user_id = u.id AND m.enabled_at IS NOT NULL)
+				)
 			FROM users u
 			JOIN tenants t ON t.id = u.tenant_id
-			WHERE t.slug = $1 AND lower(u.email) = lower($2)
+			LEFT JOIN user_sessions us ON us.public_id = $3 AND us.user_id = u.id
+			WHERE u.tenant_id = $1 AND lower(u.email) = lower($2)
 			LIMIT 1
-		`, tenant, email).Scan(&blocked)
+		`, tenant, email, sessionID).Scan(&accountID, &blocked, &role, &sessionActive, &mfaEnrollmentRequired)
 		if errors.Is(err, pgx.ErrNoRows) {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "user not found"})
 			return
@@ -135,6 +215,21 @@ func RequireActiveUser(s *Service) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account access is blocked"})
 			return
 		}
+		if !sessionActive {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session expired or revoked"})
+			return
+		}
+		if mfaEnrollmentRequired {
+			if _, ok := mfaEnrollmentPaths[c.FullPath()]; !ok {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "two-factor enrollment required", "mfa_enrollment_required": true})
+				return
+			}
+		}
+		// The token's role claim can be stale after a role change; the stored
+		// role is authoritative for permission checks.
+		c.Set(roleCtxKey, role)
+		c.Set(accountCtxKey, accountID)
+		s.touchSession(c.Request.Context(), sessionID)
 		c.Next()
 	}
 }
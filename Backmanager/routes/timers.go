@@ -0,0 +1,442 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultTimerIdleLimit is how long a timer may go without a start or
+// heartbeat before the stale_timers job stops it.
+const defaultTimerIdleLimit = 12 * time.Hour
+
+type Timer struct {
+	ID             int64      `json:"id"`
+	TenantID       int64      `json:"tenant_id"`
+	UserEmail      string     `json:"user_email"`
+	ProjectID      *int64     `json:"project_id,omitempty"`
+	TaskID         *int64     `json:"task_id,omitempty"`
+	Billable       bool       `json:"billable"`
+	Notes          string     `json:"notes"`
+	StartedAt      time.Time  `json:"started_at"`
+	LastActivityAt time.Time  `json:"last_activity_at"`
+	StoppedAt      *time.Time `json:"stopped_at,omitempty"`
+	AutoStopped    bool       `json:"auto_stopped"`
+	ElapsedSeconds int64      `json:"elapsed_seconds"`
+}
+
+type startTimerRequest struct {
+	ProjectID *int64 `json:"project_id"`
+	TaskID    *int64 `json:"task_id"`
+	Billable  *bool  `json:"billable"`
+	Notes     string `json:"notes"`
+}
+
+type stopTimerRequest struct {
+	Notes *string `json:"notes"`
+}
+
+// timerStopResult is the outcome of stopping a timer: the timesheet rows it
+// produced, the local days dropped because their week is already submitted and
+// the hour caps the rows were cut down to fit.
+type timerStopResult struct {
+	Timer       Timer             `json:"timer"`
+	Entries     []TimesheetEntry  `json:"entries"`
+	SkippedDays []string          `json:"skipped_days"`
+	Clipped     []policyViolation `json:"clipped"`
+}
+
+// timerSegment is the part of a timer that falls on one local calendar day,
+// from Start to End in the user's location.
+type timerSegment struct {
+	Day   time.Time
+	Start time.Time
+	End   time.Time
+	Hours float64
+}
+
+// clockTimes returns the segment's local HH:MM start and end, with 24:00 for
+// a segment running up to midnight. Both are nil when the segment does not
+// span a full clock minute.
+func (seg timerSegment) clockTimes() (*string, *string) {
+	start, end := seg.Start.Format("15:04"), seg.End.Format("15:04")
+	if seg.End.Day() != seg.Start.Day() {
+		end = "24:00"
+	}
+	if start >= end {
+		return nil, nil
+	}
+	return &start, &end
+}
+
+// clipTimerSegment cuts seg down so the day and week it falls in stay within
+// the policy's hour caps, given the hours already logged there. It returns the
+// caps that were exceeded; the clipped segment may be left with no hours.
+func clipTimerSegment(policy timesheetPolicy, seg timerSegment, dayHours, weekHours float64) (timerSegment, []policyViolation) {
+	caps := timesheetPolicy{MaxHoursPerDay: policy.MaxHoursPerDay, MaxHoursPerWeek: policy.MaxHoursPerWeek}
+	violations := evaluateTimesheetPolicy(caps, timesheetInput{WorkDate: seg.Day, Hours: seg.Hours}, timesheetPolicyFacts{DayHours: dayHours, WeekHours: weekHours})
+	allowed := seg.Hours
+	if policy.MaxHoursPerDay != nil {
+		allowed = math.Min(allowed, roundHours(*policy.MaxHoursPerDay-dayHours))
+	}
+	if policy.MaxHoursPerWeek != nil {
+		allowed = math.Min(allowed, roundHours(*policy.MaxHoursPerWeek-weekHours))
+	}
+	if allowed < seg.Hours {
+		allowed = math.Max(allowed, 0)
+		seg.Hours = allowed
+		seg.End = seg.Start.Add(time.Duration(allowed * float64(time.Hour)))
+	}
+	return seg, violations
+}
+
+const timerColumns = `id, tenant_id, user_email, project_id, task_id, billable, notes, started_at, last_activity_at, stopped_at, auto_stopped`
+
+func scanTimer(row pgx.Row, t *Timer) error {
+	if err := row.Scan(&t.ID, &t.TenantID, &t.UserEmail, &t.ProjectID, &t.TaskID, &t.Billable, &t.Notes, &t.StartedAt, &t.LastActivityAt, &t.StoppedAt, &t.AutoStopped); err != nil {
+		return err
+	}
+	end := time.Now()
+	if t.StoppedAt != nil {
+		end = *t.StoppedAt
+	}
+	t.ElapsedSeconds = int64(end.Sub(t.StartedAt).Seconds())
+	return nil
+}
+
+// splitTimerByDay cuts [start, stop) at local midnights in loc. Hours are
+// rounded to two decimals and days that round to zero are dropped.
+func splitTimerByDay(start, stop time.Time, loc *time.Location) []timerSegment {
+	out := make([]timerSegment, 0, 1)
+	start, stop = start.In(loc), stop.In(loc)
+	for cursor := start; cursor.Before(stop); {
+		day := time.Date(cursor.Year(), cursor.Month(), cursor.Day(), 0, 0, 0, 0, loc)
+		next := day.AddDate(0, 0, 1)
+		end := stop
+		if next.Before(stop) {
+			end = next
+		}
+		hours := math.Round(end.Sub(cursor).Hours()*100) / 100
+		if hours > 0 {
+			out = append(out, timerSegment{Day: time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), Start: cursor, End: end, Hours: hours})
+		}
+		cursor = end
+	}
+	return out
+}
+
+func (s *Service) userLocation(ctx context.Context, tenantID int64, email string) *time.Location {
+	var timezone string
+	_ = s.DB.QueryRow(ctx, `
+		SELECT COALESCE(timezone, '')
+		FROM user_settings
+		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
+		LIMIT 1
+	`, tenantID, email).Scan(&timezone)
+	return loadLocationOrUTC(timezone)
+}
+
+// stopTimer ends a locked, running timer at stopAt and writes its per-day
+// timesheet rows inside tx, clipped to the tenant's hour caps.
+func (s *Service) stopTimer(ctx context.Context, tx pgx.Tx, timer Timer, stopAt time.Time, auto bool, actor string) (timerStopResult, error) {
+	result := timerStopResult{Entries: make([]TimesheetEntry, 0), SkippedDays: make([]string, 0), Clipped: make([]policyViolation, 0)}
+	if stopAt.Before(timer.StartedAt) {
+		stopAt = timer.StartedAt
+	}
+	policy, err := s.loadTimesheetPolicy(ctx, timer.TenantID)
+	if err != nil {
+		return result, err
+	}
+	if err := lockTimesheetAuthor(ctx, tx, timer.TenantID, timer.UserEmail); err != nil {
+		return result, err
+	}
+	loc := s.userLocation(ctx, timer.TenantID, timer.UserEmail)
+	for _, segment := range splitTimerByDay(timer.StartedAt, stopAt, loc) {
+		closed, err := timesheetWeekClosed(ctx, tx, timer.TenantID, timer.UserEmail, segment.Day)
+		if err != nil {
+			return result, err
+		}
+		if closed {
+			result.SkippedDays = append(result.SkippedDays, segment.Day.Format("2006-01-02"))
+			continue
+		}
+		if policy.MaxHoursPerDay != nil || policy.MaxHoursPerWeek != nil {
+			dayHours, weekHours, err := s.timesheetHoursAround(ctx, tx, timer.TenantID, timer.UserEmail, 0, segment.Day)
+			if err != nil {
+				return result, err
+			}
+			var violations []policyViolation
+			segment, violations = clipTimerSegment(policy, segment, dayHours, weekHours)
+			result.Clipped = append(result.Clipped, violations...)
+			if segment.Hours <= 0 {
+				continue
+			}
+		}
+		startTime, endTime := segment.clockTimes()
+		var item TimesheetEntry
+		if err := scanTimesheet(tx.QueryRow(ctx, `
+			INSERT INTO timesheets (tenant_id, project_id, task_id, work_date, hours, billable, notes, created_by_email, timer_id, start_time, end_time)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::time, $11::time)
+			RETURNING `+timesheetReturning+`
+		`, timer.TenantID, timer.ProjectID, timer.TaskID, segment.Day, segment.Hours, timer.Billable, timer.Notes, timer.UserEmail, timer.ID, startTime, endTime), &item); err != nil {
+			return result, err
+		}
+		fillTimesheetNames(ctx, tx, &item)
+		if err := recordEvent(ctx, tx, timer.TenantID, "timesheet.created", actor, item); err != nil {
+			return result, err
+		}
+		result.Entries = append(result.Entries, item)
+	}
+
+	if err := scanTimer(tx.QueryRow(ctx, `
+		UPDATE timers
+		SET stopped_at = $2, auto_stopped = $3, notes = $4
+		WHERE id = $1
+		RETURNING `+timerColumns+`
+	`, timer.ID, stopAt, auto, timer.Notes), &result.Timer); err != nil {
+		return result, err
+	}
+	if err := recordEvent(ctx, tx, timer.TenantID, "timer.stopped", actor, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *Service) CurrentTimer(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	var timer Timer
+	err := scanTimer(s.DB.QueryRow(c.Request.Context(), `
+		SELECT `+timerColumns+`
+		FROM timers
+		WHERE tenant_id = $1 AND lower(user_email) = $2 AND stopped_at IS NULL
+	`, tenantID, email), &timer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusOK, gin.H{"timer": nil})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"timer": timer})
+}
+
+func (s *Service) StartTimer(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || email == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
+		return
+	}
+	var req startTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if req.ProjectID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)
+		`, *req.ProjectID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
+			return
+		}
+	}
+	if req.TaskID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2)
+		`, *req.TaskID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "task not found for this tenant"})
+			return
+		}
+	}
+	billable := true
+	if req.Billable != nil {
+		billable = *req.Billable
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var timer Timer
+	err = scanTimer(tx.QueryRow(c.Request.Context(), `
+		INSERT INTO timers (tenant_id, user_email, project_id, task_id, billable, notes, started_at, last_activity_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING `+timerColumns+`
+	`, tenantID, email, req.ProjectID, req.TaskID, billable, strings.TrimSpace(req.Notes)), &timer)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		c.JSON(http.StatusConflict, gin.H{"error": "a timer is already running"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timer.started", email, timer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, timer)
+}
+
+// HeartbeatTimer records activity on the caller's running timer so the
+// stale_timers job leaves it alone.
+func (s *Service) HeartbeatTimer(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	var timer Timer
+	err := scanTimer(s.DB.QueryRow(c.Request.Context(), `
+		UPDATE timers
+		SET last_activity_at = NOW()
+		WHERE tenant_id = $1 AND lower(user_email) = $2 AND stopped_at IS NULL
+		RETURNING `+timerColumns+`
+	`, tenantID, email), &timer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no running timer"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	c.JSON(http.StatusOK, timer)
+}
+
+func (s *Service) StopTimer(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	email := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	var req stopTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var timer Timer
+	err = scanTimer(tx.QueryRow(c.Request.Context(), `
+		SELECT `+timerColumns+`
+		FROM timers
+		WHERE tenant_id = $1 AND lower(user_email) = $2 AND stopped_at IS NULL
+		FOR UPDATE
+	`, tenantID, email), &timer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no running timer"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if req.Notes != nil {
+		timer.Notes = strings.TrimSpace(*req.Notes)
+	}
+	result, err := s.stopTimer(c.Request.Context(), tx, timer, time.Now(), false, email)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stop timer"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, result)
+}
+
+// runStaleTimerJob stops timers idle for longer than TimerIdleLimit. The stop
+// time is when the limit was crossed, not now, so a forgotten timer books at
+// most TimerIdleLimit past its last activity.
+func (s *Service) runStaleTimerJob(ctx context.Context, now time.Time) (int, error) {
+	limit := s.TimerIdleLimit
+	if limit <= 0 {
+		limit = defaultTimerIdleLimit
+	}
+	rows, err := s.DB.Query(ctx, `
+		SELECT id
+		FROM timers
+		WHERE stopped_at IS NULL AND last_activity_at < $1
+		ORDER BY last_activity_at
+		LIMIT 200
+	`, now.Add(-limit))
+	if err != nil {
+		return 0, err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, err
+	}
+
+	stopped := 0
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			return stopped, ctx.Err()
+		}
+		ok, err := s.autoStopTimer(ctx, id, limit)
+		if err != nil {
+			log.Printf("stale_timers: timer %d: %v", id, err)
+			continue
+		}
+		if ok {
+			stopped++
+		}
+	}
+	if stopped > 0 {
+		s.wakeEvents()
+	}
+	return stopped, nil
+}
+
+func (s *Service) autoStopTimer(ctx context.Context, id int64, limit time.Duration) (bool, error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var timer Timer
+	err = scanTimer(tx.QueryRow(ctx, `
+		SELECT `+timerColumns+`
+		FROM timers
+		WHERE id = $1 AND stopped_at IS NULL
+		FOR UPDATE SKIP LOCKED
+	`, id), &timer)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.stopTimer(ctx, tx, timer, timer.LastActivityAt.Add(limit), true, "system"); err != nil {
+		return false, err
+	}
+	return true, tx.Commit(ctx)
+}
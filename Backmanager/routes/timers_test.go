@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitTimerByDay(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	at := func(day, clock string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04:05", day+" "+clock, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	type seg struct {
+		day, start, end string
+		hours           float64
+	}
+	cases := []struct {
+		name        string
+		start, stop time.Time
+		want        []seg
+	}{
+		{"within a day", at("2026-03-10", "09:00:00"), at("2026-03-10", "10:30:00"), []seg{
+			{"2026-03-10", "09:00", "10:30", 1.5},
+		}},
+		{"across local midnight", at("2026-03-10", "22:30:00"), at("2026-03-11", "01:15:00"), []seg{
+			{"2026-03-10", "22:30", "00:00", 1.5},
+			{"2026-03-11", "00:00", "01:15", 1.25},
+		}},
+		// Clocks skip 02:00-03:00 on March 8, so that day's midnight to 04:00 is three hours.
+		{"across spring forward", at("2026-03-07", "23:00:00"), at("2026-03-08", "04:00:00"), []seg{
+			{"2026-03-07", "23:00", "00:00", 1},
+			{"2026-03-08", "00:00", "04:00", 3},
+		}},
+		// Clocks repeat 01:00-02:00 on November 1, so midnight to 03:00 is four hours.
+		{"across fall back", at("2026-10-31", "23:00:00"), at("2026-11-01", "03:00:00"), []seg{
+			{"2026-10-31", "23:00", "00:00", 1},
+			{"2026-11-01", "00:00", "03:00", 4},
+		}},
+		{"longer than a day", at("2026-03-10", "18:00:00"), at("2026-03-12", "06:00:00"), []seg{
+			{"2026-03-10", "18:00", "00:00", 6},
+			{"2026-03-11", "00:00", "00:00", 24},
+			{"2026-03-12", "00:00", "06:00", 6},
+		}},
+		{"seconds past midnight are dropped", at("2026-03-10", "23:00:00"), at("2026-03-11", "00:00:10"), []seg{
+			{"2026-03-10", "23:00", "00:00", 1},
+		}},
+		{"empty", at("2026-03-10", "09:00:00"), at("2026-03-10", "09:00:00"), nil},
+	}
+	for _, tc := range cases {
+		// The stored timestamps are UTC; the split must still follow loc.
+		got := splitTimerByDay(tc.start.UTC(), tc.stop.UTC(), loc)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %d segments %+v, want %d", tc.name, len(got), got, len(tc.want))
+			continue
+		}
+		for i, w := range tc.want {
+			g := got[i]
+			if g.Day.Format("2006-01-02") != w.day || g.Day.Location() != time.UTC ||
+				g.Start.Format("15:04") != w.start || g.End.Format("15:04") != w.end || g.Hours != w.hours {
+				t.Errorf("%s: segment %d = %s %s-%s %vh, want %+v", tc.name, i, g.Day.Format("2006-01-02"), g.Start.Format("15:04"), g.End.Format("15:04"), g.Hours, w)
+			}
+		}
+		for i := range got {
+			if (i == 0 && !got[i].Start.Equal(tc.start)) || (i > 0 && !got[i].Start.Equal(got[i-1].End)) {
+				t.Errorf("%s: segment %d does not continue from the previous one", tc.name, i)
+			}
+		}
+	}
+}
+
+func TestTimerSegmentClockTimes(t *testing.T) {
+	loc := time.FixedZone("test", 2*60*60)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, loc)
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       string
+	}{
+		{"within a day", day.Add(9*time.Hour + 15*time.Minute + 40*time.Second), day.Add(11*time.Hour + 5*time.Second), "09:15-11:00"},
+		{"up to midnight", day.Add(22 * time.Hour), day.AddDate(0, 0, 1), "22:00-24:00"},
+		{"from midnight", day, day.Add(90 * time.Minute), "00:00-01:30"},
+		{"under a minute", day.Add(8*time.Hour + 10*time.Second), day.Add(8*time.Hour + 50*time.Second), ""},
+	}
+	for _, tc := range cases {
+		start, end := timerSegment{Start: tc.start, End: tc.end}.clockTimes()
+		got := ""
+		if start != nil {
+			got = *start + "-" + *end
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestClipTimerSegment(t *testing.T) {
+	start := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	seg := timerSegment{Day: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), Start: start, End: start.Add(5 * time.Hour), Hours: 5}
+	policy := timesheetPolicy{MaxHoursPerDay: floatPtr(8), MaxHoursPerWeek: floatPtr(40)}
+
+	cases := []struct {
+		name                string
+		dayHours, weekHours float64
+		wantHours           float64
+		wantRules           []string
+	}{
+		{"within caps", 2, 20, 5, nil},
+		{"daily cap", 6, 20, 2, []string{"max_hours_per_day"}},
+		{"weekly cap", 0, 38.5, 1.5, []string{"max_hours_per_week"}},
+		{"both caps, tighter wins", 7, 39.5, 0.5, []string{"max_hours_per_day", "max_hours_per_week"}},
+		{"day already full", 8, 20, 0, []string{"max_hours_per_day"}},
+		{"day already over", 9, 20, 0, []string{"max_hours_per_day"}},
+	}
+	for _, tc := range cases {
+		got, violations := clipTimerSegment(policy, seg, tc.dayHours, tc.weekHours)
+		if got.Hours != tc.wantHours {
+			t.Errorf("%s: hours %v, want %v", tc.name, got.Hours, tc.wantHours)
+		}
+		if want := start.Add(time.Duration(tc.wantHours * float64(time.Hour))); !got.End.Equal(want) {
+			t.Errorf("%s: end %v, want %v", tc.name, got.End, want)
+		}
+		if len(violations) != len(tc.wantRules) {
+			t.Fatalf("%s: violations %+v, want rules %v", tc.name, violations, tc.wantRules)
+		}
+		for i, rule := range tc.wantRules {
+			if violations[i].Rule != rule {
+				t.Errorf("%s: violation %d is %q, want %q", tc.name, i, violations[i].Rule, rule)
+			}
+		}
+	}
+
+	if got, violations := clipTimerSegment(defaultTimesheetPolicy(), seg, 20, 100); got.Hours != 5 || len(violations) != 0 {
+		t.Errorf("no caps: got %v hours and %+v", got.Hours, violations)
+	}
+}
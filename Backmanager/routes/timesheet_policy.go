@@ -0,0 +1,322 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+// timesheetPolicy is a tenant's validation policy for timesheet entries. Nil
+// limits are not enforced.
+type timesheetPolicy struct {
+	MaxHoursPerDay       *float64   `json:"max_hours_per_day"`
+	MaxHoursPerWeek      *float64   `json:"max_hours_per_week"`
+	MaxFutureDays        *int       `json:"max_future_days"`
+	EnforceProjectWindow bool       `json:"enforce_project_window"`
+	BlockClosedProjects  bool       `json:"block_closed_projects"`
+	RequireClockTimes    bool       `json:"require_clock_times"`
+	PreventOverlaps      bool       `json:"prevent_overlaps"`
+	UpdatedByEmail       string     `json:"updated_by_email,omitempty"`
+	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
+}
+
+type updateTimesheetPolicyRequest struct {
+	MaxHoursPerDay       *float64 `json:"max_hours_per_day" binding:"omitempty,gt=0,lte=24"`
+	MaxHoursPerWeek      *float64 `json:"max_hours_per_week" binding:"omitempty,gt=0,lte=168"`
+	MaxFutureDays        *int     `json:"max_future_days" binding:"omitempty,gte=0,lte=3650"`
+	EnforceProjectWindow bool     `json:"enforce_project_window"`
+	BlockClosedProjects  bool     `json:"block_closed_projects"`
+	RequireClockTimes    bool     `json:"require_clock_times"`
+	PreventOverlaps      bool     `json:"prevent_overlaps"`
+}
+
+// policyViolation is one failed rule; every violation of an entry is returned
+// together so the client can show them all at once.
+type policyViolation struct {
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+	Limit    *float64 `json:"limit,omitempty"`
+	Actual   *float64 `json:"actual,omitempty"`
+	EntryIDs []int64  `json:"entry_ids,omitempty"`
+}
+
+// timesheetPolicyFacts is what the policy is evaluated against, gathered from
+// the database for one entry.
+type timesheetPolicyFacts struct {
+	Today         time.Time
+	HasProject    bool
+	ProjectStart  *time.Time
+	ProjectDue    *time.Time
+	ProjectClosed bool
+	DayHours      float64
+	WeekHours     float64
+	Overlaps      []int64
+}
+
+const timesheetPolicyColumns = `max_hours_per_day::float8, max_hours_per_week::float8, max_future_days, enforce_project_window,
+	block_closed_projects, require_clock_times, prevent_overlaps, updated_by_email, updated_at`
+
+func defaultTimesheetPolicy() timesheetPolicy {
+	return timesheetPolicy{PreventOverlaps: true}
+}
+
+func scanTimesheetPolicy(row pgx.Row, p *timesheetPolicy) error {
+	return row.Scan(&p.MaxHoursPerDay, &p.MaxHoursPerWeek, &p.MaxFutureDays, &p.EnforceProjectWindow,
+		&p.BlockClosedProjects, &p.RequireClockTimes, &p.PreventOverlaps, &p.UpdatedByEmail, &p.UpdatedAt)
+}
+
+func (s *Service) loadTimesheetPolicy(ctx context.Context, tenantID int64) (timesheetPolicy, error) {
+	policy := defaultTimesheetPolicy()
+	err := scanTimesheetPolicy(s.DB.QueryRow(ctx, `
+		SELECT `+timesheetPolicyColumns+`
+		FROM timesheet_policies
+		WHERE tenant_id = $1
+	`, tenantID), &policy)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultTimesheetPolicy(), nil
+	}
+	return policy, err
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+// evaluateTimesheetPolicy returns every rule of policy that in breaks.
+func evaluateTimesheetPolicy(policy timesheetPolicy, in timesheetInput, facts timesheetPolicyFacts) []policyViolation {
+	violations := make([]policyViolation, 0)
+	day := in.WorkDate.Format("2006-01-02")
+
+	if policy.MaxFutureDays != nil {
+		latest := facts.Today.AddDate(0, 0, *policy.MaxFutureDays)
+		if in.WorkDate.After(latest) {
+			violations = append(violations, policyViolation{
+				Rule:    "future_date",
+				Message: fmt.Sprintf("entries may be at most %d day(s) in the future (latest %s)", *policy.MaxFutureDays, latest.Format("2006-01-02")),
+			})
+		}
+	}
+	if policy.EnforceProjectWindow && facts.HasProject {
+		if facts.ProjectStart != nil && in.WorkDate.Before(*facts.ProjectStart) {
+			violations = append(violations, policyViolation{
+				Rule:    "project_window",
+				Message: fmt.Sprintf("%s is before the project starts on %s", day, facts.ProjectStart.Format("2006-01-02")),
+			})
+		}
+		if facts.ProjectDue != nil && in.WorkDate.After(*facts.ProjectDue) {
+			violations = append(violations, policyViolation{
+				Rule:    "project_window",
+				Message: fmt.Sprintf("%s is after the project is due on %s", day, facts.ProjectDue.Format("2006-01-02")),
+			})
+		}
+	}
+	if policy.BlockClosedProjects && facts.ProjectClosed {
+		violations = append(violations, policyViolation{
+			Rule:    "project_closed",
+			Message: "time cannot be logged against a completed project",
+		})
+	}
+	if policy.MaxHoursPerDay != nil {
+		total := roundHours(facts.DayHours + in.Hours)
+		if total > *policy.MaxHoursPerDay {
+			violations = append(violations, policyViolation{
+				Rule:    "max_hours_per_day",
+				Message: fmt.Sprintf("%.2f hours on %s exceeds the daily limit of %.2f", total, day, *policy.MaxHoursPerDay),
+				Limit:   floatPtr(*policy.MaxHoursPerDay),
+				Actual:  floatPtr(total),
+			})
+		}
+	}
+	if policy.MaxHoursPerWeek != nil {
+		total := roundHours(facts.WeekHours + in.Hours)
+		if total > *policy.MaxHoursPerWeek {
+			violations = append(violations, policyViolation{
+				Rule:    "max_hours_per_week",
+				Message: fmt.Sprintf("%.2f hours this week exceeds the weekly limit of %.2f", total, *policy.MaxHoursPerWeek),
+				Limit:   floatPtr(*policy.MaxHoursPerWeek),
+				Actual:  floatPtr(total),
+			})
+		}
+	}
+	if policy.RequireClockTimes && in.StartTime == nil {
+		violations = append(violations, policyViolation{
+			Rule:    "clock_times_required",
+			Message: "start_time and end_time are required",
+		})
+	}
+	if policy.PreventOverlaps && len(facts.Overlaps) > 0 {
+		violations = append(violations, policyViolation{
+			Rule:     "overlap",
+			Message:  fmt.Sprintf("%s-%s overlaps other entries on %s", *in.StartTime, *in.EndTime, day),
+			EntryIDs: facts.Overlaps,
+		})
+	}
+	return violations
+}
+
+func roundHours(h float64) float64 {
+	return float64(int64(h*100+0.5)) / 100
+}
+
+// lockTimesheetAuthor serialises writes to author's timesheet until tx ends,
+// so two concurrent entries cannot both pass the hour caps or overlap check.
+func lockTimesheetAuthor(ctx context.Context, tx pgx.Tx, tenantID int64, author string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended(lower($2), $1))`, tenantID, author)
+	return err
+}
+
+// timesheetHoursAround sums author's hours on day and in the week around it,
+// leaving out excludeID.
+func (s *Service) timesheetHoursAround(ctx context.Context, tx pgx.Tx, tenantID int64, author string, excludeID int64, day time.Time) (float64, float64, error) {
+	var dayHours, weekHours float64
+	from := weekStart(day, s.userWeekStartsOn(ctx, tenantID, author))
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(hours) FILTER (WHERE work_date = $4), 0)::float8,
+		       COALESCE(SUM(hours), 0)::float8
+		FROM timesheets
+		WHERE tenant_id = $1 AND lower(created_by_email) = lower($2) AND id <> $3
+		  AND work_date >= $5 AND work_date < $6
+	`, tenantID, author, excludeID, day, from, from.AddDate(0, 0, 7)).Scan(&dayHours, &weekHours)
+	return dayHours, weekHours, err
+}
+
+// checkTimesheetPolicy gathers the facts for an entry by author inside tx and
+// evaluates policy against them. The caller holds lockTimesheetAuthor.
+// excludeID is the entry being replaced, if any, so its old hours and times do
+// not count against the new ones.
+func (s *Service) checkTimesheetPolicy(ctx context.Context, tx pgx.Tx, tenantID int64, author string, excludeID int64, projectID *int64, in timesheetInput, policy timesheetPolicy) ([]policyViolation, error) {
+	var facts timesheetPolicyFacts
+	now := time.Now().In(s.userLocation(ctx, tenantID, author))
+	facts.Today = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	if projectID != nil && (policy.EnforceProjectWindow || policy.BlockClosedProjects) {
+		facts.HasProject = true
+		if err := tx.QueryRow(ctx, `
+			SELECT start_date, due_date, completed_at IS NOT NULL
+			FROM projects
+			WHERE id = $1 AND tenant_id = $2
+		`, *projectID, tenantID).Scan(&facts.ProjectStart, &facts.ProjectDue, &facts.ProjectClosed); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.MaxHoursPerDay != nil || policy.MaxHoursPerWeek != nil {
+		var err error
+		if facts.DayHours, facts.WeekHours, err = s.timesheetHoursAround(ctx, tx, tenantID, author, excludeID, in.WorkDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.PreventOverlaps && in.StartTime != nil {
+		rows, err := tx.Query(ctx, `
+			SELECT id
+			FROM timesheets
+			WHERE tenant_id = $1 AND lower(created_by_email) = lower($2) AND id <> $3
+			  AND work_date = $4 AND start_time < $6::time AND end_time > $5::time
+			ORDER BY start_time, id
+		`, tenantID, author, excludeID, in.WorkDate, *in.StartTime, *in.EndTime)
+		if err != nil {
+			return nil, err
+		}
+		facts.Overlaps, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return evaluateTimesheetPolicy(policy, in, facts), nil
+}
+
+// enforceTimesheetPolicy locks author's timesheet and checks in against the
+// tenant's policy inside tx, writing the error response itself when it
+// returns false.
+func (s *Service) enforceTimesheetPolicy(c *gin.Context, tx pgx.Tx, tenantID int64, author string, excludeID int64, projectID *int64, in timesheetInput) bool {
+	policy, err := s.loadTimesheetPolicy(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "policy lookup failed"})
+		return false
+	}
+	if err := lockTimesheetAuthor(c.Request.Context(), tx, tenantID, author); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "policy check failed"})
+		return false
+	}
+	violations, err := s.checkTimesheetPolicy(c.Request.Context(), tx, tenantID, author, excludeID, projectID, in, policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "policy check failed"})
+		return false
+	}
+	if len(violations) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timesheet entry violates the timesheet policy", "violations": violations})
+		return false
+	}
+	return true
+}
+
+// parseClockTime parses an HH:MM clock time. 24:00 is accepted as the end of
+// the day, which is how an entry running up to midnight is stored.
+func parseClockTime(v string) (time.Time, error) {
+	if v == "24:00" {
+		return time.Date(0, time.January, 2, 0, 0, 0, 0, time.UTC), nil
+	}
+	return time.Parse("15:04", v)
+}
+
+func formatClockTime(t time.Time) string {
+	if t.Day() > 1 {
+		return "24:00"
+	}
+	return t.Format("15:04")
+}
+
+func (s *Service) GetTimesheetPolicy(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	policy, err := s.loadTimesheetPolicy(c.Request.Context(), tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+func (s *Service) UpdateTimesheetPolicy(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	var req updateTimesheetPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if req.MaxHoursPerDay != nil && req.MaxHoursPerWeek != nil && *req.MaxHoursPerWeek < *req.MaxHoursPerDay {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_hours_per_week must not be below max_hours_per_day"})
+		return
+	}
+
+	var policy timesheetPolicy
+	err := scanTimesheetPolicy(s.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO timesheet_policies (tenant_id, max_hours_per_day, max_hours_per_week, max_future_days, enforce_project_window,
+			block_closed_projects, require_clock_times, prevent_overlaps, updated_by_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			max_hours_per_day = EXCLUDED.max_hours_per_day,
+			max_hours_per_week = EXCLUDED.max_hours_per_week,
+			max_future_days = EXCLUDED.max_future_days,
+			enforce_project_window = EXCLUDED.enforce_project_window,
+			block_closed_projects = EXCLUDED.block_closed_projects,
+			require_clock_times = EXCLUDED.require_clock_times,
+			prevent_overlaps = EXCLUDED.prevent_overlaps,
+			updated_by_email = EXCLUDED.updated_by_email,
+			updated_at = NOW()
+		RETURNING `+timesheetPolicyColumns+`
+	`, tenantID, req.MaxHoursPerDay, req.MaxHoursPerWeek, req.MaxFutureDays, req.EnforceProjectWindow,
+		req.BlockClosedProjects, req.RequireClockTimes, req.PreventOverlaps, strings.ToLower(strings.TrimSpace(emailFromContext(c)))), &policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save policy"})
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
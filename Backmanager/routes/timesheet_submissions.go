@@ -0,0 +1,373 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+type timesheetSubmission struct {
+	ID                int64      `json:"id"`
+	TenantID          int64      `json:"tenant_id"`
+	UserEmail         string     `json:"user_email"`
+	WeekStart         string     `json:"week_start"`
+	WeekEnd           string     `json:"week_end"`
+	Status            string     `json:"status"`
+	TotalHours        float64    `json:"total_hours"`
+	BillableHours     float64    `json:"billable_hours"`
+	EntryCount        int        `json:"entry_count"`
+	ApprovalRequestID *int64     `json:"approval_request_id,omitempty"`
+	SubmittedAt       time.Time  `json:"submitted_at"`
+	ApprovedAt        *time.Time `json:"approved_at,omitempty"`
+	ReopenedAt        *time.Time `json:"reopened_at,omitempty"`
+	ReopenedByEmail   string     `json:"reopened_by_email,omitempty"`
+}
+
+type submitTimesheetRequest struct {
+	WeekOf string `json:"week_of"`
+	Note   string `json:"note"`
+}
+
+const submissionColumns = `
+	ts.id, ts.tenant_id, ts.user_email, ts.week_start, ts.week_end, ts.status, ts.total_hours::float8, ts.billable_hours::float8,
+	(SELECT COUNT(*) FROM timesheets t WHERE t.submission_id = ts.id),
+	ts.approval_request_id, ts.submitted_at, ts.approved_at, ts.reopened_at, COALESCE(ts.reopened_by_email, '')`
+
+func scanSubmission(row pgx.Row, item *timesheetSubmission) error {
+	var weekStart, weekEnd time.Time
+	if err := row.Scan(
+		&item.ID, &item.TenantID, &item.UserEmail, &weekStart, &weekEnd, &item.Status, &item.TotalHours, &item.BillableHours,
+		&item.EntryCount, &item.ApprovalRequestID, &item.SubmittedAt, &item.ApprovedAt, &item.ReopenedAt, &item.ReopenedByEmail,
+	); err != nil {
+		return err
+	}
+	item.WeekStart = weekStart.Format("2006-01-02")
+	item.WeekEnd = weekEnd.Format("2006-01-02")
+	return nil
+}
+
+// weekStart returns the first day of the week containing day. Only "sunday"
+// changes the default Monday start, matching the settings page.
+func weekStart(day time.Time, weekStartsOn string) time.Time {
+	first := time.Monday
+	if strings.EqualFold(strings.TrimSpace(weekStartsOn), "sunday") {
+		first = time.Sunday
+	}
+	offset := (int(day.Weekday()) - int(first) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func (s *Service) userWeekStartsOn(ctx context.Context, tenantID int64, email string) string {
+	var weekStartsOn string
+	if err := s.DB.QueryRow(ctx, `
+		SELECT COALESCE(week_starts_on, 'monday')
+		FROM user_settings
+		WHERE tenant_id = $1 AND lower(user_email) = lower($2)
+		LIMIT 1
+	`, tenantID, email).Scan(&weekStartsOn); err != nil {
+		return "monday"
+	}
+	return weekStartsOn
+}
+
+// timesheetWeekClosed reports whether workDate falls in a week the user has
+// submitted and that has not been rejected or reopened since. Callers hold
+// lockTimesheetAuthor so a submission cannot land between the check and
+// their write.
+func timesheetWeekClosed(ctx context.Context, tx pgx.Tx, tenantID int64, email string, workDate time.Time) (bool, error) {
+	var closed bool
+	err := tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1
+			FROM timesheet_submissions
+			WHERE tenant_id = $1 AND lower(user_email) = lower($2) AND week_start <= $3 AND week_end >= $3
+			  AND status IN ('submitted', 'approved')
+		)
+	`, tenantID, email, workDate).Scan(&closed)
+	return closed, err
+}
+
+func (s *Service) ListTimesheetSubmissions(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	user := strings.ToLower(strings.TrimSpace(c.Query("user")))
+	if user == "me" {
+		user = strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	}
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT `+submissionColumns+`
+		FROM timesheet_submissions ts
+		WHERE ts.tenant_id = $1 AND ($2 = '' OR lower(ts.user_email) = $2)
+		ORDER BY ts.week_start DESC, ts.id DESC
+	`, tenantID, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+	items := make([]timesheetSubmission, 0)
+	for rows.Next() {
+		var item timesheetSubmission
+		if err := scanSubmission(rows, &item); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// SubmitTimesheetWeek moves the caller's draft entries for one week into a
+// submission and raises an approval request for it. The week is the one
+// containing week_of (default today), starting on the user's week_starts_on.
+func (s *Service) SubmitTimesheetWeek(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	requester := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	if tenantID <= 0 || requester == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing user context"})
+		return
+	}
+	var req submitTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	day := time.Now().UTC().Truncate(24 * time.Hour)
+	if strings.TrimSpace(req.WeekOf) != "" {
+		parsed, err := time.Parse("2006-01-02", strings.TrimSpace(req.WeekOf))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week_of must be YYYY-MM-DD"})
+			return
+		}
+		day = parsed
+	}
+	start := weekStart(day, s.userWeekStartsOn(c.Request.Context(), tenantID, requester))
+	end := start.AddDate(0, 0, 6)
+	routing := s.resolveApprovalRouting(c.Request.Context(), tenantID, requester)
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+	if err := lockTimesheetAuthor(c.Request.Context(), tx, tenantID, requester); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+
+	var overlapping bool
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT EXISTS(
+			SELECT 1
+			FROM timesheet_submissions
+			WHERE tenant_id = $1 AND lower(user_email) = $2 AND week_start <> $3 AND week_start <= $4 AND week_end >= $3
+			  AND status IN ('submitted', 'approved')
+		)
+	`, tenantID, requester, start, end).Scan(&overlapping); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	if overlapping {
+		c.JSON(http.StatusConflict, gin.H{"error": "this week overlaps an existing submission"})
+		return
+	}
+
+	// A rejected or reopened week is resubmitted in place; a submitted or
+	// approved one leaves the upsert without a row.
+	var submissionID int64
+	err = tx.QueryRow(c.Request.Context(), `
+		INSERT INTO timesheet_submissions (tenant_id, user_email, week_start, week_end, status, submitted_at)
+		VALUES ($1, $2, $3, $4, 'submitted', NOW())
+		ON CONFLICT (tenant_id, lower(user_email), week_start) DO UPDATE
+		SET status = 'submitted', week_end = EXCLUDED.week_end, submitted_at = NOW(), approved_at = NULL, updated_at = NOW()
+		WHERE timesheet_submissions.status IN ('rejected', 'reopened')
+		RETURNING id
+	`, tenantID, requester, start, end).Scan(&submissionID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "this week has already been submitted"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+
+	rows, err := tx.Query(c.Request.Context(), `
+		UPDATE timesheets
+		SET status = 'submitted', submission_id = $1, updated_at = NOW()
+		WHERE tenant_id = $2 AND lower(created_by_email) = $3 AND work_date BETWEEN $4 AND $5 AND status = 'draft'
+		RETURNING id
+	`, submissionID, tenantID, requester, start, end)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	timesheetIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if len(timesheetIDs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no draft timesheet entries for this week"})
+		return
+	}
+
+	title := fmt.Sprintf("Timesheet %s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	approval, err := insertApprovalRequest(c.Request.Context(), tx, tenantID, requester, nil, title, req.Note, routing)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	entries, billableHours, err := attachApprovalEntries(c.Request.Context(), tx, tenantID, approval.ID, timesheetIDs)
+	if errors.Is(err, errTimesheetAlreadyRequested) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	approval.BillableHours = billableHours
+	approval.Entries = entries
+	var totalHours float64
+	for _, entry := range entries {
+		totalHours += entry.Hours
+	}
+
+	var item timesheetSubmission
+	if err := scanSubmission(tx.QueryRow(c.Request.Context(), `
+		WITH updated AS (
+			UPDATE timesheet_submissions
+			SET total_hours = $3, billable_hours = $4, approval_request_id = $5
+			WHERE id = $1 AND tenant_id = $2
+			RETURNING *
+		)
+		SELECT `+submissionColumns+`
+		FROM updated ts
+	`, submissionID, tenantID, totalHours, billableHours, approval.ID), &item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	item.EntryCount = len(entries)
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "approval.requested", requester, approvalEvent{approvalRequestItem: approval, NotifyApprover: routing.NotifyApprover}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timesheet.submitted", requester, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// ReopenTimesheetSubmission unlocks an approved week so its author can edit
+// and resubmit it. Only an approver on the week's approval request, or a
+// timesheet manager, may reopen.
+func (s *Service) ReopenTimesheetSubmission(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	actor := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	submissionID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || submissionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid submission id"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	var status string
+	var approversRaw []byte
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT ts.status, COALESCE(ar.approver_emails, '[]'::jsonb)
+		FROM timesheet_submissions ts
+		LEFT JOIN approval_requests ar ON ar.id = ts.approval_request_id
+		WHERE ts.id = $1 AND ts.tenant_id = $2
+		FOR UPDATE OF ts
+	`, submissionID, tenantID).Scan(&status, &approversRaw); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "submission not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "submission lookup failed"})
+		return
+	}
+	isApprover := false
+	for _, email := range parseStringArrayJSON(approversRaw) {
+		if strings.EqualFold(strings.TrimSpace(email), actor) {
+			isApprover = true
+			break
+		}
+	}
+	if !isApprover && !hasPermission(c, PermTimesheetsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only an approver can reopen this submission"})
+		return
+	}
+	if status != "approved" {
+		c.JSON(http.StatusConflict, gin.H{"error": "only approved submissions can be reopened"})
+		return
+	}
+
+	// Reopened entries leave the approved request so they can be included
+	// in the next one.
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE approval_request_entries
+		SET active = false
+		WHERE tenant_id = $1 AND active AND timesheet_id IN (SELECT id FROM timesheets WHERE submission_id = $2)
+	`, tenantID, submissionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE timesheets
+		SET status = 'draft', updated_at = NOW()
+		WHERE tenant_id = $1 AND submission_id = $2
+	`, tenantID, submissionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	var item timesheetSubmission
+	if err := scanSubmission(tx.QueryRow(c.Request.Context(), `
+		WITH updated AS (
+			UPDATE timesheet_submissions
+			SET status = 'reopened', reopened_at = NOW(), reopened_by_email = $3, updated_at = NOW()
+			WHERE id = $1 AND tenant_id = $2
+			RETURNING *
+		)
+		SELECT `+submissionColumns+`
+		FROM updated ts
+	`, submissionID, tenantID, actor), &item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timesheet.reopened", actor, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, item)
+}
@@ -2,17 +2,19 @@ package routes
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type TimesheetEntry struct {
 	ID             int64     `json:"id"`
-	TenantID       string    `json:"tenant_id"`
+	TenantID       int64     `json:"tenant_id"`
 	ProjectID      *int64    `json:"project_id,omitempty"`
 	TaskID         *int64    `json:"task_id,omitempty"`
 	ProjectName    string    `json:"project_name,omitempty"`
@@ -22,6 +24,10 @@ type TimesheetEntry struct {
 	Billable       bool      `json:"billable"`
 	Notes          string    `json:"notes"`
 	CreatedByEmail string    `json:"created_by_email"`
+	Status         string    `json:"status"`
+	SubmissionID   *int64    `json:"submission_id,omitempty"`
+	StartTime      string    `json:"start_time,omitempty"`
+	EndTime        string    `json:"end_time,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -29,28 +35,114 @@ type createTimesheetRequest struct {
 	ProjectID *int64  `json:"project_id"`
 	TaskID    *int64  `json:"task_id"`
 	WorkDate  string  `json:"work_date" binding:"required"`
-	Hours     float64 `json:"hours" binding:"required,gt=0,lte=24"`
+	Hours     float64 `json:"hours" binding:"gte=0,lte=24"`
 	Billable  bool    `json:"billable"`
 	Notes     string  `json:"notes"`
+	// StartTime and EndTime are optional HH:MM clock times; when set, hours
+	// is derived from them.
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
 }
 
-func (s *Service) EnsureTimesheetsTable() error {
-	_, err := s.DB.Exec(context.Background(), `
-		CREATE TABLE IF NOT EXISTS timesheets (
-			id BIGSERIAL PRIMARY KEY,
-			tenant_id TEXT NOT NULL,
-			project_id BIGINT REFERENCES projects(id) ON DELETE SET NULL,
-			task_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL,
-			work_date DATE NOT NULL,
-			hours NUMERIC(5,2) NOT NULL,
-			billable BOOLEAN NOT NULL DEFAULT true,
-			notes TEXT NOT NULL DEFAULT '',
-			created_by_email TEXT NOT NULL,
-			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
-		);
-		CREATE INDEX IF NOT EXISTS idx_timesheets_tenant_date ON timesheets (tenant_id, work_date DESC);
-	`)
-	return err
+// timesheetInput is a validated create or update payload.
+type timesheetInput struct {
+	WorkDate  time.Time
+	Hours     float64
+	StartTime *string
+	EndTime   *string
+}
+
+const (
+	timesheetDraft  = "draft"
+	timesheetLocked = "locked"
+)
+
+const timesheetReturning = `id, tenant_id, project_id, task_id, work_date, hours::float8, billable, notes, created_by_email, status, submission_id,
+	COALESCE(to_char(start_time, 'HH24:MI'), ''), COALESCE(to_char(end_time, 'HH24:MI'), ''), created_at`
+
+func scanTimesheet(row pgx.Row, item *TimesheetEntry) error {
+	var workDate time.Time
+	if err := row.Scan(
+		&item.ID, &item.TenantID, &item.ProjectID, &item.TaskID, &workDate, &item.Hours, &item.Billable, &item.Notes,
+		&item.CreatedByEmail, &item.Status, &item.SubmissionID, &item.StartTime, &item.EndTime, &item.CreatedAt,
+	); err != nil {
+		return err
+	}
+	item.WorkDate = workDate.Format("2006-01-02")
+	return nil
+}
+
+// validateTimesheetRequest checks the payload shared by create and update and
+// writes the error response itself when it returns false. The timesheet policy
+// and whether the week is still open are checked later, inside the write's
+// transaction.
+func (s *Service) validateTimesheetRequest(c *gin.Context, tenantID int64, author string, req createTimesheetRequest) (timesheetInput, bool) {
+	var in timesheetInput
+	workDate, err := time.Parse("2006-01-02", strings.TrimSpace(req.WorkDate))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "work_date must be YYYY-MM-DD"})
+		return in, false
+	}
+	startTime, endTime := strings.TrimSpace(req.StartTime), strings.TrimSpace(req.EndTime)
+	if (startTime == "") != (endTime == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time and end_time must be set together"})
+		return in, false
+	}
+	if startTime != "" {
+		start, errStart := time.Parse("15:04", startTime)
+		end, errEnd := parseClockTime(endTime)
+		if errStart != nil || errEnd != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_time and end_time must be HH:MM"})
+			return in, false
+		}
+		if !end.After(start) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_time must be after start_time"})
+			return in, false
+		}
+		startTime, endTime = start.Format("15:04"), formatClockTime(end)
+		in.StartTime, in.EndTime = &startTime, &endTime
+		req.Hours = end.Sub(start).Hours()
+	}
+	if req.Hours <= 0 || req.Hours > 24 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 0 and 24"})
+		return in, false
+	}
+	hoursRounded, err := strconv.ParseFloat(strconv.FormatFloat(req.Hours, 'f', 2, 64), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hours"})
+		return in, false
+	}
+
+	if req.ProjectID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)
+		`, *req.ProjectID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
+			return in, false
+		}
+	}
+	if req.TaskID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2)
+		`, *req.TaskID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "task not found for this tenant"})
+			return in, false
+		}
+	}
+
+	in.WorkDate, in.Hours = workDate, hoursRounded
+	return in, true
+}
+
+func fillTimesheetNames(ctx context.Context, tx pgx.Tx, item *TimesheetEntry) {
+	if item.ProjectID != nil {
+		_ = tx.QueryRow(ctx, `SELECT COALESCE(name, '') FROM projects WHERE id = $1`, *item.ProjectID).Scan(&item.ProjectName)
+	}
+	if item.TaskID != nil {
+		_ = tx.QueryRow(ctx, `SELECT COALESCE(title, '') FROM tasks WHERE id = $1`, *item.TaskID).Scan(&item.TaskTitle)
+	}
 }
 
 func (s *Service) ListTimesheets(c *gin.Context) {
@@ -68,6 +160,10 @@ func (s *Service) ListTimesheets(c *gin.Context) {
 			ts.billable,
 			ts.notes,
 			ts.created_by_email,
+			ts.status,
+			ts.submission_id,
+			COALESCE(to_char(ts.start_time, 'HH24:MI'), ''),
+			COALESCE(to_char(ts.end_time, 'HH24:MI'), ''),
 			ts.created_at
 		FROM timesheets ts
 		LEFT JOIN projects p ON p.id = ts.project_id
@@ -100,6 +196,10 @@ func (s *Service) ListTimesheets(c *gin.Context) {
 			&item.Billable,
 			&item.Notes,
 			&item.CreatedByEmail,
+			&item.Status,
+			&item.SubmissionID,
+			&item.StartTime,
+			&item.EndTime,
 			&item.CreatedAt,
 		); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
@@ -138,70 +238,148 @@ func (s *Service) CreateTimesheet(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
-	workDate, err := time.Parse("2006-01-02", strings.TrimSpace(req.WorkDate))
+	createdBy := strings.TrimSpace(emailFromContext(c))
+	if createdBy == "" {
+		createdBy = "unknown"
+	}
+	in, ok := s.validateTimesheetRequest(c, tenantID, createdBy, req)
+	if !ok {
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "work_date must be YYYY-MM-DD"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
 		return
 	}
-	if req.Hours <= 0 || req.Hours > 24 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "hours must be between 0 and 24"})
+	defer tx.Rollback(c.Request.Context())
+	if !s.enforceTimesheetPolicy(c, tx, tenantID, createdBy, 0, req.ProjectID, in) {
 		return
 	}
-	hoursRounded, err := strconv.ParseFloat(strconv.FormatFloat(req.Hours, 'f', 2, 64), 64)
+	closed, err := timesheetWeekClosed(c.Request.Context(), tx, tenantID, createdBy, in.WorkDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hours"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "timesheet lookup failed"})
+		return
+	}
+	if closed {
+		c.JSON(http.StatusConflict, gin.H{"error": "this week has already been submitted"})
 		return
 	}
 
-	if req.ProjectID != nil {
-		var exists bool
-		if err := s.DB.QueryRow(c.Request.Context(), `
-			SELECT EXISTS(SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)
-		`, *req.ProjectID, tenantID).Scan(&exists); err != nil || !exists {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
-			return
-		}
+	var item TimesheetEntry
+	err = scanTimesheet(tx.QueryRow(c.Request.Context(), `
+		INSERT INTO timesheets (tenant_id, project_id, task_id, work_date, hours, billable, notes, created_by_email, start_time, end_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::time, $10::time)
+		RETURNING `+timesheetReturning+`
+	`, tenantID, req.ProjectID, req.TaskID, in.WorkDate, in.Hours, req.Billable, strings.TrimSpace(req.Notes), createdBy, in.StartTime, in.EndTime), &item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
 	}
-	if req.TaskID != nil {
-		var exists bool
-		if err := s.DB.QueryRow(c.Request.Context(), `
-			SELECT EXISTS(SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2)
-		`, *req.TaskID, tenantID).Scan(&exists); err != nil || !exists {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "task not found for this tenant"})
-			return
-		}
+	fillTimesheetNames(c.Request.Context(), tx, &item)
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timesheet.created", createdBy, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
 	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
 
-	createdBy := strings.TrimSpace(emailFromContext(c))
-	if createdBy == "" {
-		createdBy = "unknown"
+	c.JSON(http.StatusCreated, item)
+}
+
+func (s *Service) UpdateTimesheet(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	timesheetID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || timesheetID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timesheet id"})
+		return
+	}
+	var req createTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	actor := strings.ToLower(strings.TrimSpace(emailFromContext(c)))
+	in, ok := s.validateTimesheetRequest(c, tenantID, actor, req)
+	if !ok {
+		return
 	}
 
-	var item TimesheetEntry
-	var projectName, taskTitle string
-	var storedDate time.Time
-	err = s.DB.QueryRow(c.Request.Context(), `
-		INSERT INTO timesheets (tenant_id, project_id, task_id, work_date, hours, billable, notes, created_by_email)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, tenant_id, project_id, task_id, work_date, hours::float8, billable, notes, created_by_email, created_at
-	`, tenantID, req.ProjectID, req.TaskID, workDate, hoursRounded, req.Billable, strings.TrimSpace(req.Notes), createdBy).
-		Scan(&item.ID, &item.TenantID, &item.ProjectID, &item.TaskID, &storedDate, &item.Hours, &item.Billable, &item.Notes, &item.CreatedByEmail, &item.CreatedAt)
+	tx, err := s.DB.Begin(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
 		return
 	}
-	item.WorkDate = storedDate.Format("2006-01-02")
+	defer tx.Rollback(c.Request.Context())
 
-	if item.ProjectID != nil {
-		_ = s.DB.QueryRow(c.Request.Context(), `SELECT COALESCE(name, '') FROM projects WHERE id = $1`, *item.ProjectID).Scan(&projectName)
+	var createdBy, status string
+	if err := tx.QueryRow(c.Request.Context(), `
+		SELECT lower(created_by_email), status
+		FROM timesheets
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, timesheetID, tenantID).Scan(&createdBy, &status); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "timesheet not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "timesheet lookup failed"})
+		return
 	}
-	if item.TaskID != nil {
-		_ = s.DB.QueryRow(c.Request.Context(), `SELECT COALESCE(title, '') FROM tasks WHERE id = $1`, *item.TaskID).Scan(&taskTitle)
+	if createdBy != actor {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only edit your own timesheet entries"})
+		return
+	}
+	if status != timesheetDraft {
+		c.JSON(http.StatusConflict, gin.H{"error": timesheetNotEditable(status)})
+		return
+	}
+	if !s.enforceTimesheetPolicy(c, tx, tenantID, actor, timesheetID, req.ProjectID, in) {
+		return
+	}
+	closed, err := timesheetWeekClosed(c.Request.Context(), tx, tenantID, actor, in.WorkDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "timesheet lookup failed"})
+		return
+	}
+	if closed {
+		c.JSON(http.StatusConflict, gin.H{"error": "this week has already been submitted"})
+		return
 	}
-	item.ProjectName = projectName
-	item.TaskTitle = taskTitle
 
-	c.JSON(http.StatusCreated, item)
+	var item TimesheetEntry
+	err = scanTimesheet(tx.QueryRow(c.Request.Context(), `
+		UPDATE timesheets
+		SET project_id = $3, task_id = $4, work_date = $5, hours = $6, billable = $7, notes = $8,
+		    start_time = $9::time, end_time = $10::time, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING `+timesheetReturning+`
+	`, timesheetID, tenantID, req.ProjectID, req.TaskID, in.WorkDate, in.Hours, req.Billable, strings.TrimSpace(req.Notes), in.StartTime, in.EndTime), &item)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	fillTimesheetNames(c.Request.Context(), tx, &item)
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timesheet.updated", actor, item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	s.wakeEvents()
+
+	c.JSON(http.StatusOK, item)
+}
+
+func timesheetNotEditable(status string) string {
+	if status == timesheetLocked {
+		return "timesheet entry is locked by an approved submission"
+	}
+	return "timesheet entry is submitted for approval"
 }
 
 func (s *Service) DeleteTimesheet(c *gin.Context) {
@@ -212,18 +390,55 @@ func (s *Service) DeleteTimesheet(c *gin.Context) {
 		return
 	}
 
-	commandTag, err := s.DB.Exec(c.Request.Context(), `
-		DELETE FROM timesheets
+	var createdBy, status string
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT lower(created_by_email), status
+		FROM timesheets
 		WHERE id = $1 AND tenant_id = $2
+	`, timesheetID, tenantID).Scan(&createdBy, &status); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "timesheet not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "timesheet lookup failed"})
+		return
+	}
+	if createdBy != strings.ToLower(strings.TrimSpace(emailFromContext(c))) && !hasPermission(c, PermTimesheetsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "you can only delete your own timesheet entries"})
+		return
+	}
+	if status != timesheetDraft {
+		c.JSON(http.StatusConflict, gin.H{"error": timesheetNotEditable(status)})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	commandTag, err := tx.Exec(c.Request.Context(), `
+		DELETE FROM timesheets
+		WHERE id = $1 AND tenant_id = $2 AND status = 'draft'
 	`, timesheetID, tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
 		return
 	}
 	if commandTag.RowsAffected() == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "timesheet not found"})
+		c.JSON(http.StatusConflict, gin.H{"error": "only draft timesheet entries can be deleted"})
+		return
+	}
+	if err := recordEvent(c.Request.Context(), tx, tenantID, "timesheet.deleted", emailFromContext(c), gin.H{"id": timesheetID, "created_by_email": createdBy}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
 		return
 	}
+	s.wakeEvents()
 
 	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
 }
@@ -0,0 +1,92 @@
+package routes
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from RFC 4226 appendix D and RFC 6238 appendix B.
+const rfcSecret = "12345678901234567890"
+
+func TestHOTPRFC4226(t *testing.T) {
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		if got := hotp([]byte(rfcSecret), int64(counter)); got != code {
+			t.Errorf("hotp(counter %d) = %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestTOTPRFC6238(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	// The RFC lists eight-digit codes; six digits keep the low six.
+	cases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+	for _, tc := range cases {
+		at := time.Unix(tc.unix, 0)
+		want := tc.code[len(tc.code)-totpDigits:]
+		got, err := totpCode(secret, at)
+		if err != nil || got != want {
+			t.Errorf("totpCode(%d) = %s, %v; want %s", tc.unix, got, err, want)
+		}
+		if counter, ok := validateTOTP(secret, want, at); !ok || counter != totpCounter(at) {
+			t.Errorf("validateTOTP(%d) = %d, %v", tc.unix, counter, ok)
+		}
+	}
+}
+
+func TestValidateTOTPSkewWindow(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte(rfcSecret))
+	issued := time.Unix(1111111111, 0)
+	counter := totpCounter(issued)
+	code, err := totpCode(secret, issued)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stepStart := time.Unix(counter*totpPeriod, 0)
+	step := totpPeriod * time.Second
+
+	cases := []struct {
+		name string
+		now  time.Time
+		ok   bool
+	}{
+		{"same step", issued, true},
+		{"first second of the step", stepStart, true},
+		{"one step late", issued.Add(step), true},
+		{"last second of the late window", stepStart.Add(2*step - time.Second), true},
+		{"one step early", issued.Add(-step), true},
+		{"first second of the early window", stepStart.Add(-step), true},
+		{"two steps late", stepStart.Add(2 * step), false},
+		{"just before the early window", stepStart.Add(-step - time.Second), false},
+	}
+	for _, tc := range cases {
+		got, ok := validateTOTP(secret, code, tc.now)
+		if ok != tc.ok || (ok && got != counter) {
+			t.Errorf("%s: validateTOTP = %d, %v; want counter %d, %v", tc.name, got, ok, counter, tc.ok)
+		}
+	}
+
+	spaced := code[:3] + " " + code[3:]
+	if _, ok := validateTOTP(strings.ToLower(secret), " "+spaced+" ", issued); !ok {
+		t.Error("spaced code or lower-case secret was rejected")
+	}
+	for _, bad := range []string{code[:5], code + "0", "abcdef"} {
+		if _, ok := validateTOTP(secret, bad, issued); ok {
+			t.Errorf("accepted %q", bad)
+		}
+	}
+	if _, ok := validateTOTP("not base32!", code, issued); ok {
+		t.Error("accepted a code against an undecodable secret")
+	}
+}
@@ -106,30 +106,14 @@ func (s *Service) CreateSystemUpdate(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tenant recipients"})
 		return
 	}
-	subject := "[PulseForge] Platform update scheduled: " + item.Title
-	message := "Hello,\n\nA new platform update has been scheduled.\n\n" +
-		"Date: " + item.ScheduledDate + "\n" +
-		"Feature: " + item.Title + "\n\n" +
-		"Brief:\n" + item.FeatureBrief + "\n\n" +
-		"Expectation:\n" + item.Expectations + "\n\n" +
-		"Please prepare your organization accordingly."
-
-	sent := 0
-	failed := 0
-	for _, to := range recipients {
-		if err := s.sendMail(context.Background(), to, subject, message); err != nil {
-			failed++
-			continue
-		}
-		sent++
-	}
+	queued, failed := s.enqueueBroadcast(c.Request.Context(), recipients, "system_update_scheduled", item)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"item":        item,
 		"recipients":  len(recipients),
-		"sent":        sent,
+		"queued":      queued,
 		"failed":      failed,
-		"mail_status": "broadcast completed",
+		"mail_status": "broadcast queued",
 	})
 }
 
@@ -177,38 +161,44 @@ func (s *Service) UpdateSystemUpdate(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch tenant recipients"})
 		return
 	}
-	subject := "[PulseForge] Platform update revised: " + item.Title
-	message := "Hello,\n\nA scheduled platform update has been revised.\n\n" +
-		"Date: " + item.ScheduledDate + "\n" +
-		"Feature: " + item.Title + "\n\n" +
-		"Brief:\n" + item.FeatureBrief + "\n\n" +
-		"Expectation:\n" + item.Expectations + "\n\n" +
-		"Please align your organization plans accordingly."
+	queued, failed := s.enqueueBroadcast(c.Request.Context(), recipients, "system_update_revised", item)
 
-	sent := 0
+	c.JSON(http.StatusOK, gin.H{
+		"item":        item,
+		"recipients":  len(recipients),
+		"queued":      queued,
+		"failed":      failed,
+		"mail_status": "update broadcast queued",
+	})
+}
+
+// enqueueBroadcast queues one message per recipient, rendered in each
+// recipient's locale with platform branding. failed counts rows that could not
+// be written to the outbox, not delivery failures.
+func (s *Service) enqueueBroadcast(ctx context.Context, recipients []string, template string, item systemUpdate) (int, int) {
+	data := map[string]any{
+		"Date":         item.ScheduledDate,
+		"Title":        item.Title,
+		"Brief":        item.FeatureBrief,
+		"Expectations": item.Expectations,
+	}
+	queued := 0
 	failed := 0
 	for _, to := range recipients {
-		if err := s.sendMail(context.Background(), to, subject, message); err != nil {
+		if err := s.enqueueTemplate(ctx, "system_update", 0, to, template, data); err != nil {
 			failed++
 			continue
 		}
-		sent++
+		queued++
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"item":        item,
-		"recipients":  len(recipients),
-		"sent":        sent,
-		"failed":      failed,
-		"mail_status": "update broadcast completed",
-	})
+	return queued, failed
 }
 
 func (s *Service) fetchOrgAdminEmails(ctx context.Context) ([]string, error) {
 	rows, err := s.DB.Query(ctx, `
 		SELECT DISTINCT lower(trim(email))
 		FROM users
-		WHERE role = 'org_admin'
+		WHERE role IN ('owner', 'org_admin')
 	`)
 	if err != nil {
 		return nil, err
@@ -1,28 +1,33 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 )
 
 type tenantUser struct {
-	ID      string `json:"id"`
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Role    string `json:"role"`
-	Blocked bool   `json:"blocked"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	Role        string     `json:"role"`
+	Blocked     bool       `json:"blocked"`
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
 }
 
 func (s *Service) ListUsers(c *gin.Context) {
-	tenantSlug := tenantFromContext(c)
+	tenantID := tenantFromContext(c)
 	rows, err := s.DB.Query(c.Request.Context(), `
-		SELECT COALESCE(u.public_id, ''), u.name, u.email, u.role, COALESCE(u.blocked, false)
+		SELECT COALESCE(u.public_id, ''), u.name, u.email, u.role, COALESCE(u.blocked, false),
+		       CASE WHEN u.locked_until > NOW() THEN u.locked_until END
 		FROM users u
-		JOIN tenants t ON t.id = u.tenant_id
-		WHERE t.slug = $1
+		WHERE u.tenant_id = $1
 		ORDER BY u.created_at DESC
-	`, tenantSlug)
+	`, tenantID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
 		return
@@ -32,7 +37,7 @@ func (s *Service) ListUsers(c *gin.Context) {
 	items := make([]tenantUser, 0)
 	for rows.Next() {
 		var user tenantUser
-		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.Blocked); err != nil {
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.Blocked, &user.LockedUntil); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
 			return
 		}
@@ -40,3 +45,98 @@ func (s *Service) ListUsers(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
+
+type updateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func (s *Service) UpdateUserRole(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	userID := strings.TrimSpace(c.Param("id"))
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	var req updateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	newRole := strings.ToLower(strings.TrimSpace(req.Role))
+	if !isValidTenantRole(newRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be owner, org_admin, manager, member, viewer or guest"})
+		return
+	}
+
+	var targetEmail string
+	var currentRole string
+	if err := s.DB.QueryRow(c.Request.Context(), `
+		SELECT lower(u.email), COALESCE(u.role, 'member')
+		FROM users u
+		WHERE u.tenant_id = $1 AND u.public_id = $2
+		LIMIT 1
+	`, tenantID, userID).Scan(&targetEmail, &currentRole); errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "user lookup failed"})
+		return
+	}
+	if targetEmail == strings.ToLower(strings.TrimSpace(emailFromContext(c))) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "you cannot change your own role"})
+		return
+	}
+	if currentRole == RoleSystemAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "system admin roles cannot be changed"})
+		return
+	}
+	if !canAssignRole(roleFromContext(c), currentRole, newRole) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "cannot assign or modify a role at or above your own"})
+		return
+	}
+
+	tx, err := s.DB.Begin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "transaction failed"})
+		return
+	}
+	defer tx.Rollback(c.Request.Context())
+
+	// Lock the owners so two demotions cannot each leave the other as the
+	// last one.
+	if currentRole == RoleOwner && newRole != RoleOwner {
+		rows, err := tx.Query(c.Request.Context(), `
+			SELECT public_id
+			FROM users
+			WHERE tenant_id = $1 AND role = 'owner' AND COALESCE(blocked, false) = false
+			FOR UPDATE
+		`, tenantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+			return
+		}
+		owners, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+			return
+		}
+		if len(owners) == 0 || (len(owners) == 1 && owners[0] == userID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "the organization must keep at least one owner"})
+			return
+		}
+	}
+
+	if _, err := tx.Exec(c.Request.Context(), `
+		UPDATE users
+		SET role = $1
+		WHERE tenant_id = $2 AND public_id = $3
+	`, newRole, tenantID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update role"})
+		return
+	}
+	if err := tx.Commit(c.Request.Context()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "updated", "role": newRole})
+}
@@ -0,0 +1,739 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	webhookBatchSize   = 10
+	webhookMaxAttempts = 8
+	webhookLease       = 2 * time.Minute
+	webhookTimeout     = 10 * time.Second
+	// webhookBodyLimit caps how much of a receiver's response is read. The
+	// body is discarded; only the status line is logged, so a webhook cannot
+	// be used to read responses from hosts the server can reach.
+	webhookBodyLimit = 2048
+
+	WebhookSignatureHeader = "X-PulseForge-Signature"
+	WebhookEventHeader     = "X-PulseForge-Event"
+	WebhookDeliveryHeader  = "X-PulseForge-Delivery"
+)
+
+// webhookEvents lists every event type handlers emit. Subscriptions may name
+// these directly or use "prefix.*".
+var webhookEvents = []string{
+	"project.created", "project.updated", "project.deleted",
+	"task.created", "task.updated", "task.deleted",
+	"subtask.created", "subtask.updated", "subtask.deleted",
+	"dependency.created", "dependency.deleted",
+	"timesheet.created", "timesheet.updated", "timesheet.deleted", "timesheet.submitted", "timesheet.reopened",
+	"timer.started", "timer.stopped",
+	"billing_rate.created", "billing_rate.updated", "billing_rate.deleted",
+	"approval.requested", "approval.progressed", "approval.approved", "approval.rejected",
+	"issue.created",
+	"webhook.ping",
+}
+
+type webhookItem struct {
+	ID             int64     `json:"id"`
+	URL            string    `json:"url"`
+	Events         []string  `json:"events"`
+	Description    string    `json:"description"`
+	Active         bool      `json:"active"`
+	CreatedByEmail string    `json:"created_by_email"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Secret is only returned on create and rotate.
+	Secret string `json:"secret,omitempty"`
+}
+
+type webhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events"`
+	Description string   `json:"description"`
+	Active      *bool    `json:"active"`
+	Secret      string   `json:"secret"`
+}
+
+type webhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	MaxAttempts    int             `json:"max_attempts"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DurationMS     int64           `json:"duration_ms"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// claimedWebhook is a delivery leased to one worker; LockedUntil identifies
+// the lease, as for claimedMail.
+type claimedWebhook struct {
+	ID          int64
+	Attempts    int
+	MaxAttempts int
+	LockedUntil time.Time
+	EventID     string
+	EventType   string
+	Payload     []byte
+	URL         string
+	Secret      string
+}
+
+// webhookEnvelope is the JSON body receivers get.
+type webhookEnvelope struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	TenantID  int64     `json:"tenant_id"`
+	Actor     string    `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// SignWebhookPayload returns the signature header value for body sent at ts:
+// "t=<unix>,v1=<hex HMAC-SHA256 of "<unix>.<body>">". Receivers recompute it
+// with their secret and should reject stale timestamps.
+func SignWebhookPayload(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validWebhookEvent(name string) bool {
+	if name == "*" {
+		return true
+	}
+	if prefix, ok := strings.CutSuffix(name, ".*"); ok {
+		for _, ev := range webhookEvents {
+			if strings.HasPrefix(ev, prefix+".") {
+				return true
+			}
+		}
+		return false
+	}
+	for _, ev := range webhookEvents {
+		if ev == name {
+			return true
+		}
+	}
+	return false
+}
+
+var errWebhookAddressBlocked = errors.New("webhook address is not publicly routable")
+
+// nonPublicPrefixes are ranges netip has no predicate for: "this network",
+// carrier-grade NAT, benchmarking and reserved space.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+}
+
+// publicAddress reports whether webhooks may connect to addr.
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// newWebhookClient returns the client deliveries are sent with. Unless
+// allowPrivate is set, the dialer refuses non-public addresses after DNS
+// resolution, so a hostname cannot be pointed at internal services. Proxies
+// are not used and redirects are not followed, since either would let a
+// delivery reach a host that was never checked.
+func newWebhookClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			if allowPrivate {
+				return nil
+			}
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			addr, err := netip.ParseAddr(host)
+			if err != nil {
+				return err
+			}
+			if !publicAddress(addr) {
+				return fmt.Errorf("%w: %s", errWebhookAddressBlocked, host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: webhookTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        20,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validWebhookURL rejects URLs that are obviously internal up front; the
+// dialer in newWebhookClient is what actually enforces it.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || u.User != nil {
+		return false
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddress(addr) {
+		return false
+	}
+	return true
+}
+
+// handleWebhookEvent is the "webhooks" event subscriber. It queues a delivery
+// of ev to every active webhook in the tenant whose filter matches; the event
+// id doubles as the delivery's event id so receivers can dedupe.
+func (s *Service) handleWebhookEvent(ctx context.Context, ev domainEvent) error {
+	if ev.TenantID <= 0 || !validWebhookEvent(ev.Type) {
+		return nil
+	}
+	eventID := "evt_" + strconv.FormatInt(ev.ID, 10)
+	payload, err := json.Marshal(webhookEnvelope{ID: eventID, Type: ev.Type, TenantID: ev.TenantID, Actor: ev.ActorEmail, CreatedAt: ev.CreatedAt.UTC(), Data: ev.Payload})
+	if err != nil {
+		return err
+	}
+	prefix, _, _ := strings.Cut(ev.Type, ".")
+	tag, err := s.DB.Exec(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, tenant_id, event_id, event_type, payload, max_attempts)
+		SELECT w.id, w.tenant_id, $3, $2, $4::jsonb, $6
+		FROM webhooks w
+		WHERE w.tenant_id = $1 AND w.active
+		  AND (w.events = '[]'::jsonb OR w.events ? '*' OR w.events ? $2 OR w.events ? $5)
+		  AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.webhook_id = w.id AND d.event_id = $3)
+	`, ev.TenantID, ev.Type, eventID, string(payload), prefix+".*", webhookMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		s.wakeWebhooks()
+	}
+	return nil
+}
+
+func (s *Service) wakeWebhooks() {
+	select {
+	case s.webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunWebhookWorkers drains webhook_deliveries with n workers until ctx is
+// cancelled, mirroring RunEmailWorkers.
+func (s *Service) RunWebhookWorkers(ctx context.Context, n int, interval time.Duration) {
+	if n < 1 {
+		n = 1
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.webhookWorker(ctx, interval)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Service) webhookWorker(ctx context.Context, interval time.Duration) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-s.webhookWake:
+		}
+
+		claimed, err := s.claimWebhookDeliveries(ctx, webhookBatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("webhook claim failed: %v", err)
+		}
+		for _, d := range claimed {
+			s.deliverWebhook(ctx, d)
+		}
+
+		next := interval
+		if len(claimed) == webhookBatchSize {
+			next = 0
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(next)
+	}
+}
+
+func (s *Service) claimWebhookDeliveries(ctx context.Context, limit int) ([]claimedWebhook, error) {
+	rows, err := s.DB.Query(ctx, `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET status = 'sending', attempts = attempts + 1, locked_until = NOW() + make_interval(secs => $2), updated_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM webhook_deliveries
+				WHERE (status = 'pending' AND next_attempt_at <= NOW())
+				   OR (status = 'sending' AND locked_until < NOW())
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, webhook_id, attempts, max_attempts, locked_until, event_id, event_type, payload
+		)
+		SELECT c.id, c.attempts, c.max_attempts, c.locked_until, c.event_id, c.event_type, c.payload, w.url, w.secret
+		FROM claimed c
+		JOIN webhooks w ON w.id = c.webhook_id
+	`, limit, webhookLease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]claimedWebhook, 0, limit)
+	for rows.Next() {
+		var d claimedWebhook
+		if err := rows.Scan(&d.ID, &d.Attempts, &d.MaxAttempts, &d.LockedUntil, &d.EventID, &d.EventType, &d.Payload, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (s *Service) deliverWebhook(ctx context.Context, d claimedWebhook) {
+	started := time.Now()
+	status, summary, sendErr := s.postWebhook(ctx, d)
+	elapsed := time.Since(started).Milliseconds()
+
+	var respStatus *int
+	if status > 0 {
+		respStatus = &status
+	}
+	bg := context.Background()
+	var tag pgconn.CommandTag
+	var err error
+	switch {
+	case sendErr == nil:
+		tag, err = s.DB.Exec(bg, `
+			UPDATE webhook_deliveries
+			SET status = 'delivered', delivered_at = NOW(), locked_until = NULL, last_error = NULL,
+			    response_status = $3, response_body = $4, duration_ms = $5, updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, d.ID, d.LockedUntil, respStatus, summary, elapsed)
+	case d.Attempts >= d.MaxAttempts:
+		log.Printf("webhook delivery %d dead after %d attempts: %v", d.ID, d.Attempts, sendErr)
+		tag, err = s.DB.Exec(bg, `
+			UPDATE webhook_deliveries
+			SET status = 'dead', locked_until = NULL, last_error = $3, response_status = $4, response_body = $5, duration_ms = $6, updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, d.ID, d.LockedUntil, sendErr.Error(), respStatus, summary, elapsed)
+	default:
+		tag, err = s.DB.Exec(bg, `
+			UPDATE webhook_deliveries
+			SET status = 'pending', locked_until = NULL, last_error = $3, response_status = $4, response_body = $5, duration_ms = $6,
+			    next_attempt_at = $7, updated_at = NOW()
+			WHERE id = $1 AND status = 'sending' AND locked_until = $2
+		`, d.ID, d.LockedUntil, sendErr.Error(), respStatus, summary, elapsed, time.Now().Add(outboxBackoff(d.Attempts)))
+	}
+	if err != nil {
+		log.Printf("webhook delivery %d: failed to record result: %v", d.ID, err)
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("webhook delivery %d: lease lost before the result was recorded", d.ID)
+	}
+}
+
+// postWebhook sends one signed delivery and returns the response status and
+// status line. Any non-2xx response, redirects included, is an error.
+func (s *Service) postWebhook(ctx context.Context, d claimedWebhook) (int, string, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, d.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PulseForge-Webhooks/1")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(d.Secret, time.Now(), d.Payload))
+
+	resp, err := s.webhookClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookBodyLimit))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, resp.Status, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, resp.Status, nil
+}
+
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	out := make([]string, 0, len(events))
+	seen := make(map[string]struct{}, len(events))
+	for _, ev := range events {
+		ev = strings.ToLower(strings.TrimSpace(ev))
+		if ev == "" {
+			continue
+		}
+		if !validWebhookEvent(ev) {
+			return nil, fmt.Errorf("unknown event: %s", ev)
+		}
+		if _, ok := seen[ev]; ok {
+			continue
+		}
+		seen[ev] = struct{}{}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+func scanWebhook(row pgx.Row) (webhookItem, error) {
+	var item webhookItem
+	var eventsRaw []byte
+	err := row.Scan(&item.ID, &item.URL, &eventsRaw, &item.Description, &item.Active, &item.CreatedByEmail, &item.CreatedAt, &item.UpdatedAt)
+	item.Events = parseStringArrayJSON(eventsRaw)
+	return item, err
+}
+
+func (s *Service) ListWebhooks(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, url, events, description, active, created_by_email, created_at, updated_at
+		FROM webhooks
+		WHERE tenant_id = $1
+		ORDER BY id
+	`, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]webhookItem, 0)
+	for rows.Next() {
+		item, err := scanWebhook(rows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "events": webhookEvents})
+}
+
+func (s *Service) CreateWebhook(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if !validWebhookURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an absolute http or https URL on a public host"})
+		return
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		token, err := randomToken(24)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+			return
+		}
+		secret = "whsec_" + token
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	eventsJSON, _ := json.Marshal(events)
+
+	item, err := scanWebhook(s.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO webhooks (tenant_id, url, secret, events, description, active, created_by_email)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7)
+		RETURNING id, url, events, description, active, created_by_email, created_at, updated_at
+	`, tenantID, req.URL, secret, string(eventsJSON), strings.TrimSpace(req.Description), active, strings.ToLower(strings.TrimSpace(emailFromContext(c)))))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	item.Secret = secret
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateWebhook replaces url, events, description and active. The secret is
+// kept unless a new one is supplied.
+func (s *Service) UpdateWebhook(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	var req webhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	req.URL = strings.TrimSpace(req.URL)
+	if !validWebhookURL(req.URL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url must be an absolute http or https URL on a public host"})
+		return
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	eventsJSON, _ := json.Marshal(events)
+
+	item, err := scanWebhook(s.DB.QueryRow(c.Request.Context(), `
+		UPDATE webhooks
+		SET url = $3, events = $4::jsonb, description = $5, active = $6, secret = COALESCE(NULLIF($7, ''), secret), updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING id, url, events, description, active, created_by_email, created_at, updated_at
+	`, id, tenantID, req.URL, string(eventsJSON), strings.TrimSpace(req.Description), active, strings.TrimSpace(req.Secret)))
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (s *Service) DeleteWebhook(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	tag, err := s.DB.Exec(c.Request.Context(), `DELETE FROM webhooks WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (s *Service) RotateWebhookSecret(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	token, err := randomToken(24)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate secret"})
+		return
+	}
+	secret := "whsec_" + token
+	tag, err := s.DB.Exec(c.Request.Context(), `
+		UPDATE webhooks SET secret = $3, updated_at = NOW() WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID, secret)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"secret": secret})
+}
+
+// PingWebhook queues a webhook.ping delivery to one webhook regardless of its
+// event filter, so receivers can be checked before real traffic.
+func (s *Service) PingWebhook(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	eventID, err := randomToken(16)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create event"})
+		return
+	}
+	eventID = "evt_" + eventID
+	payload, _ := json.Marshal(webhookEnvelope{ID: eventID, Type: "webhook.ping", TenantID: tenantID, CreatedAt: time.Now().UTC(), Data: gin.H{"webhook_id": id}})
+
+	var deliveryID int64
+	err = s.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO webhook_deliveries (webhook_id, tenant_id, event_id, event_type, payload, max_attempts)
+		SELECT id, tenant_id, $3, 'webhook.ping', $4::jsonb, 1
+		FROM webhooks
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING id
+	`, id, tenantID, eventID, string(payload)).Scan(&deliveryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue ping"})
+		return
+	}
+	s.wakeWebhooks()
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "delivery_id": deliveryID})
+}
+
+func (s *Service) ListWebhookDeliveries(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	id, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	limit := 50
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		if n, err := strconv.Atoi(raw); err == nil && n > 0 && n <= 200 {
+			limit = n
+		}
+	}
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, webhook_id, event_id, event_type, payload, status, attempts, max_attempts, response_status,
+		       COALESCE(response_body, ''), COALESCE(last_error, ''), duration_ms, next_attempt_at, delivered_at, created_at
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND tenant_id = $2 AND ($3 = '' OR status = $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`, id, tenantID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]webhookDelivery, 0, limit)
+	for rows.Next() {
+		var d webhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.MaxAttempts, &d.ResponseStatus,
+			&d.ResponseBody, &d.LastError, &d.DurationMS, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, d)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// RedeliverWebhook queues a fresh delivery of a logged event with the same
+// event id, leaving the original row in the log. Receivers should dedupe on
+// the event id.
+func (s *Service) RedeliverWebhook(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	webhookID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || webhookID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook id"})
+		return
+	}
+	deliveryID, err := strconv.ParseInt(strings.TrimSpace(c.Param("delivery_id")), 10, 64)
+	if err != nil || deliveryID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	var newID int64
+	err = s.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO webhook_deliveries (webhook_id, tenant_id, event_id, event_type, payload, max_attempts)
+		SELECT webhook_id, tenant_id, event_id, event_type, payload, $4
+		FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2 AND tenant_id = $3
+		RETURNING id
+	`, deliveryID, webhookID, tenantID, webhookMaxAttempts).Scan(&newID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue redelivery"})
+		return
+	}
+	s.wakeWebhooks()
+	c.JSON(http.StatusAccepted, gin.H{"status": "queued", "delivery_id": newID})
+}
@@ -0,0 +1,448 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	workflowEntityTask    = "task"
+	workflowEntityProject = "project"
+)
+
+// workflowFields are the fields a transition may require, per entity.
+var workflowFields = map[string][]string{
+	workflowEntityTask:    {"description", "assignees", "phase", "start_date", "due_date", "story_points", "estimated_hours"},
+	workflowEntityProject: {"assignees"},
+}
+
+type workflowState struct {
+	Key      string `json:"key"`
+	Label    string `json:"label,omitempty"`
+	Terminal bool   `json:"terminal"`
+}
+
+type workflowTransition struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	RequiredFields []string `json:"required_fields,omitempty"`
+}
+
+type Workflow struct {
+	ID          int64                `json:"id"`
+	ProjectID   *int64               `json:"project_id,omitempty"`
+	Entity      string               `json:"entity"`
+	Name        string               `json:"name"`
+	States      []workflowState      `json:"states"`
+	Transitions []workflowTransition `json:"transitions"`
+	CreatedAt   time.Time            `json:"created_at"`
+	UpdatedAt   time.Time            `json:"updated_at"`
+}
+
+type workflowRequest struct {
+	Entity      string               `json:"entity" binding:"required"`
+	ProjectID   *int64               `json:"project_id"`
+	Name        string               `json:"name"`
+	States      []workflowState      `json:"states" binding:"required"`
+	Transitions []workflowTransition `json:"transitions"`
+}
+
+type statusChange struct {
+	ID         int64     `json:"id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ActorEmail string    `json:"actor_email"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// transitionError is a rejected status change, reported with a 400.
+type transitionError struct {
+	Message       string
+	MissingFields []string
+}
+
+func (e *transitionError) response() gin.H {
+	out := gin.H{"error": e.Message}
+	if len(e.MissingFields) > 0 {
+		out["missing_fields"] = e.MissingFields
+	}
+	return out
+}
+
+func (w *Workflow) state(status string) *workflowState {
+	status = strings.TrimSpace(status)
+	for i := range w.States {
+		if strings.EqualFold(w.States[i].Key, status) {
+			return &w.States[i]
+		}
+	}
+	return nil
+}
+
+// initialState is the first state, or fallback without a workflow.
+func (w *Workflow) initialState(fallback string) string {
+	if w == nil || len(w.States) == 0 {
+		return fallback
+	}
+	return w.States[0].Key
+}
+
+// isTerminal reports whether status closes the item. Without a workflow the
+// legacy done/completed/closed statuses are terminal.
+func (w *Workflow) isTerminal(status string) bool {
+	if w == nil {
+		return isDoneStatus(status)
+	}
+	st := w.state(status)
+	return st != nil && st.Terminal
+}
+
+// normalize maps status onto the workflow's spelling of the state key.
+func (w *Workflow) normalize(status string) string {
+	if w == nil {
+		return strings.TrimSpace(status)
+	}
+	if st := w.state(status); st != nil {
+		return st.Key
+	}
+	return strings.TrimSpace(status)
+}
+
+// checkInitial validates the status of a newly created item.
+func (w *Workflow) checkInitial(status string) *transitionError {
+	if w == nil || w.state(status) != nil {
+		return nil
+	}
+	return &transitionError{Message: "unknown status: " + status}
+}
+
+// checkTransition validates moving from one status to another. present
+// reports whether a field is filled in on the item after the change. An item
+// whose current status is not in the workflow (left over from before it was
+// configured) may move to any state, and keeping the status unchanged is
+// always allowed.
+func (w *Workflow) checkTransition(from, to string, present func(field string) bool) *transitionError {
+	if w == nil || strings.EqualFold(strings.TrimSpace(from), strings.TrimSpace(to)) {
+		return nil
+	}
+	target := w.state(to)
+	if target == nil {
+		return &transitionError{Message: "unknown status: " + to}
+	}
+	current := w.state(from)
+	if current == nil || strings.EqualFold(current.Key, target.Key) {
+		return nil
+	}
+	if len(w.Transitions) == 0 {
+		return nil
+	}
+	for _, t := range w.Transitions {
+		if !strings.EqualFold(t.From, current.Key) || !strings.EqualFold(t.To, target.Key) {
+			continue
+		}
+		var missing []string
+		for _, field := range t.RequiredFields {
+			if !present(field) {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			return &transitionError{
+				Message:       "moving from " + current.Key + " to " + target.Key + " requires " + strings.Join(missing, ", "),
+				MissingFields: missing,
+			}
+		}
+		return nil
+	}
+	return &transitionError{Message: "cannot move from " + current.Key + " to " + target.Key}
+}
+
+// loadWorkflow returns the workflow governing entity in projectID: the
+// project's own definition, else the tenant default, else nil.
+func (s *Service) loadWorkflow(ctx context.Context, tenantID int64, entity string, projectID int64) (*Workflow, error) {
+	var w Workflow
+	var statesRaw, transitionsRaw []byte
+	err := s.DB.QueryRow(ctx, `
+		SELECT id, project_id, entity, name, states, transitions, created_at, updated_at
+		FROM workflows
+		WHERE tenant_id = $1 AND entity = $2 AND (project_id = $3 OR project_id IS NULL)
+		ORDER BY project_id NULLS LAST
+		LIMIT 1
+	`, tenantID, entity, projectID).Scan(&w.ID, &w.ProjectID, &w.Entity, &w.Name, &statesRaw, &transitionsRaw, &w.CreatedAt, &w.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	_ = json.Unmarshal(statesRaw, &w.States)
+	_ = json.Unmarshal(transitionsRaw, &w.Transitions)
+	if len(w.States) == 0 {
+		return nil, nil
+	}
+	return &w, nil
+}
+
+// recordStatusChange appends to status_history inside tx.
+func recordStatusChange(ctx context.Context, tx pgx.Tx, tenantID int64, entity string, entityID int64, from, to, actor string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO status_history (tenant_id, entity, entity_id, from_status, to_status, actor_email)
+		VALUES ($1, $2, $3, $4, $5, lower($6))
+	`, tenantID, entity, entityID, from, to, actor)
+	return err
+}
+
+// validateWorkflow cleans req in place and returns a message for a 400.
+func validateWorkflow(req *workflowRequest) string {
+	req.Entity = strings.ToLower(strings.TrimSpace(req.Entity))
+	fields, ok := workflowFields[req.Entity]
+	if !ok {
+		return "entity must be task or project"
+	}
+	if req.Entity == workflowEntityProject && req.ProjectID != nil {
+		return "project workflows are tenant-wide; omit project_id"
+	}
+	if len(req.States) == 0 {
+		return "at least one state is required"
+	}
+	seen := make(map[string]bool, len(req.States))
+	terminal := false
+	for i := range req.States {
+		st := &req.States[i]
+		st.Key = strings.ToLower(strings.TrimSpace(st.Key))
+		st.Label = strings.TrimSpace(st.Label)
+		if st.Key == "" {
+			return "state keys must not be empty"
+		}
+		if seen[st.Key] {
+			return "duplicate state: " + st.Key
+		}
+		seen[st.Key] = true
+		terminal = terminal || st.Terminal
+	}
+	if !terminal {
+		return "at least one state must be terminal"
+	}
+
+	allowed := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		allowed[f] = true
+	}
+	pairs := make(map[string]bool, len(req.Transitions))
+	for i := range req.Transitions {
+		t := &req.Transitions[i]
+		t.From = strings.ToLower(strings.TrimSpace(t.From))
+		t.To = strings.ToLower(strings.TrimSpace(t.To))
+		if !seen[t.From] || !seen[t.To] {
+			return "transition " + t.From + " -> " + t.To + " references an unknown state"
+		}
+		if t.From == t.To {
+			return "transition " + t.From + " -> " + t.To + " does not change state"
+		}
+		if pairs[t.From+"\x00"+t.To] {
+			return "duplicate transition " + t.From + " -> " + t.To
+		}
+		pairs[t.From+"\x00"+t.To] = true
+		clean := make([]string, 0, len(t.RequiredFields))
+		for _, f := range t.RequiredFields {
+			f = strings.ToLower(strings.TrimSpace(f))
+			if !allowed[f] {
+				return "field " + f + " cannot be required on a " + req.Entity
+			}
+			clean = append(clean, f)
+		}
+		t.RequiredFields = clean
+	}
+	if req.Transitions == nil {
+		req.Transitions = []workflowTransition{}
+	}
+	return ""
+}
+
+func scanWorkflow(row pgx.Row, w *Workflow) error {
+	var statesRaw, transitionsRaw []byte
+	if err := row.Scan(&w.ID, &w.ProjectID, &w.Entity, &w.Name, &statesRaw, &transitionsRaw, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return err
+	}
+	w.States = make([]workflowState, 0)
+	w.Transitions = make([]workflowTransition, 0)
+	_ = json.Unmarshal(statesRaw, &w.States)
+	_ = json.Unmarshal(transitionsRaw, &w.Transitions)
+	return nil
+}
+
+func (s *Service) ListWorkflows(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, project_id, entity, name, states, transitions, created_at, updated_at
+		FROM workflows
+		WHERE tenant_id = $1
+		ORDER BY entity, project_id NULLS FIRST
+	`, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]Workflow, 0)
+	for rows.Next() {
+		var w Workflow
+		if err := scanWorkflow(rows, &w); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, w)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "fields": workflowFields})
+}
+
+func (s *Service) CreateWorkflow(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	var req workflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if msg := validateWorkflow(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if req.ProjectID != nil {
+		var exists bool
+		if err := s.DB.QueryRow(c.Request.Context(), `
+			SELECT EXISTS (SELECT 1 FROM projects WHERE id = $1 AND tenant_id = $2)
+		`, *req.ProjectID, tenantID).Scan(&exists); err != nil || !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project not found for this tenant"})
+			return
+		}
+	}
+	statesJSON, _ := json.Marshal(req.States)
+	transitionsJSON, _ := json.Marshal(req.Transitions)
+
+	var w Workflow
+	err := scanWorkflow(s.DB.QueryRow(c.Request.Context(), `
+		INSERT INTO workflows (tenant_id, project_id, entity, name, states, transitions)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb)
+		ON CONFLICT DO NOTHING
+		RETURNING id, project_id, entity, name, states, transitions, created_at, updated_at
+	`, tenantID, req.ProjectID, req.Entity, strings.TrimSpace(req.Name), string(statesJSON), string(transitionsJSON)), &w)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusConflict, gin.H{"error": "a workflow already exists for this scope"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "insert failed"})
+		return
+	}
+	c.JSON(http.StatusCreated, w)
+}
+
+// UpdateWorkflow replaces states and transitions. Items already in a state
+// that is removed keep it and may move to any remaining state.
+func (s *Service) UpdateWorkflow(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	workflowID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || workflowID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow id"})
+		return
+	}
+	var req workflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+	if msg := validateWorkflow(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	statesJSON, _ := json.Marshal(req.States)
+	transitionsJSON, _ := json.Marshal(req.Transitions)
+
+	// Entity and scope are fixed once created; only the definition changes.
+	var w Workflow
+	err = scanWorkflow(s.DB.QueryRow(c.Request.Context(), `
+		UPDATE workflows
+		SET name = $1, states = $2::jsonb, transitions = $3::jsonb, updated_at = NOW()
+		WHERE id = $4 AND tenant_id = $5 AND entity = $6
+		RETURNING id, project_id, entity, name, states, transitions, created_at, updated_at
+	`, strings.TrimSpace(req.Name), string(statesJSON), string(transitionsJSON), workflowID, tenantID, req.Entity), &w)
+	if errors.Is(err, pgx.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "update failed"})
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (s *Service) DeleteWorkflow(c *gin.Context) {
+	tenantID := tenantFromContext(c)
+	workflowID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || workflowID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workflow id"})
+		return
+	}
+	tag, err := s.DB.Exec(c.Request.Context(), `
+		DELETE FROM workflows
+		WHERE id = $1 AND tenant_id = $2
+	`, workflowID, tenantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "delete failed"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (s *Service) statusHistory(c *gin.Context, entity string) {
+	tenantID := tenantFromContext(c)
+	entityID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || entityID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + entity + " id"})
+		return
+	}
+	rows, err := s.DB.Query(c.Request.Context(), `
+		SELECT id, from_status, to_status, actor_email, created_at
+		FROM status_history
+		WHERE tenant_id = $1 AND entity = $2 AND entity_id = $3
+		ORDER BY created_at, id
+	`, tenantID, entity, entityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "query failed"})
+		return
+	}
+	defer rows.Close()
+
+	items := make([]statusChange, 0)
+	for rows.Next() {
+		var item statusChange
+		if err := rows.Scan(&item.ID, &item.FromStatus, &item.ToStatus, &item.ActorEmail, &item.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "scan failed"})
+			return
+		}
+		items = append(items, item)
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (s *Service) TaskStatusHistory(c *gin.Context) {
+	s.statusHistory(c, workflowEntityTask)
+}
+
+func (s *Service) ProjectStatusHistory(c *gin.Context) {
+	s.statusHistory(c, workflowEntityProject)
+}
@@ -0,0 +1,44 @@
+package routes
+
+import "testing"
+
+func testWorkflow() *Workflow {
+	return &Workflow{
+		States: []workflowState{{Key: "todo"}, {Key: "review"}, {Key: "done", Terminal: true}},
+		Transitions: []workflowTransition{
+			{From: "todo", To: "review"},
+			{From: "review", To: "done", RequiredFields: []string{"assignee_email"}},
+		},
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	w := testWorkflow()
+	none := func(string) bool { return false }
+	cases := []struct {
+		name, from, to string
+		ok             bool
+	}{
+		{"allowed", "todo", "review", true},
+		{"not in transitions", "todo", "done", false},
+		{"missing required field", "review", "done", false},
+		{"unknown target", "todo", "archived", false},
+		{"legacy status may move anywhere", "in_progress", "review", true},
+		{"legacy status kept unchanged", "in_progress", "in_progress", true},
+		{"legacy status kept with other casing", "In_Progress ", "in_progress", true},
+		{"same state", "Review", "review", true},
+	}
+	for _, tc := range cases {
+		err := w.checkTransition(tc.from, tc.to, none)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: checkTransition(%q, %q) = %v", tc.name, tc.from, tc.to, err)
+		}
+	}
+
+	if err := w.checkTransition("review", "done", func(f string) bool { return f == "assignee_email" }); err != nil {
+		t.Errorf("required field present: %v", err)
+	}
+	if (*Workflow)(nil).checkTransition("anything", "else", none) != nil {
+		t.Error("nil workflow should allow any transition")
+	}
+}
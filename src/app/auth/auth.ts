@@ -1,3 +1,5 @@
+export type UserRole = "system_admin" | "owner" | "org_admin" | "manager" | "member" | "viewer" | "guest";
+
 export type AuthUser = {
   id: string;
   name: string;
@@ -5,11 +7,12 @@ export type AuthUser = {
   tenantSlug: string;
   tenantName?: string;
   tenantLogoUrl?: string;
-  role: "system_admin" | "org_admin";
+  role: UserRole;
 };
 
 export type AuthSession = {
   token: string;
+  refreshToken?: string;
   user: AuthUser;
 };
 
@@ -36,11 +39,17 @@ type ForgotPasswordInput = {
   email: string;
 };
 
+type ResetPasswordInput = {
+  token: string;
+  password: string;
+};
+
 export type Project = {
   id: number;
   project_code?: string;
-  tenant_id: string;
+  tenant_id: number;
   name: string;
+  client_name?: string;
   status: string;
   assignees: string[];
   start_date?: string | null;
@@ -54,21 +63,50 @@ export type Project = {
 export type TaskItem = {
   id: number;
   task_code?: string;
-  tenant_id: string;
+  tenant_id: number;
   project_id: number;
   project_name?: string;
   phase?: string;
   title: string;
+  description?: string;
   status: string;
   priority: string;
-  subtasks: string[];
+  assignees?: string[];
+  start_date?: string;
+  due_date?: string;
+  story_points?: number;
+  estimated_hours?: number;
+  subtasks: TaskSubtask[];
+  subtask_progress?: { done: number; total: number };
+  completed_at?: string;
   created_at: string;
   attachments?: string[];
 };
 
+export type TaskSubtask = {
+  id: number;
+  task_id: number;
+  title: string;
+  status: string;
+  assignee?: string;
+  position: number;
+  completed_at?: string;
+  created_at: string;
+  updated_at: string;
+};
+
+export type TaskPlanInput = {
+  description?: string;
+  assignees?: string[];
+  start_date?: string;
+  due_date?: string;
+  story_points?: number | null;
+  estimated_hours?: number | null;
+};
+
 export type TimesheetEntry = {
   id: number;
-  tenant_id: string;
+  tenant_id: number;
   project_id?: number | null;
   task_id?: number | null;
   project_name?: string;
@@ -78,15 +116,169 @@ export type TimesheetEntry = {
   billable: boolean;
   notes: string;
   created_by_email: string;
+  status: "draft" | "submitted" | "locked";
+  submission_id?: number | null;
+  start_time?: string;
+  end_time?: string;
   created_at: string;
 };
 
+export type TimesheetInput = {
+  project_id?: number | null;
+  task_id?: number | null;
+  work_date: string;
+  hours: number;
+  billable: boolean;
+  notes?: string;
+  start_time?: string;
+  end_time?: string;
+};
+
+export type TimesheetPolicy = {
+  max_hours_per_day: number | null;
+  max_hours_per_week: number | null;
+  max_future_days: number | null;
+  enforce_project_window: boolean;
+  block_closed_projects: boolean;
+  require_clock_times: boolean;
+  prevent_overlaps: boolean;
+  updated_by_email?: string;
+  updated_at?: string;
+};
+
+export type PolicyViolation = {
+  rule: string;
+  message: string;
+  limit?: number;
+  actual?: number;
+  entry_ids?: number[];
+};
+
+// RequestError carries the policy violations the API lists alongside an error.
+export class RequestError extends Error {
+  violations: PolicyViolation[];
+
+  constructor(message: string, violations: PolicyViolation[] = []) {
+    super(message);
+    this.name = "RequestError";
+    this.violations = violations;
+  }
+}
+
+export type TimesheetSubmission = {
+  id: number;
+  tenant_id: number;
+  user_email: string;
+  week_start: string;
+  week_end: string;
+  status: "submitted" | "approved" | "rejected" | "reopened";
+  total_hours: number;
+  billable_hours: number;
+  entry_count: number;
+  approval_request_id?: number | null;
+  submitted_at: string;
+  approved_at?: string | null;
+  reopened_at?: string | null;
+  reopened_by_email?: string;
+};
+
+export type Timer = {
+  id: number;
+  tenant_id: number;
+  user_email: string;
+  project_id?: number | null;
+  task_id?: number | null;
+  billable: boolean;
+  notes: string;
+  started_at: string;
+  last_activity_at: string;
+  stopped_at?: string | null;
+  auto_stopped: boolean;
+  elapsed_seconds: number;
+};
+
+export type TimerStopResult = {
+  timer: Timer;
+  entries: TimesheetEntry[];
+  skipped_days: string[];
+  clipped: PolicyViolation[];
+};
+
 export type TimesheetSummary = {
   billable_hours: number;
   non_billable_hours: number;
   total_hours: number;
 };
 
+export type BillingRateScope = "tenant" | "project" | "user" | "task";
+
+export type BillingRate = {
+  id: number;
+  scope: BillingRateScope;
+  project_id?: number | null;
+  task_id?: number | null;
+  user_email?: string | null;
+  hourly_rate: number;
+  currency: string;
+  effective_from: string;
+  effective_to?: string;
+  created_by_email: string;
+  created_at: string;
+};
+
+export type BillingRateInput = {
+  scope: BillingRateScope;
+  project_id?: number | null;
+  task_id?: number | null;
+  user_email?: string;
+  hourly_rate: number;
+  currency: string;
+  effective_from: string;
+  effective_to?: string;
+};
+
+export type BillingReportQuery = {
+  from: string;
+  to: string;
+  group_by?: ("client" | "project" | "user")[];
+  rounding?: "none" | "up" | "down" | "nearest";
+  increment?: number;
+  lines?: boolean;
+};
+
+export type BillingReportGroup = {
+  client_name?: string;
+  project_id?: number | null;
+  project_name?: string;
+  user_email?: string;
+  currency: string;
+  hours: number;
+  billed_hours: number;
+  unpriced_hours: number;
+  amount: number;
+  lines?: {
+    timesheet_id: number;
+    work_date: string;
+    task_id?: number | null;
+    hours: number;
+    billed_hours: number;
+    rate_id?: number;
+    rate_scope?: BillingRateScope;
+    hourly_rate?: number;
+    amount: number;
+  }[];
+};
+
+export type BillingReport = {
+  from: string;
+  to: string;
+  group_by: string[];
+  rounding: { mode: string; increment_minutes: number };
+  groups: BillingReportGroup[];
+  totals: { currency: string; billed_hours: number; amount: number }[];
+  unpriced_hours: number;
+};
+
 export type SystemOrganization = {
   tenant_slug: string;
   tenant_name: string;
@@ -123,6 +315,95 @@ export type SystemLog = {
   created_at: string;
 };
 
+export type EmailOutboxStatus = "pending" | "sending" | "sent" | "dead";
+
+export type EmailOutboxMessage = {
+  id: number;
+  category: string;
+  recipient: string;
+  subject: string;
+  status: EmailOutboxStatus;
+  attempts: number;
+  max_attempts: number;
+  last_error?: string;
+  next_attempt_at: string;
+  created_at: string;
+  sent_at?: string;
+};
+
+export type SystemJob = {
+  name: string;
+  interval_seconds: number;
+  last_started_at?: string;
+  last_finished_at?: string;
+  last_status: "never" | "running" | "ok" | "error";
+  last_error?: string;
+  last_items: number;
+  last_duration_ms: number;
+  next_run_at: string;
+  run_count: number;
+  failure_count: number;
+  ran_by?: string;
+};
+
+export type Webhook = {
+  id: number;
+  url: string;
+  events: string[];
+  description: string;
+  active: boolean;
+  created_by_email: string;
+  created_at: string;
+  updated_at: string;
+  secret?: string;
+};
+
+export type WebhookInput = {
+  url: string;
+  events: string[];
+  description?: string;
+  active?: boolean;
+  secret?: string;
+};
+
+export type WebhookDelivery = {
+  id: number;
+  webhook_id: number;
+  event_id: string;
+  event_type: string;
+  payload: Record<string, unknown>;
+  status: "pending" | "sending" | "delivered" | "dead";
+  attempts: number;
+  max_attempts: number;
+  response_status?: number;
+  response_body?: string;
+  last_error?: string;
+  duration_ms: number;
+  next_attempt_at: string;
+  delivered_at?: string;
+  created_at: string;
+};
+
+export type EventSubscriberState = {
+  name: string;
+  last_event_id: number;
+  lag: number;
+  failures: number;
+  last_error?: string;
+  updated_at: string;
+};
+
+export type AuditEntry = {
+  id: number;
+  event_id: number;
+  event_type: string;
+  actor_email: string;
+  subject_type: string;
+  subject_id?: number;
+  summary: string;
+  created_at: string;
+};
+
 export type SystemTenant = {
   id: number;
   slug: string;
@@ -143,7 +424,7 @@ export type TenantUser = {
 
 export type ForumPost = {
   id: number;
-  tenant_id: string;
+  tenant_id: number;
   author_email: string;
   title: string;
   body: string;
@@ -152,7 +433,7 @@ export type ForumPost = {
 
 export type CodeShare = {
   id: number;
-  tenant_id: string;
+  tenant_id: number;
   author_email: string;
   title: string;
   body: string;
@@ -164,7 +445,7 @@ export type CodeShare = {
 
 export type IssueItem = {
   id: number;
-  tenant_id: string;
+  tenant_id: number;
   project_id?: number | null;
   project_name?: string;
   title: string;
@@ -187,16 +468,25 @@ export type SystemUpdate = {
 
 export type AppNotification = {
   id: string;
-  tenant_id: string;
+  tenant_id: number;
   recipient_email: string;
   type: "project" | "due" | "support" | "system" | "summary" | string;
   title: string;
   detail: string;
   meta?: Record<string, unknown>;
   read_at?: string | null;
+  archived_at?: string | null;
   created_at: string;
 };
 
+export type NotificationChannel = "all" | "in_app" | "email" | "none";
+
+export type NotificationPreferences = {
+  channels: Record<string, NotificationChannel>;
+  types: string[];
+  options: NotificationChannel[];
+};
+
 export type NotificationSummary = {
   pending_projects: number;
   assigned_pending_projects: number;
@@ -206,6 +496,7 @@ export type NotificationSummary = {
 
 export type UserSettings = {
   timezone: string;
+  locale: string;
   week_starts_on: "monday" | "sunday";
   reminder_frequency: "daily" | "weekly" | "custom";
   reminder_days: string[];
@@ -224,7 +515,7 @@ export type UserSettings = {
 
 export type ApprovalRequest = {
   id: number;
-  tenant_id: string;
+  tenant_id: number;
   project_id?: number | null;
   project_name: string;
   billable_hours: number;
@@ -238,6 +529,19 @@ export type ApprovalRequest = {
   approvals: string[];
   created_at: string;
   updated_at: string;
+  entries?: ApprovalLineItem[];
+};
+
+export type ApprovalLineItem = {
+  id: number;
+  timesheet_id?: number | null;
+  user_email: string;
+  project_id?: number | null;
+  task_id?: number | null;
+  work_date: string;
+  hours: number;
+  billable: boolean;
+  active: boolean;
 };
 
 export type SessionItem = {
@@ -246,6 +550,7 @@ export type SessionItem = {
   email: string;
   role: string;
   blocked: boolean;
+  locked_until?: string | null;
   last_login_at?: string | null;
   device_label?: string;
   ip?: string;
@@ -293,11 +598,46 @@ function normalizeError(error: unknown): string {
   return "Request failed.";
 }
 
+let refreshInFlight: Promise<string | null> | null = null;
+
+async function refreshAccessToken(): Promise<string | null> {
+  const session = readSession();
+  if (!session?.refreshToken) return null;
+  if (!refreshInFlight) {
+    refreshInFlight = (async () => {
+      try {
+        const response = await fetch(`${API_BASE}/api/v1/auth/refresh`, {
+          method: "POST",
+          headers: { "Content-Type": "application/json" },
+          body: JSON.stringify({ refresh_token: session.refreshToken }),
+        });
+        if (!response.ok) return null;
+        const payload = (await response.json()) as { token: string; refresh_token: string };
+        writeSession({ ...session, token: payload.token, refreshToken: payload.refresh_token });
+        return payload.token;
+      } catch {
+        return null;
+      } finally {
+        refreshInFlight = null;
+      }
+    })();
+  }
+  return refreshInFlight;
+}
+
 async function requestJSON<T>(path: string, init?: RequestInit): Promise<T> {
-  const response = await fetch(`${API_BASE}${path}`, init);
-  const payload = (await response.json().catch(() => ({}))) as { error?: string } & T;
+  let response = await fetch(`${API_BASE}${path}`, init);
+  const headers = new Headers(init?.headers);
+  if (response.status === 401 && headers.has("Authorization")) {
+    const nextToken = await refreshAccessToken();
+    if (nextToken) {
+      headers.set("Authorization", `Bearer ${nextToken}`);
+      response = await fetch(`${API_BASE}${path}`, { ...init, headers });
+    }
+  }
+  const payload = (await response.json().catch(() => ({}))) as { error?: string; violations?: PolicyViolation[] } & T;
   if (!response.ok) {
-    throw new Error(payload.error || `Request failed with ${response.status}`);
+    throw new RequestError(payload.error || `Request failed with ${response.status}`, payload.violations || []);
   }
   return payload;
 }
@@ -305,11 +645,11 @@ async function requestJSON<T>(path: string, init?: RequestInit): Promise<T> {
 function userFromToken(token: string): AuthUser {
   const claims = parseJwtClaims(token);
   const email = String(claims.email || "");
-  const tenantSlug = String(claims.tenant_id || "");
+  const tenantSlug = String(claims.tenant_slug || "");
   const tenantName = String(claims.tenant_name || "");
   const tenantLogoUrl = String(claims.tenant_logo || "");
   const id = String(claims.sub || "");
-  const role = String(claims.role || "org_admin") as "system_admin" | "org_admin";
+  const role = String(claims.role || "org_admin") as UserRole;
   const nameFromEmail = email.includes("@") ? email.split("@")[0] : "User";
   return {
     id,
@@ -354,6 +694,7 @@ export async function registerUser(input: RegisterInput): Promise<{ ok: true; us
   try {
     const payload = await requestJSON<{
       token: string;
+      refresh_token?: string;
       user: { id: string; name: string; email: string; tenant_slug: string };
     }>("/api/v1/auth/register", {
       method: "POST",
@@ -375,21 +716,40 @@ export async function registerUser(input: RegisterInput): Promise<{ ok: true; us
       tenantSlug: payload.user.tenant_slug,
       tenantName: input.tenantName,
       tenantLogoUrl: input.tenantLogoData || "",
-      role: (payload.user as { role?: "system_admin" | "org_admin" }).role || "org_admin",
+      role: (payload.user as { role?: UserRole }).role || "org_admin",
     };
-    writeSession({ token: payload.token, user });
+    writeSession({ token: payload.token, refreshToken: payload.refresh_token, user });
     return { ok: true, user };
   } catch (error) {
     return { ok: false, message: normalizeError(error) };
   }
 }
 
-export async function loginUser(input: LoginInput): Promise<{ ok: true; user: AuthUser } | { ok: false; message: string }> {
+type LoginResult =
+  | { ok: true; user: AuthUser }
+  | { ok: false; message: string; mfaChallenge?: string };
+
+type LoginPayload = {
+  token?: string;
+  refresh_token?: string;
+  mfa_required?: boolean;
+  challenge_token?: string;
+  user?: { name?: string; tenant_name?: string; tenant_logo?: string; role?: UserRole };
+};
+
+function sessionFromLoginPayload(payload: LoginPayload): AuthUser {
+  const user = userFromToken(payload.token || "");
+  if (payload.user?.name) user.name = payload.user.name;
+  if (payload.user?.tenant_name) user.tenantName = payload.user.tenant_name;
+  if (payload.user?.tenant_logo) user.tenantLogoUrl = payload.user.tenant_logo;
+  if (payload.user?.role) user.role = payload.user.role;
+  writeSession({ token: payload.token || "", refreshToken: payload.refresh_token, user });
+  return user;
+}
+
+export async function loginUser(input: LoginInput): Promise<LoginResult> {
   try {
-    const payload = await requestJSON<{
-      token: string;
-      user?: { name?: string; tenant_name?: string; tenant_logo?: string; role?: "system_admin" | "org_admin" };
-    }>("/api/v1/auth/login", {
+    const payload = await requestJSON<LoginPayload>("/api/v1/auth/login", {
       method: "POST",
       headers: { "Content-Type": "application/json" },
       body: JSON.stringify({
@@ -399,13 +759,23 @@ export async function loginUser(input: LoginInput): Promise<{ ok: true; user: Au
       }),
     });
 
-    const user = userFromToken(payload.token);
-    if (payload.user?.name) user.name = payload.user.name;
-    if (payload.user?.tenant_name) user.tenantName = payload.user.tenant_name;
-    if (payload.user?.tenant_logo) user.tenantLogoUrl = payload.user.tenant_logo;
-    if (payload.user?.role) user.role = payload.user.role;
-    writeSession({ token: payload.token, user });
-    return { ok: true, user };
+    if (payload.mfa_required && payload.challenge_token) {
+      return { ok: false, message: "Enter the code from your authenticator app.", mfaChallenge: payload.challenge_token };
+    }
+    return { ok: true, user: sessionFromLoginPayload(payload) };
+  } catch (error) {
+    return { ok: false, message: normalizeError(error) };
+  }
+}
+
+export async function completeMfaLogin(challengeToken: string, code: string): Promise<LoginResult> {
+  try {
+    const payload = await requestJSON<LoginPayload>("/api/v1/auth/mfa/challenge", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ challenge_token: challengeToken, code }),
+    });
+    return { ok: true, user: sessionFromLoginPayload(payload) };
   } catch (error) {
     return { ok: false, message: normalizeError(error) };
   }
@@ -427,6 +797,19 @@ export async function forgotPassword(input: ForgotPasswordInput): Promise<{ ok:
   }
 }
 
+export async function resetPassword(input: ResetPasswordInput): Promise<{ ok: true; message: string } | { ok: false; message: string }> {
+  try {
+    await requestJSON<{ status?: string }>("/api/v1/auth/reset-password", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ token: input.token, password: input.password }),
+    });
+    return { ok: true, message: "Password updated. Sign in with your new password." };
+  } catch (error) {
+    return { ok: false, message: normalizeError(error) };
+  }
+}
+
 export async function listProjects(): Promise<Project[]> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
@@ -439,6 +822,7 @@ export async function listProjects(): Promise<Project[]> {
 
 export async function createProject(input: {
   project_code?: string;
+  client_name?: string;
   name: string;
   status?: string;
   assignees?: string[];
@@ -462,6 +846,7 @@ export async function createProject(input: {
 export async function updateProject(input: {
   id: number;
   project_code?: string;
+  client_name?: string;
   name: string;
   status?: string;
   assignees?: string[];
@@ -480,6 +865,7 @@ export async function updateProject(input: {
     },
     body: JSON.stringify({
       project_code: input.project_code || "",
+      client_name: input.client_name || "",
       name: input.name,
       status: input.status,
       assignees: input.assignees || [],
@@ -500,10 +886,21 @@ export async function deleteProject(id: number): Promise<{ status: string }> {
   });
 }
 
-export async function listTasks(): Promise<TaskItem[]> {
+export async function listTasks(filters?: {
+  assignee?: string;
+  due_from?: string;
+  due_to?: string;
+  overdue?: boolean;
+}): Promise<TaskItem[]> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: TaskItem[] }>("/api/v1/tasks", {
+  const params = new URLSearchParams();
+  if (filters?.assignee) params.set("assignee", filters.assignee);
+  if (filters?.due_from) params.set("due_from", filters.due_from);
+  if (filters?.due_to) params.set("due_to", filters.due_to);
+  if (filters?.overdue) params.set("overdue", "true");
+  const query = params.toString();
+  const payload = await requestJSON<{ items: TaskItem[] }>(`/api/v1/tasks${query ? `?${query}` : ""}`, {
     method: "GET",
     headers: { Authorization: `Bearer ${token}` },
   });
@@ -519,7 +916,7 @@ export async function createTask(input: {
   phase?: string;
   subtasks?: string[];
   attachments?: string[];
-}): Promise<TaskItem> {
+} & TaskPlanInput): Promise<TaskItem> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
   return requestJSON<TaskItem>("/api/v1/tasks", {
@@ -540,9 +937,8 @@ export async function updateTask(input: {
   priority?: string;
   project_id: number;
   phase?: string;
-  subtasks?: string[];
   attachments?: string[];
-}): Promise<TaskItem> {
+} & TaskPlanInput): Promise<TaskItem> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
   return requestJSON<TaskItem>(`/api/v1/tasks/${input.id}`, {
@@ -558,8 +954,13 @@ export async function updateTask(input: {
       priority: input.priority,
       project_id: input.project_id,
       phase: input.phase || "",
-      subtasks: input.subtasks || [],
       attachments: input.attachments || [],
+      description: input.description || "",
+      assignees: input.assignees || [],
+      start_date: input.start_date || "",
+      due_date: input.due_date || "",
+      story_points: input.story_points ?? null,
+      estimated_hours: input.estimated_hours ?? null,
     }),
   });
 }
@@ -573,154 +974,592 @@ export async function deleteTask(id: number): Promise<{ status: string }> {
   });
 }
 
-export async function listTimesheets(): Promise<{ items: TimesheetEntry[]; summary: TimesheetSummary }> {
+export type TaskDependency = {
+  id: number;
+  project_id: number;
+  predecessor_id: number;
+  successor_id: number;
+  type: "FS" | "SS";
+  lag_days: number;
+  created_at: string;
+};
+
+export type ScheduledTask = {
+  task_id: number;
+  title: string;
+  status: string;
+  duration_days: number;
+  earliest_start: number;
+  earliest_finish: number;
+  latest_start: number;
+  latest_finish: number;
+  slack_days: number;
+  critical: boolean;
+  start_date?: string;
+  finish_date?: string;
+};
+
+export type ProjectSchedule = {
+  project_id: number;
+  start_date?: string;
+  due_date?: string;
+  finish_date?: string;
+  duration_days: number;
+  exceeds_due_date: boolean;
+  overrun_days: number;
+  critical_path: number[];
+  tasks: ScheduledTask[];
+  dependencies: TaskDependency[];
+};
+
+export async function getProjectSchedule(projectId: number): Promise<ProjectSchedule> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: TimesheetEntry[]; summary: TimesheetSummary }>("/api/v1/timesheets", {
+  return requestJSON<ProjectSchedule>(`/api/v1/projects/${projectId}/schedule`, {
     method: "GET",
     headers: { Authorization: `Bearer ${token}` },
   });
-  return {
-    items: payload.items || [],
-    summary: payload.summary || { billable_hours: 0, non_billable_hours: 0, total_hours: 0 },
-  };
 }
 
-export async function createTimesheet(input: {
-  project_id?: number | null;
-  task_id?: number | null;
-  work_date: string;
-  hours: number;
-  billable: boolean;
-  notes?: string;
-}): Promise<TimesheetEntry> {
+export async function createTaskDependency(
+  projectId: number,
+  input: { predecessor_id: number; successor_id: number; type?: "FS" | "SS"; lag_days?: number },
+): Promise<TaskDependency> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<TimesheetEntry>("/api/v1/timesheets", {
+  return requestJSON<TaskDependency>(`/api/v1/projects/${projectId}/dependencies`, {
     method: "POST",
     headers: {
       "Content-Type": "application/json",
       Authorization: `Bearer ${token}`,
     },
-    body: JSON.stringify({
-      project_id: input.project_id ?? null,
-      task_id: input.task_id ?? null,
-      work_date: input.work_date,
-      hours: input.hours,
-      billable: input.billable,
-      notes: input.notes || "",
-    }),
+    body: JSON.stringify(input),
   });
 }
 
-export async function deleteTimesheet(id: number): Promise<{ status: string }> {
+export async function deleteTaskDependency(projectId: number, dependencyId: number): Promise<{ status: string }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ status: string }>(`/api/v1/timesheets/${id}`, {
+  return requestJSON<{ status: string }>(`/api/v1/projects/${projectId}/dependencies/${dependencyId}`, {
     method: "DELETE",
     headers: { Authorization: `Bearer ${token}` },
   });
 }
 
-export async function deleteApprovalRequest(id: number): Promise<{ status: string }> {
+export type WorkflowState = { key: string; label?: string; terminal: boolean };
+
+export type WorkflowTransition = { from: string; to: string; required_fields?: string[] };
+
+export type Workflow = {
+  id: number;
+  project_id?: number;
+  entity: "task" | "project";
+  name: string;
+  states: WorkflowState[];
+  transitions: WorkflowTransition[];
+  created_at: string;
+  updated_at: string;
+};
+
+export type StatusChange = {
+  id: number;
+  from_status: string;
+  to_status: string;
+  actor_email: string;
+  created_at: string;
+};
+
+export async function listWorkflows(): Promise<{ items: Workflow[]; fields: Record<string, string[]> }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ status: string }>(`/api/v1/approvals/requests/${id}`, {
-    method: "DELETE",
+  return requestJSON<{ items: Workflow[]; fields: Record<string, string[]> }>("/api/v1/workflows", {
+    method: "GET",
     headers: { Authorization: `Bearer ${token}` },
   });
 }
 
-export async function deleteForumPost(id: number): Promise<{ status: string }> {
+export async function createWorkflow(input: {
+  entity: "task" | "project";
+  project_id?: number;
+  name?: string;
+  states: WorkflowState[];
+  transitions?: WorkflowTransition[];
+}): Promise<Workflow> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ status: string }>(`/api/v1/forum/posts/${id}`, {
-    method: "DELETE",
-    headers: { Authorization: `Bearer ${token}` },
+  return requestJSON<Workflow>("/api/v1/workflows", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(input),
   });
 }
 
-export async function deleteCodeShare(id: number): Promise<{ status: string }> {
+export async function updateWorkflow(
+  id: number,
+  input: { entity: "task" | "project"; name?: string; states: WorkflowState[]; transitions?: WorkflowTransition[] },
+): Promise<Workflow> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ status: string }>(`/api/v1/code-shares/${id}`, {
+  return requestJSON<Workflow>(`/api/v1/workflows/${id}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function deleteWorkflow(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/workflows/${id}`, {
     method: "DELETE",
     headers: { Authorization: `Bearer ${token}` },
   });
 }
 
-export async function listUsers(): Promise<TenantUser[]> {
+export async function getStatusHistory(entity: "task" | "project", id: number): Promise<StatusChange[]> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: TenantUser[] }>("/api/v1/users", {
+  const payload = await requestJSON<{ items: StatusChange[] }>(`/api/v1/${entity === "task" ? "tasks" : "projects"}/${id}/history`, {
     method: "GET",
     headers: { Authorization: `Bearer ${token}` },
   });
   return payload.items || [];
 }
 
-export async function listSessions(): Promise<SessionItem[]> {
+export async function createSubtask(
+  taskId: number,
+  input: { title: string; status?: string; assignee?: string; position?: number },
+): Promise<TaskSubtask> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: SessionItem[] }>("/api/v1/sessions", {
-    method: "GET",
-    headers: { Authorization: `Bearer ${token}` },
+  return requestJSON<TaskSubtask>(`/api/v1/tasks/${taskId}/subtasks`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(input),
   });
-  return payload.items || [];
 }
 
-export async function updateSessionAction(input: { user_id: string; action: "terminate" | "block" | "unblock" }): Promise<{ status: string }> {
+export async function updateSubtask(
+  taskId: number,
+  subtaskId: number,
+  input: { title: string; status?: string; assignee?: string },
+): Promise<TaskSubtask> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ status: string }>(`/api/v1/sessions/${input.user_id}`, {
+  return requestJSON<TaskSubtask>(`/api/v1/tasks/${taskId}/subtasks/${subtaskId}`, {
     method: "PUT",
     headers: {
       "Content-Type": "application/json",
       Authorization: `Bearer ${token}`,
     },
-    body: JSON.stringify({ action: input.action }),
+    body: JSON.stringify(input),
   });
 }
 
-export async function listApprovalRequests(): Promise<ApprovalRequest[]> {
+export async function deleteSubtask(taskId: number, subtaskId: number): Promise<{ status: string }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: ApprovalRequest[] }>("/api/v1/approvals/requests", {
-    method: "GET",
+  return requestJSON<{ status: string }>(`/api/v1/tasks/${taskId}/subtasks/${subtaskId}`, {
+    method: "DELETE",
     headers: { Authorization: `Bearer ${token}` },
   });
-  return payload.items || [];
 }
 
-export async function createApprovalRequest(input: {
-  project_id?: number | null;
-  note?: string;
-  hours?: number;
-}): Promise<ApprovalRequest> {
+export async function reorderSubtasks(
+  taskId: number,
+  ids: number[],
+): Promise<{ items: TaskSubtask[]; progress: { done: number; total: number } }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<ApprovalRequest>("/api/v1/approvals/requests", {
+  return requestJSON<{ items: TaskSubtask[]; progress: { done: number; total: number } }>(`/api/v1/tasks/${taskId}/subtasks/reorder`, {
     method: "POST",
     headers: {
       "Content-Type": "application/json",
       Authorization: `Bearer ${token}`,
     },
-    body: JSON.stringify({
-      project_id: input.project_id ?? null,
-      note: input.note || "",
-      hours: input.hours || 0,
-    }),
+    body: JSON.stringify({ ids }),
   });
 }
 
-export async function actionApprovalRequest(input: { id: number; action: "approve" | "reject" }): Promise<ApprovalRequest> {
+export async function listTimesheets(): Promise<{ items: TimesheetEntry[]; summary: TimesheetSummary }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<ApprovalRequest>(`/api/v1/approvals/requests/${input.id}/action`, {
-    method: "PUT",
-    headers: {
-      "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
+  const payload = await requestJSON<{ items: TimesheetEntry[]; summary: TimesheetSummary }>("/api/v1/timesheets", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return {
+    items: payload.items || [],
+    summary: payload.summary || { billable_hours: 0, non_billable_hours: 0, total_hours: 0 },
+  };
+}
+
+function timesheetBody(input: TimesheetInput): string {
+  return JSON.stringify({
+    project_id: input.project_id ?? null,
+    task_id: input.task_id ?? null,
+    work_date: input.work_date,
+    hours: input.hours,
+    billable: input.billable,
+    notes: input.notes || "",
+    start_time: input.start_time || "",
+    end_time: input.end_time || "",
+  });
+}
+
+export async function createTimesheet(input: TimesheetInput): Promise<TimesheetEntry> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetEntry>("/api/v1/timesheets", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: timesheetBody(input),
+  });
+}
+
+export async function updateTimesheet(id: number, input: TimesheetInput): Promise<TimesheetEntry> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetEntry>(`/api/v1/timesheets/${id}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: timesheetBody(input),
+  });
+}
+
+export async function deleteTimesheet(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/timesheets/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function getTimesheetPolicy(): Promise<TimesheetPolicy> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetPolicy>("/api/v1/timesheet-policy", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function updateTimesheetPolicy(input: TimesheetPolicy): Promise<TimesheetPolicy> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetPolicy>("/api/v1/timesheet-policy", {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({
+      max_hours_per_day: input.max_hours_per_day,
+      max_hours_per_week: input.max_hours_per_week,
+      max_future_days: input.max_future_days,
+      enforce_project_window: input.enforce_project_window,
+      block_closed_projects: input.block_closed_projects,
+      require_clock_times: input.require_clock_times,
+      prevent_overlaps: input.prevent_overlaps,
+    }),
+  });
+}
+
+export async function listBillingRates(): Promise<BillingRate[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ items: BillingRate[] }>("/api/v1/billing/rates", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+function billingRateBody(input: BillingRateInput): string {
+  return JSON.stringify({
+    scope: input.scope,
+    project_id: input.project_id ?? null,
+    task_id: input.task_id ?? null,
+    user_email: input.user_email || "",
+    hourly_rate: input.hourly_rate,
+    currency: input.currency,
+    effective_from: input.effective_from,
+    effective_to: input.effective_to || "",
+  });
+}
+
+export async function createBillingRate(input: BillingRateInput): Promise<BillingRate> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<BillingRate>("/api/v1/billing/rates", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: billingRateBody(input),
+  });
+}
+
+export async function updateBillingRate(id: number, input: BillingRateInput): Promise<BillingRate> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<BillingRate>(`/api/v1/billing/rates/${id}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: billingRateBody(input),
+  });
+}
+
+export async function deleteBillingRate(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/billing/rates/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+function billingReportParams(query: BillingReportQuery): URLSearchParams {
+  const params = new URLSearchParams({ from: query.from, to: query.to });
+  if (query.group_by?.length) params.set("group_by", query.group_by.join(","));
+  if (query.rounding) params.set("rounding", query.rounding);
+  if (query.increment) params.set("increment", String(query.increment));
+  if (query.lines) params.set("lines", "true");
+  return params;
+}
+
+export async function getBillingReport(query: BillingReportQuery): Promise<BillingReport> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<BillingReport>(`/api/v1/reports/billing?${billingReportParams(query).toString()}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function downloadBillingReportCSV(query: BillingReportQuery): Promise<Blob> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const params = billingReportParams(query);
+  params.set("format", "csv");
+  const response = await fetch(`${API_BASE}/api/v1/reports/billing?${params.toString()}`, {
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  if (!response.ok) {
+    const payload = (await response.json().catch(() => ({}))) as { error?: string };
+    throw new Error(payload.error || `Request failed with ${response.status}`);
+  }
+  return response.blob();
+}
+
+export async function getCurrentTimer(): Promise<Timer | null> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ timer: Timer | null }>("/api/v1/timers/current", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.timer || null;
+}
+
+export async function startTimer(input: { project_id?: number | null; task_id?: number | null; billable?: boolean; notes?: string }): Promise<Timer> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<Timer>("/api/v1/timers/start", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({
+      project_id: input.project_id ?? null,
+      task_id: input.task_id ?? null,
+      billable: input.billable ?? true,
+      notes: input.notes || "",
+    }),
+  });
+}
+
+export async function stopTimer(notes?: string): Promise<TimerStopResult> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimerStopResult>("/api/v1/timers/stop", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(notes === undefined ? {} : { notes }),
+  });
+}
+
+export async function heartbeatTimer(): Promise<Timer> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<Timer>("/api/v1/timers/heartbeat", {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function listTimesheetSubmissions(user?: "me"): Promise<TimesheetSubmission[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const query = user ? `?user=${user}` : "";
+  const payload = await requestJSON<{ items: TimesheetSubmission[] }>(`/api/v1/timesheets/submissions${query}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function submitTimesheetWeek(input: { week_of?: string; note?: string }): Promise<TimesheetSubmission> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetSubmission>("/api/v1/timesheets/submit", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({ week_of: input.week_of || "", note: input.note || "" }),
+  });
+}
+
+export async function reopenTimesheetSubmission(id: number): Promise<TimesheetSubmission> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<TimesheetSubmission>(`/api/v1/timesheets/submissions/${id}/reopen`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function deleteApprovalRequest(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/approvals/requests/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function deleteForumPost(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/forum/posts/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function deleteCodeShare(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/code-shares/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function listUsers(): Promise<TenantUser[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ items: TenantUser[] }>("/api/v1/users", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function listSessions(): Promise<SessionItem[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ items: SessionItem[] }>("/api/v1/sessions", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function updateSessionAction(input: { user_id: string; action: "terminate" | "block" | "unblock" | "unlock" }): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/sessions/${input.user_id}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({ action: input.action }),
+  });
+}
+
+export async function listApprovalRequests(): Promise<ApprovalRequest[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ items: ApprovalRequest[] }>("/api/v1/approvals/requests", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function createApprovalRequest(input: {
+  project_id?: number | null;
+  note?: string;
+  timesheet_ids?: number[];
+  user_email?: string;
+  from?: string;
+  to?: string;
+}): Promise<ApprovalRequest> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<ApprovalRequest>("/api/v1/approvals/requests", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({
+      project_id: input.project_id ?? null,
+      note: input.note || "",
+      timesheet_ids: input.timesheet_ids || [],
+      user_email: input.user_email || "",
+      from: input.from || "",
+      to: input.to || "",
+    }),
+  });
+}
+
+export async function actionApprovalRequest(input: { id: number; action: "approve" | "reject" }): Promise<ApprovalRequest> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<ApprovalRequest>(`/api/v1/approvals/requests/${input.id}/action`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
     },
     body: JSON.stringify({ action: input.action }),
   });
@@ -847,6 +1686,262 @@ export async function getSystemLogs(limit = 100): Promise<SystemLog[]> {
   return payload.items || [];
 }
 
+export async function getSystemEmailOutbox(
+  status = "",
+  limit = 100,
+): Promise<{ items: EmailOutboxMessage[]; counts: Record<EmailOutboxStatus, number> }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const query = new URLSearchParams({ limit: String(limit) });
+  if (status) query.set("status", status);
+  return requestJSON<{ items: EmailOutboxMessage[]; counts: Record<EmailOutboxStatus, number> }>(`/api/v1/system/email-outbox?${query.toString()}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function retryEmailOutboxMessage(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/system/email-outbox/${id}/retry`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export type EmailTemplateSource = {
+  subject: string;
+  text: string;
+  html: string;
+};
+
+export type EmailTemplateItem = {
+  name: string;
+  description: string;
+  overrides: string[];
+};
+
+export type EmailTemplateDetail = {
+  name: string;
+  description: string;
+  locale: string;
+  default_locale: string;
+  default: EmailTemplateSource;
+  override: EmailTemplateSource | null;
+  sample: Record<string, unknown>;
+};
+
+export type EmailBranding = {
+  name: string;
+  logo_url: string;
+  accent_color: string;
+};
+
+export async function listEmailTemplates(): Promise<{ items: EmailTemplateItem[]; locales: string[] }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ items: EmailTemplateItem[]; locales: string[] }>("/api/v1/email-templates", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function getEmailTemplate(name: string, locale = "en"): Promise<EmailTemplateDetail> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<EmailTemplateDetail>(`/api/v1/email-templates/${encodeURIComponent(name)}?locale=${encodeURIComponent(locale)}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function saveEmailTemplate(name: string, input: EmailTemplateSource & { locale: string }): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/email-templates/${encodeURIComponent(name)}`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function deleteEmailTemplate(name: string, locale: string): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/email-templates/${encodeURIComponent(name)}?locale=${encodeURIComponent(locale)}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function previewEmailTemplate(
+  name: string,
+  input: Partial<EmailTemplateSource> & { locale: string; data?: Record<string, unknown> },
+): Promise<{ locale: string; subject: string; text: string; html: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ locale: string; subject: string; text: string; html: string }>(`/api/v1/email-templates/${encodeURIComponent(name)}/preview`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function getEmailBranding(): Promise<EmailBranding> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<EmailBranding>("/api/v1/email-branding", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function updateEmailBranding(accentColor: string): Promise<EmailBranding> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<EmailBranding>("/api/v1/email-branding", {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+      Authorization: `Bearer ${token}`,
+    },
+    body: JSON.stringify({ accent_color: accentColor }),
+  });
+}
+
+// leader is the instance holding the scheduler lock ("" when none does);
+// instance is the replica that answered.
+export type SystemJobsResponse = {
+  items: SystemJob[];
+  leader: string;
+  leader_active: boolean;
+  instance: string;
+  is_leader: boolean;
+};
+
+export async function getSystemJobs(): Promise<SystemJobsResponse> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<SystemJobsResponse>("/api/v1/system/jobs", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function runSystemJob(name: string): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/system/jobs/${encodeURIComponent(name)}/run`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function getSystemEventSubscribers(): Promise<EventSubscriberState[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const payload = await requestJSON<{ items: EventSubscriberState[] }>("/api/v1/system/event-subscribers", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function listAuditLog(options?: { cursor?: string; subjectType?: string; limit?: number }): Promise<{ items: AuditEntry[]; nextCursor?: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const params = new URLSearchParams({ limit: String(options?.limit || 100) });
+  if (options?.cursor) params.set("cursor", options.cursor);
+  if (options?.subjectType) params.set("subject_type", options.subjectType);
+  const payload = await requestJSON<{ items: AuditEntry[]; next_cursor?: string }>(`/api/v1/audit-log?${params.toString()}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return { items: payload.items || [], nextCursor: payload.next_cursor || undefined };
+}
+
+export async function listWebhooks(): Promise<{ items: Webhook[]; events: string[] }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ items: Webhook[]; events: string[] }>("/api/v1/webhooks", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function createWebhook(input: WebhookInput): Promise<Webhook> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<Webhook>("/api/v1/webhooks", {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}`, "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function updateWebhook(id: number, input: WebhookInput): Promise<Webhook> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<Webhook>(`/api/v1/webhooks/${id}`, {
+    method: "PUT",
+    headers: { Authorization: `Bearer ${token}`, "Content-Type": "application/json" },
+    body: JSON.stringify(input),
+  });
+}
+
+export async function deleteWebhook(id: number): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/webhooks/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function rotateWebhookSecret(id: number): Promise<{ secret: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ secret: string }>(`/api/v1/webhooks/${id}/rotate-secret`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function pingWebhook(id: number): Promise<{ status: string; delivery_id: number }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string; delivery_id: number }>(`/api/v1/webhooks/${id}/ping`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function listWebhookDeliveries(id: number, status?: WebhookDelivery["status"]): Promise<WebhookDelivery[]> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const query = status ? `?status=${status}` : "";
+  const payload = await requestJSON<{ items: WebhookDelivery[] }>(`/api/v1/webhooks/${id}/deliveries${query}`, {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+  return payload.items || [];
+}
+
+export async function redeliverWebhook(id: number, deliveryId: number): Promise<{ status: string; delivery_id: number }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string; delivery_id: number }>(`/api/v1/webhooks/${id}/deliveries/${deliveryId}/redeliver`, {
+    method: "POST",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
 export async function getSystemTenants(): Promise<SystemTenant[]> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
@@ -872,10 +1967,10 @@ export async function createSystemUpdate(input: {
   title: string;
   feature_brief: string;
   expectations: string;
-}): Promise<{ item: SystemUpdate; recipients: number; sent: number; failed: number; mail_status: string }> {
+}): Promise<{ item: SystemUpdate; recipients: number; queued: number; failed: number; mail_status: string }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ item: SystemUpdate; recipients: number; sent: number; failed: number; mail_status: string }>("/api/v1/system/updates", {
+  return requestJSON<{ item: SystemUpdate; recipients: number; queued: number; failed: number; mail_status: string }>("/api/v1/system/updates", {
     method: "POST",
     headers: {
       "Content-Type": "application/json",
@@ -891,10 +1986,10 @@ export async function updateSystemUpdate(input: {
   title: string;
   feature_brief: string;
   expectations: string;
-}): Promise<{ item: SystemUpdate; recipients: number; sent: number; failed: number; mail_status: string }> {
+}): Promise<{ item: SystemUpdate; recipients: number; queued: number; failed: number; mail_status: string }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  return requestJSON<{ item: SystemUpdate; recipients: number; sent: number; failed: number; mail_status: string }>(`/api/v1/system/updates/${input.id}`, {
+  return requestJSON<{ item: SystemUpdate; recipients: number; queued: number; failed: number; mail_status: string }>(`/api/v1/system/updates/${input.id}`, {
     method: "PUT",
     headers: {
       "Content-Type": "application/json",
@@ -974,14 +2069,116 @@ export async function sendSupportRequest(input: { subject: string; message: stri
   });
 }
 
-export async function listNotifications(limit = 30): Promise<{ items: AppNotification[]; summary?: NotificationSummary }> {
+export async function listNotifications(
+  limit = 30,
+  options?: { cursor?: string; type?: string; status?: "unread" | "read"; archived?: boolean },
+): Promise<{ items: AppNotification[]; summary?: NotificationSummary; nextCursor?: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const params = new URLSearchParams({ limit: String(limit) });
+  if (options?.cursor) params.set("cursor", options.cursor);
+  if (options?.type) params.set("type", options.type);
+  if (options?.status) params.set("status", options.status);
+  if (options?.archived) params.set("archived", "true");
+  const payload = await requestJSON<{ items: AppNotification[]; summary?: NotificationSummary; next_cursor?: string }>(
+    `/api/v1/notifications?${params.toString()}`,
+    {
+      method: "GET",
+      headers: { Authorization: `Bearer ${token}` },
+    },
+  );
+  return { items: payload.items || [], summary: payload.summary || undefined, nextCursor: payload.next_cursor || undefined };
+}
+
+export async function getUnreadNotificationCount(): Promise<{ unread: number; by_type: Record<string, number> }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ unread: number; by_type: Record<string, number> }>("/api/v1/notifications/unread-count", {
+    method: "GET",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function markAllNotificationsRead(type?: string): Promise<{ status: string; updated: number }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  const query = type ? `?type=${encodeURIComponent(type)}` : "";
+  return requestJSON<{ status: string; updated: number }>(`/api/v1/notifications/read-all${query}`, {
+    method: "PUT",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function archiveNotification(id: string, restore = false): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>(`/api/v1/notifications/${id}/archive${restore ? "?restore=true" : ""}`, {
+    method: "PUT",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function deleteNotification(id: string): Promise<{ status: string }> {
   const token = getAuthToken();
   if (!token) throw new Error("Please login first.");
-  const payload = await requestJSON<{ items: AppNotification[]; summary?: NotificationSummary }>(`/api/v1/notifications?limit=${limit}`, {
+  return requestJSON<{ status: string }>(`/api/v1/notifications/${id}`, {
+    method: "DELETE",
+    headers: { Authorization: `Bearer ${token}` },
+  });
+}
+
+export async function getNotificationPreferences(): Promise<NotificationPreferences> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<NotificationPreferences>("/api/v1/notifications/preferences", {
     method: "GET",
     headers: { Authorization: `Bearer ${token}` },
   });
-  return { items: payload.items || [], summary: payload.summary };
+}
+
+export async function updateNotificationPreferences(channels: Record<string, NotificationChannel>): Promise<{ status: string }> {
+  const token = getAuthToken();
+  if (!token) throw new Error("Please login first.");
+  return requestJSON<{ status: string }>("/api/v1/notifications/preferences", {
+    method: "PUT",
+    headers: { Authorization: `Bearer ${token}`, "Content-Type": "application/json" },
+    body: JSON.stringify({ channels }),
+  });
+}
+
+// EventSource cannot send headers, so the stream takes the access token as a
+// query parameter. onError fires when the connection drops or the session ends;
+// callers should refresh and reopen.
+export function openNotificationStream(
+  onNotification: (item: AppNotification) => void,
+  onError?: (sessionEnded: boolean) => void,
+  lastEventId?: string,
+): EventSource | null {
+  const token = getAuthToken();
+  if (!token || typeof window === "undefined" || typeof EventSource === "undefined") return null;
+  const params = new URLSearchParams({ access_token: token });
+  if (lastEventId) params.set("last_event_id", lastEventId);
+  const source = new EventSource(`${API_BASE}/api/v1/notifications/stream?${params.toString()}`);
+  source.addEventListener("notification", (event) => {
+    try {
+      onNotification(JSON.parse((event as MessageEvent).data) as AppNotification);
+    } catch {
+      // ignore malformed events
+    }
+  });
+  source.addEventListener("session_ended", () => {
+    source.close();
+    onError?.(true);
+  });
+  source.onerror = () => {
+    source.close();
+    onError?.(false);
+  };
+  return source;
+}
+
+export async function refreshNotificationToken(): Promise<boolean> {
+  return (await refreshAccessToken()) !== null;
 }
 
 export async function markNotificationRead(id: string): Promise<{ status: string }> {
@@ -1,8 +1,8 @@
 "use client";
 
-import { FormEvent, useMemo, useState } from "react";
+import { FormEvent, useEffect, useMemo, useState } from "react";
 import { useRouter } from "next/navigation";
-import { forgotPassword, loginUser, registerUser } from "./auth";
+import { completeMfaLogin, forgotPassword, loginUser, registerUser, resetPassword } from "./auth";
 
 type Mode = "login" | "register";
 
@@ -20,8 +20,23 @@ export default function AuthPage() {
   const [forgotLoading, setForgotLoading] = useState(false);
   const [forgotMessage, setForgotMessage] = useState("");
   const [error, setError] = useState("");
+  const [resetToken, setResetToken] = useState("");
+  const [mfaChallenge, setMfaChallenge] = useState("");
+  const [mfaCode, setMfaCode] = useState("");
 
-  const title = useMemo(() => (mode === "login" ? "Welcome back" : "Create your workspace"), [mode]);
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get("reset");
+    if (token) {
+      setMode("login");
+      setResetToken(token);
+    }
+  }, []);
+
+  const title = useMemo(() => {
+    if (resetToken) return "Choose a new password";
+    if (mfaChallenge) return "Two-factor verification";
+    return mode === "login" ? "Welcome back" : "Create your workspace";
+  }, [mode, resetToken, mfaChallenge]);
   const heroImage =
     mode === "login"
       ? "https://images.unsplash.com/photo-1497366754035-f200968a6e72?auto=format&fit=crop&w=1400&q=80"
@@ -32,13 +47,33 @@ export default function AuthPage() {
     setError("");
     setLoading(true);
 
-    const result =
-      mode === "login"
+    if (resetToken) {
+      const reset = await resetPassword({ token: resetToken, password });
+      setLoading(false);
+      if (!reset.ok) {
+        setError(reset.message);
+        return;
+      }
+      setResetToken("");
+      setPassword("");
+      setForgotMessage(reset.message);
+      window.history.replaceState(null, "", window.location.pathname);
+      return;
+    }
+
+    const result = mfaChallenge
+      ? await completeMfaLogin(mfaChallenge, mfaCode)
+      : mode === "login"
         ? await loginUser({ email, password })
         : await registerUser({ tenantSlug, tenantName, tenantLogoData, name, email, password });
 
     setLoading(false);
     if (!result.ok) {
+      if ("mfaChallenge" in result && result.mfaChallenge) {
+        setMfaChallenge(result.mfaChallenge);
+        setForgotMessage(result.message);
+        return;
+      }
       setError(result.message);
       return;
     }
@@ -187,16 +222,20 @@ export default function AuthPage() {
               </>
             ) : null}
 
-            <label className="mt-4 block text-sm font-medium text-slate-700">Email</label>
-            <input
-              type="email"
-              value={email}
-              onChange={(event) => setEmail(event.target.value)}
-              required
-              className="mt-1 w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-400"
-            />
+            {!resetToken ? (
+              <>
+                <label className="mt-4 block text-sm font-medium text-slate-700">Email</label>
+                <input
+                  type="email"
+                  value={email}
+                  onChange={(event) => setEmail(event.target.value)}
+                  required
+                  className="mt-1 w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-400"
+                />
+              </>
+            ) : null}
 
-            <label className="mt-4 block text-sm font-medium text-slate-700">Password</label>
+            <label className="mt-4 block text-sm font-medium text-slate-700">{resetToken ? "New Password" : "Password"}</label>
             <div className="mt-1 flex gap-2">
               <input
                 type={showPassword ? "text" : "password"}
@@ -229,7 +268,23 @@ export default function AuthPage() {
               </button>
             </div>
 
-            {mode === "login" ? (
+            {mfaChallenge ? (
+              <>
+                <label className="mt-4 block text-sm font-medium text-slate-700">Authentication Code</label>
+                <input
+                  type="text"
+                  inputMode="numeric"
+                  autoComplete="one-time-code"
+                  value={mfaCode}
+                  onChange={(event) => setMfaCode(event.target.value)}
+                  required
+                  placeholder="123456 or recovery code"
+                  className="mt-1 w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-400"
+                />
+              </>
+            ) : null}
+
+            {mode === "login" && !resetToken && !mfaChallenge ? (
               <button
                 type="button"
                 onClick={onForgotPassword}
@@ -248,7 +303,7 @@ export default function AuthPage() {
               disabled={loading}
               className="mt-5 w-full rounded-lg bg-slate-900 px-4 py-2.5 text-sm font-medium text-white disabled:opacity-60"
             >
-              {loading ? "Please wait..." : mode === "login" ? "Sign In" : "Create Account"}
+              {loading ? "Please wait..." : resetToken ? "Update Password" : mfaChallenge ? "Verify" : mode === "login" ? "Sign In" : "Create Account"}
             </button>
           </form>
         </section>
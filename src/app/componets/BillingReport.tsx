@@ -0,0 +1,281 @@
+"use client";
+
+import { FormEvent, useCallback, useEffect, useState } from "react";
+import {
+  BillingRate,
+  BillingRateScope,
+  BillingReport as BillingReportData,
+  BillingReportQuery,
+  createBillingRate,
+  deleteBillingRate,
+  downloadBillingReportCSV,
+  getBillingReport,
+  listBillingRates,
+  Project,
+} from "../auth/auth";
+import { LoadingSpinner } from "./LoadingSpinner";
+
+type BillingReportProps = {
+  projects: Project[];
+};
+
+function monthStart(): string {
+  const now = new Date();
+  return new Date(Date.UTC(now.getFullYear(), now.getMonth(), 1)).toISOString().slice(0, 10);
+}
+
+export function BillingReport({ projects }: BillingReportProps) {
+  const [from, setFrom] = useState(monthStart);
+  const [to, setTo] = useState(() => new Date().toISOString().slice(0, 10));
+  const [groupBy, setGroupBy] = useState<"client" | "project" | "user">("project");
+  const [rounding, setRounding] = useState<"none" | "up" | "down" | "nearest">("none");
+  const [increment, setIncrement] = useState(15);
+  const [report, setReport] = useState<BillingReportData | null>(null);
+  const [rates, setRates] = useState<BillingRate[]>([]);
+  const [loading, setLoading] = useState(false);
+  const [error, setError] = useState("");
+
+  const [rateScope, setRateScope] = useState<BillingRateScope>("tenant");
+  const [rateTarget, setRateTarget] = useState("");
+  const [rateAmount, setRateAmount] = useState("");
+  const [rateCurrency, setRateCurrency] = useState("USD");
+  const [rateFrom, setRateFrom] = useState(() => new Date().toISOString().slice(0, 10));
+
+  const query = useCallback((): BillingReportQuery => {
+    const dims: BillingReportQuery["group_by"] = groupBy === "client" ? ["client"] : groupBy === "project" ? ["client", "project"] : ["client", "project", "user"];
+    return { from, to, group_by: dims, rounding, increment: rounding === "none" ? undefined : increment };
+  }, [from, to, groupBy, rounding, increment]);
+
+  const load = useCallback(async () => {
+    setLoading(true);
+    setError("");
+    try {
+      const [reportData, rateItems] = await Promise.all([getBillingReport(query()), listBillingRates()]);
+      setReport(reportData);
+      setRates(rateItems);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to load billing report.");
+    } finally {
+      setLoading(false);
+    }
+  }, [query]);
+
+  useEffect(() => {
+    void load();
+  }, [load]);
+
+  async function onExport() {
+    setError("");
+    try {
+      const blob = await downloadBillingReportCSV(query());
+      const link = document.createElement("a");
+      link.href = URL.createObjectURL(blob);
+      link.download = `pulseforge-billing-${from}-${to}.csv`;
+      link.click();
+      URL.revokeObjectURL(link.href);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to export billing report.");
+    }
+  }
+
+  async function onAddRate(event: FormEvent<HTMLFormElement>) {
+    event.preventDefault();
+    setError("");
+    const amount = Number(rateAmount);
+    if (!Number.isFinite(amount) || amount < 0) {
+      setError("Rate must be a positive number.");
+      return;
+    }
+    try {
+      await createBillingRate({
+        scope: rateScope,
+        project_id: rateScope === "project" && rateTarget ? Number(rateTarget) : null,
+        task_id: rateScope === "task" && rateTarget ? Number(rateTarget) : null,
+        user_email: rateScope === "user" ? rateTarget.trim() : "",
+        hourly_rate: amount,
+        currency: rateCurrency.trim().toUpperCase(),
+        effective_from: rateFrom,
+      });
+      setRateAmount("");
+      setRateTarget("");
+      await load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to add rate.");
+    }
+  }
+
+  async function onDeleteRate(id: number) {
+    setError("");
+    try {
+      await deleteBillingRate(id);
+      await load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to delete rate.");
+    }
+  }
+
+  function rateTargetLabel(rate: BillingRate): string {
+    const project = rate.project_id ? projects.find((item) => item.id === rate.project_id)?.name || `Project #${rate.project_id}` : "";
+    if (rate.scope === "task") return `Task #${rate.task_id}`;
+    if (rate.scope === "user") return project ? `${rate.user_email} on ${project}` : rate.user_email || "-";
+    if (rate.scope === "project") return project;
+    return "All work";
+  }
+
+  return (
+    <div className="space-y-4 rounded-xl border border-slate-200 bg-white p-4">
+      <div className="flex flex-wrap items-end justify-between gap-3">
+        <div>
+          <h3 className="text-sm font-semibold text-slate-900">Billing</h3>
+          <p className="text-xs text-slate-500">Approved billable hours priced with the effective rate for each entry.</p>
+        </div>
+        <div className="flex flex-wrap items-center gap-2 text-sm">
+          <input type="date" value={from} onChange={(event) => setFrom(event.target.value)} className="rounded-lg border border-slate-300 px-3 py-2" aria-label="From" />
+          <input type="date" value={to} onChange={(event) => setTo(event.target.value)} className="rounded-lg border border-slate-300 px-3 py-2" aria-label="To" />
+          <select value={groupBy} onChange={(event) => setGroupBy(event.target.value as typeof groupBy)} className="rounded-lg border border-slate-300 bg-white px-3 py-2" aria-label="Group by">
+            <option value="client">By client</option>
+            <option value="project">By project</option>
+            <option value="user">By user</option>
+          </select>
+          <select value={rounding} onChange={(event) => setRounding(event.target.value as typeof rounding)} className="rounded-lg border border-slate-300 bg-white px-3 py-2" aria-label="Rounding">
+            <option value="none">No rounding</option>
+            <option value="up">Round up</option>
+            <option value="nearest">Round nearest</option>
+            <option value="down">Round down</option>
+          </select>
+          {rounding !== "none" ? (
+            <select value={increment} onChange={(event) => setIncrement(Number(event.target.value))} className="rounded-lg border border-slate-300 bg-white px-3 py-2" aria-label="Increment">
+              {[6, 10, 15, 30, 60].map((minutes) => (
+                <option key={minutes} value={minutes}>
+                  {minutes} min
+                </option>
+              ))}
+            </select>
+          ) : null}
+          <button type="button" onClick={() => void onExport()} className="rounded-lg border border-slate-300 bg-white px-3 py-2 font-medium text-slate-700">
+            Export Billing CSV
+          </button>
+        </div>
+      </div>
+
+      {error ? <p className="text-sm text-rose-600">{error}</p> : null}
+      {loading ? <LoadingSpinner label="Loading billing..." /> : null}
+
+      {report ? (
+        <>
+          <div className="flex flex-wrap gap-3">
+            {report.totals.map((total) => (
+              <article key={total.currency} className="rounded-lg border border-slate-200 px-4 py-3">
+                <p className="text-xs uppercase tracking-wide text-slate-500">{total.currency}</p>
+                <p className="text-xl font-semibold text-slate-900">{total.amount.toFixed(2)}</p>
+                <p className="text-xs text-slate-500">{total.billed_hours.toFixed(2)}h billed</p>
+              </article>
+            ))}
+            {report.unpriced_hours > 0 ? (
+              <article className="rounded-lg border border-amber-200 bg-amber-50 px-4 py-3">
+                <p className="text-xs uppercase tracking-wide text-amber-700">No rate</p>
+                <p className="text-xl font-semibold text-amber-800">{report.unpriced_hours.toFixed(2)}h</p>
+                <p className="text-xs text-amber-700">add a rate to price these hours</p>
+              </article>
+            ) : null}
+          </div>
+          <div className="overflow-x-auto">
+            <table className="w-full min-w-[760px] text-left text-sm">
+              <thead className="text-slate-500">
+                <tr className="border-b border-slate-200">
+                  <th className="px-2 py-2 font-medium">Client</th>
+                  <th className="px-2 py-2 font-medium">Project</th>
+                  <th className="px-2 py-2 font-medium">User</th>
+                  <th className="px-2 py-2 font-medium">Hours</th>
+                  <th className="px-2 py-2 font-medium">Billed</th>
+                  <th className="px-2 py-2 font-medium">Amount</th>
+                </tr>
+              </thead>
+              <tbody>
+                {report.groups.map((group, index) => (
+                  <tr key={`${group.client_name}-${group.project_id}-${group.user_email}-${group.currency}-${index}`} className="border-b border-slate-100">
+                    <td className="px-2 py-2 text-slate-700">{group.client_name || "-"}</td>
+                    <td className="px-2 py-2 text-slate-700">{group.project_name || "-"}</td>
+                    <td className="px-2 py-2 text-slate-700">{group.user_email || "-"}</td>
+                    <td className="px-2 py-2 text-slate-700">{group.hours.toFixed(2)}h</td>
+                    <td className="px-2 py-2 text-slate-700">{(group.billed_hours + group.unpriced_hours).toFixed(2)}h</td>
+                    <td className="px-2 py-2 font-medium text-slate-900">{group.currency ? `${group.amount.toFixed(2)} ${group.currency}` : <span className="text-amber-700">No rate</span>}</td>
+                  </tr>
+                ))}
+              </tbody>
+            </table>
+          </div>
+        </>
+      ) : null}
+
+      <div className="border-t border-slate-200 pt-4">
+        <h4 className="mb-2 text-sm font-semibold text-slate-900">Rates</h4>
+        <p className="mb-3 text-xs text-slate-500">The most specific rate wins: task, user on a project, project, user, then the workspace default. A new rate takes over from its effective date.</p>
+        <table className="mb-3 w-full text-left text-sm">
+          <tbody>
+            {rates.map((rate) => (
+              <tr key={rate.id} className="border-b border-slate-100">
+                <td className="px-2 py-2 capitalize text-slate-700">{rate.scope}</td>
+                <td className="px-2 py-2 text-slate-700">{rateTargetLabel(rate)}</td>
+                <td className="px-2 py-2 font-medium text-slate-900">
+                  {rate.hourly_rate.toFixed(2)} {rate.currency}/h
+                </td>
+                <td className="px-2 py-2 text-slate-500">
+                  from {rate.effective_from}
+                  {rate.effective_to ? ` to ${rate.effective_to}` : ""}
+                </td>
+                <td className="px-2 py-2 text-right">
+                  <button type="button" onClick={() => void onDeleteRate(rate.id)} className="text-xs font-medium text-rose-600">
+                    Delete
+                  </button>
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+        <form onSubmit={onAddRate} className="flex flex-wrap items-center gap-2 text-sm">
+          <select
+            value={rateScope}
+            onChange={(event) => {
+              setRateScope(event.target.value as BillingRateScope);
+              setRateTarget("");
+            }}
+            className="rounded-lg border border-slate-300 bg-white px-3 py-2"
+            aria-label="Rate scope"
+          >
+            <option value="tenant">Workspace</option>
+            <option value="project">Project</option>
+            <option value="user">User</option>
+            <option value="task">Task</option>
+          </select>
+          {rateScope === "project" ? (
+            <select value={rateTarget} onChange={(event) => setRateTarget(event.target.value)} className="rounded-lg border border-slate-300 bg-white px-3 py-2" required aria-label="Project">
+              <option value="">Select project</option>
+              {projects.map((project) => (
+                <option key={project.id} value={project.id}>
+                  {project.name}
+                </option>
+              ))}
+            </select>
+          ) : null}
+          {rateScope === "user" || rateScope === "task" ? (
+            <input
+              type={rateScope === "user" ? "email" : "number"}
+              value={rateTarget}
+              onChange={(event) => setRateTarget(event.target.value)}
+              placeholder={rateScope === "user" ? "user@company.com" : "Task ID"}
+              className="rounded-lg border border-slate-300 px-3 py-2"
+              required
+            />
+          ) : null}
+          <input type="number" min={0} step={0.01} value={rateAmount} onChange={(event) => setRateAmount(event.target.value)} placeholder="Hourly rate" className="w-32 rounded-lg border border-slate-300 px-3 py-2" required />
+          <input type="text" maxLength={3} value={rateCurrency} onChange={(event) => setRateCurrency(event.target.value)} className="w-20 rounded-lg border border-slate-300 px-3 py-2 uppercase" aria-label="Currency" required />
+          <input type="date" value={rateFrom} onChange={(event) => setRateFrom(event.target.value)} className="rounded-lg border border-slate-300 px-3 py-2" aria-label="Effective from" required />
+          <button type="submit" className="rounded-lg bg-slate-900 px-3 py-2 font-medium text-white">
+            Add Rate
+          </button>
+        </form>
+      </div>
+    </div>
+  );
+}
@@ -1,7 +1,7 @@
 "use client";
 
 import { useCallback, useEffect, useMemo, useState } from "react";
-import { AppNotification, NotificationSummary, listNotifications, markNotificationRead, sendSupportRequest } from "../auth/auth";
+import { AppNotification, NotificationSummary, listNotifications, markAllNotificationsRead, markNotificationRead, openNotificationStream, refreshNotificationToken, sendSupportRequest, type UserRole } from "../auth/auth";
 
 type PageKey = "dashboard" | "projects" | "tasks" | "timesheets" | "analytics" | "reports" | "calendar" | "approvals" | "forum" | "devshare" | "issues" | "profile" | "settings" | "admin";
 
@@ -27,7 +27,7 @@ type NavProps = {
   onNavigate: (page: PageKey) => void;
   userName: string;
   orgId?: string;
-  role: "system_admin" | "org_admin";
+  role: UserRole;
   isSystemAdmin: boolean;
   searchQuery: string;
   onSearchChange: (value: string) => void;
@@ -56,7 +56,7 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
       setNotifications([
         {
           id: "notif-load-error",
-          tenant_id: orgId || "",
+          tenant_id: 0,
           recipient_email: userName,
           type: "system",
           title: "Notifications unavailable",
@@ -74,15 +74,47 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
       await refreshNotifications();
     };
     void run();
+    // The stream delivers new items; polling only keeps the summary fresh.
     const timer = window.setInterval(() => {
       void run();
-    }, 60000);
+    }, 300000);
     return () => {
       mounted = false;
       window.clearInterval(timer);
     };
   }, [refreshNotifications]);
 
+  useEffect(() => {
+    let closed = false;
+    let source: EventSource | null = null;
+    let retry: number | undefined;
+    let lastId: string | undefined;
+    const connect = () => {
+      if (closed) return;
+      source = openNotificationStream(
+        (item) => {
+          lastId = item.id;
+          setNotifications((prev) => (prev.some((existing) => existing.id === item.id) ? prev : [item, ...prev].slice(0, 40)));
+        },
+        (sessionEnded) => {
+          if (closed || sessionEnded) return;
+          // A dropped stream usually means the access token expired.
+          retry = window.setTimeout(async () => {
+            await refreshNotificationToken();
+            connect();
+          }, 5000);
+        },
+        lastId,
+      );
+    };
+    connect();
+    return () => {
+      closed = true;
+      window.clearTimeout(retry);
+      source?.close();
+    };
+  }, [orgId, userName]);
+
   const pageList: Array<{ key: PageKey; label: string }> =
     role === "system_admin"
       ? [
@@ -224,7 +256,7 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
               ) : null}
             </button>
 
-            {role === "org_admin" ? (
+            {role !== "system_admin" ? (
               <button
                 type="button"
                 onClick={() => setSupportOpen((prev) => !prev)}
@@ -238,7 +270,7 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
               </button>
             ) : null}
 
-            {role === "org_admin" ? (
+            {role !== "system_admin" ? (
               <button
                 type="button"
                 onClick={() => setHelpOpen((prev) => !prev)}
@@ -259,7 +291,7 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
             onClick={() => setProfileOpen((prev) => !prev)}
             className={`inline-flex h-9 w-9 items-center justify-center rounded-full border ${isSystemAdmin ? "border-sky-200 bg-sky-50 text-slate-800 hover:bg-sky-100" : "border-slate-200 bg-white text-slate-700 hover:bg-slate-50"}`}
             aria-label="Open profile"
-            title={role === "org_admin" ? "Profile" : "Account settings"}
+            title={role !== "system_admin" ? "Profile" : "Account settings"}
           >
             <svg viewBox="0 0 24 24" className="h-4 w-4" fill="none" stroke="currentColor" strokeWidth="1.8">
               <circle cx="12" cy="8" r="3.1" />
@@ -277,6 +309,21 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
             <div className="flex items-center justify-between border-b border-slate-200 px-4 py-3">
               <p className="text-sm font-semibold text-slate-900">Notifications</p>
               <div className="flex items-center gap-3">
+                <button
+                  type="button"
+                  onClick={async () => {
+                    try {
+                      await markAllNotificationsRead();
+                      const now = new Date().toISOString();
+                      setNotifications((prev) => prev.map((n) => (n.read_at ? n : { ...n, read_at: now })));
+                    } catch {
+                      // keep drawer usable if read state update fails
+                    }
+                  }}
+                  className="text-xs text-slate-500"
+                >
+                  Mark all read
+                </button>
                 <button type="button" onClick={() => void refreshNotifications()} className="text-xs text-slate-500">
                   Sync
                 </button>
@@ -396,7 +443,7 @@ export function Nav({ currentPage, onNavigate, userName, orgId, role, isSystemAd
                   type="button"
                   onClick={() => {
                     setProfileOpen(false);
-                    onNavigate(role === "org_admin" ? "profile" : "settings");
+                    onNavigate(role !== "system_admin" ? "profile" : "settings");
                   }}
                   className="w-full rounded-md border border-slate-200 px-3 py-2 text-sm text-slate-700 hover:bg-slate-50"
                 >
@@ -0,0 +1,181 @@
+"use client";
+
+import { FormEvent, useCallback, useEffect, useState } from "react";
+import { createTaskDependency, deleteTaskDependency, getProjectSchedule, ProjectSchedule } from "../auth/auth";
+import { LoadingSpinner } from "./LoadingSpinner";
+
+type ProjectGanttProps = {
+  projectId: number;
+  editable?: boolean;
+};
+
+export function ProjectGantt({ projectId, editable = false }: ProjectGanttProps) {
+  const [schedule, setSchedule] = useState<ProjectSchedule | null>(null);
+  const [loading, setLoading] = useState(true);
+  const [error, setError] = useState("");
+  const [predecessorId, setPredecessorId] = useState("");
+  const [successorId, setSuccessorId] = useState("");
+  const [dependencyType, setDependencyType] = useState<"FS" | "SS">("FS");
+
+  const load = useCallback(async () => {
+    setLoading(true);
+    setError("");
+    try {
+      setSchedule(await getProjectSchedule(projectId));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to load schedule.");
+    } finally {
+      setLoading(false);
+    }
+  }, [projectId]);
+
+  useEffect(() => {
+    void load();
+  }, [load]);
+
+  async function onAddDependency(event: FormEvent<HTMLFormElement>) {
+    event.preventDefault();
+    if (!predecessorId || !successorId) return;
+    setError("");
+    try {
+      await createTaskDependency(projectId, {
+        predecessor_id: Number(predecessorId),
+        successor_id: Number(successorId),
+        type: dependencyType,
+      });
+      setPredecessorId("");
+      setSuccessorId("");
+      await load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to add dependency.");
+    }
+  }
+
+  async function onRemoveDependency(id: number) {
+    setError("");
+    try {
+      await deleteTaskDependency(projectId, id);
+      await load();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to remove dependency.");
+    }
+  }
+
+  if (loading && !schedule) return <LoadingSpinner label="Loading schedule..." />;
+  if (!schedule) return error ? <p className="text-sm text-rose-600">{error}</p> : null;
+
+  const span = Math.max(schedule.duration_days, 1);
+  const titleByID = new Map(schedule.tasks.map((task) => [task.task_id, task.title]));
+  const ordered = [...schedule.tasks].sort((a, b) => a.earliest_start - b.earliest_start || a.task_id - b.task_id);
+
+  return (
+    <div className="space-y-3">
+      <div className="flex flex-wrap items-center gap-3 text-xs text-slate-600">
+        <span>
+          Planned finish: <strong className="text-slate-900">{schedule.finish_date?.slice(0, 10) || "-"}</strong>
+        </span>
+        <span>
+          Due: <strong className="text-slate-900">{schedule.due_date?.slice(0, 10) || "-"}</strong>
+        </span>
+        <span>{schedule.critical_path.length} critical task(s)</span>
+        {schedule.exceeds_due_date ? (
+          <span className="rounded bg-rose-100 px-2 py-1 font-semibold text-rose-700">
+            Critical path overruns the due date by {schedule.overrun_days} day(s)
+          </span>
+        ) : null}
+      </div>
+      {error ? <p className="text-sm text-rose-600">{error}</p> : null}
+
+      {ordered.length === 0 ? (
+        <p className="text-sm text-slate-500">No tasks to schedule yet.</p>
+      ) : (
+        <div className="space-y-1">
+          {ordered.map((task) => (
+            <div key={task.task_id} className="grid grid-cols-[180px_1fr] items-center gap-2 text-xs">
+              <p className="truncate text-slate-700" title={task.title}>
+                {task.title}
+              </p>
+              <div className="relative h-5 rounded bg-slate-100">
+                <div
+                  className={`absolute top-0 h-5 rounded ${task.critical ? "bg-rose-500" : "bg-sky-400"}`}
+                  style={{
+                    left: `${(task.earliest_start / span) * 100}%`,
+                    width: `${Math.max((task.duration_days / span) * 100, 1)}%`,
+                  }}
+                  title={`${task.start_date?.slice(0, 10) || task.earliest_start} → ${task.finish_date?.slice(0, 10) || task.earliest_finish} · slack ${task.slack_days}d`}
+                />
+                {task.slack_days > 0 ? (
+                  <div
+                    className="absolute top-2 h-1 rounded bg-sky-200"
+                    style={{
+                      left: `${(task.earliest_finish / span) * 100}%`,
+                      width: `${(task.slack_days / span) * 100}%`,
+                    }}
+                  />
+                ) : null}
+              </div>
+            </div>
+          ))}
+        </div>
+      )}
+
+      {schedule.dependencies.length > 0 ? (
+        <ul className="space-y-1 text-xs text-slate-600">
+          {schedule.dependencies.map((dep) => (
+            <li key={dep.id} className="flex items-center gap-2">
+              <span>
+                {titleByID.get(dep.predecessor_id) || `Task ${dep.predecessor_id}`} → {titleByID.get(dep.successor_id) || `Task ${dep.successor_id}`} ({dep.type}
+                {dep.lag_days ? ` +${dep.lag_days}d` : ""})
+              </span>
+              {editable ? (
+                <button type="button" onClick={() => void onRemoveDependency(dep.id)} className="text-slate-400 hover:text-rose-600">
+                  Remove
+                </button>
+              ) : null}
+            </li>
+          ))}
+        </ul>
+      ) : null}
+
+      {editable && schedule.tasks.length > 1 ? (
+        <form onSubmit={onAddDependency} className="flex flex-wrap items-center gap-2 text-xs">
+          <select
+            value={predecessorId}
+            onChange={(event) => setPredecessorId(event.target.value)}
+            className="rounded-lg border border-slate-300 bg-white px-2 py-1"
+          >
+            <option value="">Predecessor</option>
+            {schedule.tasks.map((task) => (
+              <option key={task.task_id} value={task.task_id}>
+                {task.title}
+              </option>
+            ))}
+          </select>
+          <select
+            value={dependencyType}
+            onChange={(event) => setDependencyType(event.target.value as "FS" | "SS")}
+            className="rounded-lg border border-slate-300 bg-white px-2 py-1"
+          >
+            <option value="FS">finish → start</option>
+            <option value="SS">start → start</option>
+          </select>
+          <select
+            value={successorId}
+            onChange={(event) => setSuccessorId(event.target.value)}
+            className="rounded-lg border border-slate-300 bg-white px-2 py-1"
+          >
+            <option value="">Successor</option>
+            {schedule.tasks.map((task) => (
+              <option key={task.task_id} value={task.task_id}>
+                {task.title}
+              </option>
+            ))}
+          </select>
+          <button type="submit" className="rounded-lg border border-slate-300 px-3 py-1 text-slate-700 hover:bg-slate-50">
+            Add dependency
+          </button>
+        </form>
+      ) : null}
+    </div>
+  );
+}
@@ -10,6 +10,7 @@ type ApprovalsPageProps = {
 
 const defaultSettings: UserSettings = {
   timezone: "UTC",
+  locale: "en",
   week_starts_on: "monday",
   reminder_frequency: "daily",
   reminder_days: ["monday", "wednesday", "friday"],
@@ -38,7 +39,9 @@ export default function ApprovalsPage({ searchQuery = "" }: ApprovalsPageProps)
   const [selectedIDs, setSelectedIDs] = useState<number[]>([]);
 
   const [projectID, setProjectID] = useState("");
-  const [hours, setHours] = useState("");
+  const [fromDate, setFromDate] = useState("");
+  const [toDate, setToDate] = useState("");
+  const [expandedID, setExpandedID] = useState<number | null>(null);
   const [note, setNote] = useState("");
 
   const [openMenuApprovalId, setOpenMenuApprovalId] = useState<number | null>(null);
@@ -92,10 +95,12 @@ export default function ApprovalsPage({ searchQuery = "" }: ApprovalsPageProps)
       await createApprovalRequest({
         project_id: projectID ? Number(projectID) : null,
         note: note.trim(),
-        hours: hours.trim() ? Number(hours) : 0,
+        from: fromDate,
+        to: toDate,
       });
       setProjectID("");
-      setHours("");
+      setFromDate("");
+      setToDate("");
       setNote("");
       setStatus("Approval request submitted and notifications sent.");
       await loadData();
@@ -199,7 +204,7 @@ export default function ApprovalsPage({ searchQuery = "" }: ApprovalsPageProps)
 
       <form onSubmit={onCreate} className="rounded-xl border border-slate-200 bg-white p-4">
         <h3 className="mb-3 text-sm font-semibold text-slate-900">Create Approval Request</h3>
-        <div className="grid gap-3 md:grid-cols-3">
+        <div className="grid gap-3 md:grid-cols-4">
           <label htmlFor="approval-project" className="sr-only">
             Project
           </label>
@@ -217,13 +222,20 @@ export default function ApprovalsPage({ searchQuery = "" }: ApprovalsPageProps)
             ))}
           </select>
           <input
-            type="number"
-            min={0}
-            step={0.25}
-            value={hours}
-            onChange={(event) => setHours(event.target.value)}
-            placeholder="Billable hours (optional)"
+            type="date"
+            value={fromDate}
+            onChange={(event) => setFromDate(event.target.value)}
+            aria-label="Entries from"
             className="rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none"
+            required
+          />
+          <input
+            type="date"
+            value={toDate}
+            onChange={(event) => setToDate(event.target.value)}
+            aria-label="Entries to"
+            className="rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none"
+            required
           />
           <button
             type="submit"
@@ -305,7 +317,27 @@ export default function ApprovalsPage({ searchQuery = "" }: ApprovalsPageProps)
                     />
                   </td>
                   <td className="px-2 py-3 text-slate-900">{item.project_name || "-"}</td>
-                  <td className="px-2 py-3 text-slate-700">{item.billable_hours.toFixed(2)}</td>
+                  <td className="px-2 py-3 text-slate-700">
+                    {item.billable_hours.toFixed(2)}
+                    {item.entries && item.entries.length > 0 ? (
+                      <button
+                        type="button"
+                        onClick={() => setExpandedID((prev) => (prev === item.id ? null : item.id))}
+                        className="ml-2 text-xs text-sky-700 hover:underline"
+                      >
+                        {item.entries.length} entries
+                      </button>
+                    ) : null}
+                    {expandedID === item.id ? (
+                      <ul className="mt-2 space-y-1 text-xs text-slate-600">
+                        {(item.entries || []).map((entry) => (
+                          <li key={entry.id} className={entry.active ? "" : "line-through opacity-60"}>
+                            {entry.work_date} · {entry.user_email} · {entry.hours.toFixed(2)}h {entry.billable ? "" : "(non-billable)"}
+                          </li>
+                        ))}
+                      </ul>
+                    ) : null}
+                  </td>
                   <td className="px-2 py-3 text-slate-700">{item.approval_mode === "multi_approval" ? "Multi Approval" : "Simple"}</td>
                   <td className="px-2 py-3 text-slate-700">{(item.approver_emails || []).join(" -> ") || "-"}</td>
                   <td className="px-2 py-3 text-slate-700">
@@ -2,6 +2,7 @@
 
 import { FormEvent, useEffect, useMemo, useState } from "react";
 import { createSystemUpdate, getSession, getSystemLogs, getSystemUpdates, listProjects, listTasks, Project, SystemUpdate, TaskItem, updateSystemUpdate } from "../auth/auth";
+import { ProjectGantt } from "../componets/ProjectGantt";
 
 function startOfMonth(date: Date) {
   return new Date(date.getFullYear(), date.getMonth(), 1);
@@ -19,6 +20,7 @@ export default function CalendarPage() {
   const [current, setCurrent] = useState(() => startOfMonth(new Date()));
   const [projects, setProjects] = useState<Project[]>([]);
   const [tasks, setTasks] = useState<TaskItem[]>([]);
+  const [ganttProjectID, setGanttProjectID] = useState<number | "">("");
   const [updates, setUpdates] = useState<SystemUpdate[]>([]);
   const [supportItems, setSupportItems] = useState<
     Array<{ id: string; tenantSlug: string; userEmail: string; createdAt: string; statusCode: number }>
@@ -110,8 +112,8 @@ export default function CalendarPage() {
       });
       setStatus(
         editingUpdateID === null
-          ? `Update scheduled. Emails sent: ${result.sent}/${result.recipients} (failed: ${result.failed}).`
-          : `Update revised. Emails sent: ${result.sent}/${result.recipients} (failed: ${result.failed}).`,
+          ? `Update scheduled. Emails queued: ${result.queued}/${result.recipients} (failed: ${result.failed}).`
+          : `Update revised. Emails queued: ${result.queued}/${result.recipients} (failed: ${result.failed}).`,
       );
       setScheduledDate("");
       setTitle("");
@@ -361,6 +363,31 @@ export default function CalendarPage() {
           })}
         </div>
       </div>
+
+      {!isSystemAdmin ? (
+        <div className="rounded-xl border border-slate-200 bg-white p-4">
+          <div className="mb-3 flex flex-wrap items-center justify-between gap-3">
+            <h3 className="text-sm font-semibold text-slate-900">Project timeline</h3>
+            <select
+              value={String(ganttProjectID)}
+              onChange={(event) => setGanttProjectID(event.target.value ? Number(event.target.value) : "")}
+              className="rounded-lg border border-slate-300 bg-white px-3 py-2 text-sm text-slate-800"
+            >
+              <option value="">Select a project</option>
+              {projects.map((project) => (
+                <option key={project.id} value={project.id}>
+                  {project.name}
+                </option>
+              ))}
+            </select>
+          </div>
+          {ganttProjectID ? (
+            <ProjectGantt projectId={ganttProjectID} />
+          ) : (
+            <p className="text-sm text-slate-500">Choose a project to see its schedule and critical path.</p>
+          )}
+        </div>
+      ) : null}
     </section>
   );
 }
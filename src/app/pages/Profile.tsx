@@ -112,7 +112,7 @@ export default function ProfilePage({ user }: ProfilePageProps) {
             <div className="flex justify-between gap-3 rounded-lg bg-slate-50 px-3 py-2"><dt>User ID</dt><dd className="font-medium text-slate-900">{user.id}</dd></div>
             <div className="flex justify-between gap-3 rounded-lg bg-slate-50 px-3 py-2"><dt>Organization Slug</dt><dd className="font-medium text-slate-900">{user.tenantSlug}</dd></div>
             <div className="flex justify-between gap-3 rounded-lg bg-slate-50 px-3 py-2"><dt>Organization Name</dt><dd className="font-medium text-slate-900">{user.tenantName || profile.organization_name || "-"}</dd></div>
-            {user.role === "org_admin" ? (
+            {user.role !== "system_admin" ? (
               <>
                 <div className="flex justify-between gap-3 rounded-lg bg-slate-50 px-3 py-2"><dt>Active Sessions (24h)</dt><dd className="font-medium text-slate-900">{profile.active_sessions_24h || 0}</dd></div>
                 <div className="flex justify-between gap-3 rounded-lg bg-slate-50 px-3 py-2"><dt>Allowed Sessions</dt><dd className="font-medium text-slate-900">{profile.max_sessions || 5}</dd></div>
@@ -3,6 +3,7 @@
 import { FormEvent, useEffect, useMemo, useState } from "react";
 import { createProject, deleteProject, IssueItem, listIssues, listProjects, listTasks, listUsers, Project, TaskItem, TenantUser, updateProject } from "../auth/auth";
 import { LoadingSpinner } from "../componets/LoadingSpinner";
+import { ProjectGantt } from "../componets/ProjectGantt";
 
 function statusClass(status: string) {
   const normalized = status.trim().toLowerCase();
@@ -42,6 +43,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
   const [showCreate, setShowCreate] = useState(false);
   const [newName, setNewName] = useState("");
   const [newProjectCode, setNewProjectCode] = useState("");
+  const [newClientName, setNewClientName] = useState("");
   const [newStatus, setNewStatus] = useState("active");
   const [newStartDate, setNewStartDate] = useState(() => new Date().toISOString().slice(0, 10));
   const [newDurationDays, setNewDurationDays] = useState(30);
@@ -114,6 +116,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
     try {
       const created = await createProject({
         project_code: newProjectCode.trim(),
+        client_name: newClientName.trim(),
         name: newName.trim(),
         status: newStatus,
         assignees: selectedAssignees,
@@ -125,6 +128,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
       setProjects((prev) => [...prev, created]);
       setNewName("");
       setNewProjectCode("");
+      setNewClientName("");
       setNewStatus("active");
       setSelectedAssignees([]);
       setNewAssigneeInput("");
@@ -143,6 +147,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
     setEditingProject(null);
     setNewName("");
     setNewProjectCode("");
+    setNewClientName("");
     setNewStatus("active");
     setSelectedAssignees([]);
     setNewAssigneeInput("");
@@ -188,6 +193,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
       const updated = await updateProject({
         id: editingProject.id,
         project_code: newProjectCode.trim(),
+        client_name: newClientName.trim(),
         name: newName.trim(),
         status: newStatus,
         assignees: selectedAssignees,
@@ -211,6 +217,7 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
     setEditingProject(project);
     setNewName(project.name);
     setNewProjectCode(project.project_code || "");
+    setNewClientName(project.client_name || "");
     setNewStatus(project.status || "active");
     setSelectedAssignees(project.assignees || []);
     setNewAssigneeInput("");
@@ -494,6 +501,10 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
                     </tbody>
                   </table>
                 </div>
+                <div className="mt-4 rounded-lg border border-slate-200 bg-white p-3">
+                  <h4 className="mb-2 text-xs font-semibold uppercase tracking-wide text-slate-500">Schedule</h4>
+                  <ProjectGantt projectId={selectedReportProject.id} editable />
+                </div>
               </>
             ) : (
               <p className="text-sm text-slate-500">Choose a project to display its mini report.</p>
@@ -658,6 +669,19 @@ export default function ProjectsPage({ searchQuery = "" }: ProjectsPageProps) {
                   className="w-full rounded-lg border border-slate-300 bg-slate-50 px-3 py-2 text-sm outline-none"
                 />
               </div>
+              <div>
+                <label htmlFor="project-client" className="mb-1 block text-sm font-medium text-slate-700">
+                  Client
+                </label>
+                <input
+                  id="project-client"
+                  type="text"
+                  value={newClientName}
+                  onChange={(event) => setNewClientName(event.target.value)}
+                  placeholder="Acme Corp"
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
               <div>
                 <label htmlFor="project-status" className="mb-1 block text-sm font-medium text-slate-700">
                   Execution Status
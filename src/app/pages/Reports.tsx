@@ -1,7 +1,8 @@
 "use client";
 
 import { useEffect, useMemo, useState } from "react";
-import { IssueItem, listIssues, listProjects, listTasks, Project, TaskItem } from "../auth/auth";
+import { getCurrentUser, IssueItem, listIssues, listProjects, listTasks, Project, TaskItem } from "../auth/auth";
+import { BillingReport } from "../componets/BillingReport";
 
 type ReportsPageProps = {
   searchQuery?: string;
@@ -13,6 +14,8 @@ export default function ReportsPage({ searchQuery = "" }: ReportsPageProps) {
   const [issues, setIssues] = useState<IssueItem[]>([]);
   const [selectedProjectID, setSelectedProjectID] = useState<number | "all">("all");
   const [error, setError] = useState("");
+  const role = getCurrentUser()?.role;
+  const canViewBilling = role === "system_admin" || role === "owner" || role === "org_admin" || role === "manager";
 
   useEffect(() => {
     let mounted = true;
@@ -225,6 +228,8 @@ export default function ReportsPage({ searchQuery = "" }: ReportsPageProps) {
           </table>
         </div>
       </div>
+
+      {canViewBilling ? <BillingReport projects={projects} /> : null}
     </section>
   );
 }
@@ -1,10 +1,23 @@
 "use client";
 
 import { FormEvent, useEffect, useMemo, useState } from "react";
-import { getSession, getUserSettings, listSessions, sendSupportRequest, SessionItem, updateSessionAction, updateUserSettings, UserSettings } from "../auth/auth";
+import {
+  getNotificationPreferences,
+  getSession,
+  getUserSettings,
+  listSessions,
+  NotificationChannel,
+  sendSupportRequest,
+  SessionItem,
+  updateNotificationPreferences,
+  updateSessionAction,
+  updateUserSettings,
+  UserSettings,
+} from "../auth/auth";
 
 const defaultSettings: UserSettings = {
   timezone: "UTC",
+  locale: "en",
   week_starts_on: "monday",
   reminder_frequency: "daily",
   reminder_days: ["monday", "wednesday", "friday"],
@@ -27,6 +40,7 @@ const timezones = ["UTC", "Africa/Nairobi", "Europe/London", "America/New York",
 export default function SettingsPage() {
   const [settings, setSettings] = useState<UserSettings>(defaultSettings);
   const [settingsStatus, setSettingsStatus] = useState("");
+  const [channels, setChannels] = useState<Record<string, NotificationChannel>>({});
   const [savingSettings, setSavingSettings] = useState(false);
   const [showAdvanced, setShowAdvanced] = useState(false);
   const [sessions, setSessions] = useState<SessionItem[]>([]);
@@ -50,6 +64,12 @@ export default function SettingsPage() {
         if (!mounted) return;
         setSettings(defaultSettings);
       }
+      try {
+        const prefs = await getNotificationPreferences();
+        if (mounted) setChannels(prefs.channels);
+      } catch {
+        // channel preferences are optional; keep defaults
+      }
     })();
     return () => {
       mounted = false;
@@ -96,6 +116,7 @@ export default function SettingsPage() {
         reminder_days: showDayPicker ? settings.reminder_days : [],
       };
       const response = await updateUserSettings(payload);
+      await updateNotificationPreferences(channels);
       setSettingsStatus(response.status || "Settings saved.");
       const latest = await getUserSettings();
       setSettings({ ...defaultSettings, ...latest });
@@ -168,6 +189,17 @@ export default function SettingsPage() {
                   ))}
                 </select>
               </div>
+              <div>
+                <label className="mb-1 block text-slate-700">Email language</label>
+                <select
+                  value={settings.locale}
+                  onChange={(e) => setSettings((prev) => ({ ...prev, locale: e.target.value }))}
+                  className="w-full rounded-lg border border-slate-300 bg-white px-3 py-2 outline-none"
+                >
+                  <option value="en">English</option>
+                  <option value="es">Español</option>
+                </select>
+              </div>
               <div>
                 <label className="mb-1 block text-slate-700">Week starts on</label>
                 <select
@@ -252,6 +284,21 @@ export default function SettingsPage() {
                   />
                 </label>
               ))}
+              {Object.keys(channels).map((type) => (
+                <label key={type} className="flex items-center justify-between rounded-lg bg-slate-50 px-3 py-2">
+                  <span className="capitalize">{type} notifications</span>
+                  <select
+                    value={channels[type]}
+                    onChange={(e) => setChannels((prev) => ({ ...prev, [type]: e.target.value as NotificationChannel }))}
+                    className="rounded-lg border border-slate-300 bg-white px-2 py-1 text-sm outline-none"
+                  >
+                    <option value="all">In-app and email</option>
+                    <option value="in_app">In-app only</option>
+                    <option value="email">Email only</option>
+                    <option value="none">Off</option>
+                  </select>
+                </label>
+              ))}
               <div className="rounded-lg bg-slate-50 px-3 py-2">
                 <label className="mb-1 block">Log retention days</label>
                 <input type="number" min={1} value={settings.log_retention_days} onChange={(e) => setSettings((prev) => ({ ...prev, log_retention_days: Number(e.target.value) || 1 }))} className="w-full rounded-lg border border-slate-300 px-3 py-2 outline-none" />
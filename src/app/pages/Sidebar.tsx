@@ -1,7 +1,7 @@
 "use client";
 
 import { useEffect, useMemo, useState, type ReactNode } from "react";
-import { listProjects, Project } from "../auth/auth";
+import { listProjects, Project, type UserRole } from "../auth/auth";
 
 type PageKey = "dashboard" | "projects" | "tasks" | "timesheets" | "analytics" | "reports" | "calendar" | "approvals" | "forum" | "devshare" | "issues" | "profile" | "settings" | "admin";
 
@@ -152,7 +152,7 @@ const menu: Array<{ key: PageKey; label: string; icon: ReactNode }> = [
 type SidebarProps = {
   currentPage: PageKey;
   onNavigate: (page: PageKey) => void;
-  role: "system_admin" | "org_admin";
+  role: UserRole;
   isSystemAdmin: boolean;
   collapsed: boolean;
   onToggleCollapse: () => void;
@@ -173,7 +173,7 @@ export function Sidebar({ currentPage, onNavigate, role, isSystemAdmin, collapse
 
   useEffect(() => {
     let mounted = true;
-    if (role !== "org_admin") return () => { mounted = false; };
+    if (role === "system_admin") return () => { mounted = false; };
     (async () => {
       try {
         const projects = await listProjects();
@@ -279,7 +279,7 @@ export function Sidebar({ currentPage, onNavigate, role, isSystemAdmin, collapse
         </ul>
       </nav>
 
-      {!collapsed && role === "org_admin" ? (
+      {!collapsed && role !== "system_admin" ? (
         <section className="shrink-0 border-t border-slate-200 px-3 py-3">
           {/* <div className="mb-2 flex items-center justify-between">
             <button
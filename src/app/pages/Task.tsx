@@ -1,7 +1,22 @@
 "use client";
 
 import { FormEvent, useEffect, useMemo, useState } from "react";
-import { createTask, deleteTask, listProjects, listTasks, Project, TaskItem, updateTask } from "../auth/auth";
+import {
+  createSubtask,
+  createTask,
+  deleteSubtask,
+  deleteTask,
+  listProjects,
+  listTasks,
+  listWorkflows,
+  Project,
+  TaskItem,
+  TaskSubtask,
+  updateSubtask,
+  updateTask,
+  Workflow,
+  WorkflowState,
+} from "../auth/auth";
 import { LoadingSpinner } from "../componets/LoadingSpinner";
 
 function chipClass(status: string) {
@@ -40,9 +55,17 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
   const [taskCode, setTaskCode] = useState("");
   const [projectId, setProjectId] = useState("");
   const [phase, setPhase] = useState("");
-  const [status, setStatus] = useState("todo");
+  const [status, setStatus] = useState("");
   const [priority, setPriority] = useState("medium");
   const [subtasksText, setSubtasksText] = useState("");
+  const [newSubtaskTitle, setNewSubtaskTitle] = useState("");
+  const [workflows, setWorkflows] = useState<Workflow[]>([]);
+  const [description, setDescription] = useState("");
+  const [assigneesText, setAssigneesText] = useState("");
+  const [startDate, setStartDate] = useState("");
+  const [dueDate, setDueDate] = useState("");
+  const [storyPoints, setStoryPoints] = useState("");
+  const [estimatedHours, setEstimatedHours] = useState("");
   const [editingTask, setEditingTask] = useState<TaskItem | null>(null);
   const [submitting, setSubmitting] = useState(false);
 
@@ -57,9 +80,14 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     let mounted = true;
     (async () => {
       try {
-        const [items, projectsData] = await Promise.all([listTasks(), listProjects()]);
+        const [items, projectsData, workflowData] = await Promise.all([
+          listTasks(),
+          listProjects(),
+          listWorkflows().catch(() => ({ items: [] as Workflow[], fields: {} })),
+        ]);
         if (mounted) setTasks(items);
         if (mounted) setProjects(projectsData);
+        if (mounted) setWorkflows(workflowData.items);
       } catch (err) {
         if (mounted) setError(err instanceof Error ? err.message : "Failed to load tasks.");
       } finally {
@@ -93,6 +121,47 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     return () => document.removeEventListener("mousedown", handleClick);
   }, [isBulkMenuOpen]);
 
+  const statusOptions = useMemo<WorkflowState[]>(() => {
+    const taskWorkflows = workflows.filter((item) => item.entity === "task");
+    const workflow =
+      taskWorkflows.find((item) => projectId && item.project_id === Number(projectId)) ||
+      taskWorkflows.find((item) => !item.project_id);
+    const states = workflow?.states.length
+      ? workflow.states
+      : [
+          { key: "todo", label: "To Do", terminal: false },
+          { key: "In progress", label: "In Progress", terminal: false },
+          { key: "done", label: "Done", terminal: true },
+        ];
+    if (status && !states.some((item) => item.key.toLowerCase() === status.trim().toLowerCase())) {
+      return [{ key: status, label: status, terminal: false }, ...states];
+    }
+    return states;
+  }, [workflows, projectId, status]);
+
+  function planFields() {
+    return {
+      description: description.trim(),
+      assignees: assigneesText
+        .split(",")
+        .map((value) => value.trim())
+        .filter(Boolean),
+      start_date: startDate,
+      due_date: dueDate,
+      story_points: storyPoints.trim() ? Number(storyPoints) : null,
+      estimated_hours: estimatedHours.trim() ? Number(estimatedHours) : null,
+    };
+  }
+
+  function resetPlanFields(task?: TaskItem) {
+    setDescription(task?.description || "");
+    setAssigneesText((task?.assignees || []).join(", "));
+    setStartDate(task?.start_date?.slice(0, 10) || "");
+    setDueDate(task?.due_date?.slice(0, 10) || "");
+    setStoryPoints(task?.story_points != null ? String(task.story_points) : "");
+    setEstimatedHours(task?.estimated_hours != null ? String(task.estimated_hours) : "");
+  }
+
   async function onCreate(event: FormEvent<HTMLFormElement>) {
     event.preventDefault();
     if (!title.trim() || !projectId || submitting) return;
@@ -112,15 +181,17 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
         phase: phase.trim(),
         subtasks,
         attachments: taskAttachments.map((file) => file.name),
+        ...planFields(),
       });
       setTasks((prev) => [...prev, item]);
       setTitle("");
       setTaskCode("");
       setProjectId("");
       setPhase("");
-      setStatus("todo");
+      setStatus("");
       setPriority("medium");
       setSubtasksText("");
+      resetPlanFields();
       setTaskAttachments([]);
       setShowCreate(false);
     } catch (err) {
@@ -135,10 +206,6 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     if (!editingTask || !title.trim() || !projectId || submitting) return;
     setError("");
     setSubmitting(true);
-    const subtasks = subtasksText
-      .split("\n")
-      .map((line) => line.trim())
-      .filter(Boolean);
     try {
       const updated = await updateTask({
         id: editingTask.id,
@@ -148,8 +215,8 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
         priority,
         project_id: Number(projectId),
         phase: phase.trim(),
-        subtasks,
         attachments: taskAttachments.map((file) => file.name),
+        ...planFields(),
       });
       setTasks((prev) => prev.map((item) => (item.id === updated.id ? updated : item)));
       closeForm();
@@ -160,6 +227,54 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     }
   }
 
+  function applySubtasks(taskId: number, update: (items: TaskSubtask[]) => TaskSubtask[]) {
+    const patch = (task: TaskItem) => {
+      if (task.id !== taskId) return task;
+      const subtasks = update(task.subtasks || []);
+      const done = subtasks.filter((item) => ["done", "completed", "closed"].includes(item.status.trim().toLowerCase())).length;
+      return { ...task, subtasks, subtask_progress: { done, total: subtasks.length } };
+    };
+    setTasks((prev) => prev.map(patch));
+    setEditingTask((prev) => (prev ? patch(prev) : prev));
+  }
+
+  async function onAddSubtask() {
+    if (!editingTask || !newSubtaskTitle.trim()) return;
+    setError("");
+    try {
+      const item = await createSubtask(editingTask.id, { title: newSubtaskTitle.trim() });
+      applySubtasks(editingTask.id, (items) => [...items, item]);
+      setNewSubtaskTitle("");
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to add subtask.");
+    }
+  }
+
+  async function onToggleSubtask(subtask: TaskSubtask) {
+    const done = ["done", "completed", "closed"].includes(subtask.status.trim().toLowerCase());
+    setError("");
+    try {
+      const item = await updateSubtask(subtask.task_id, subtask.id, {
+        title: subtask.title,
+        status: done ? "todo" : "done",
+        assignee: subtask.assignee || "",
+      });
+      applySubtasks(subtask.task_id, (items) => items.map((entry) => (entry.id === item.id ? item : entry)));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to update subtask.");
+    }
+  }
+
+  async function onRemoveSubtask(subtask: TaskSubtask) {
+    setError("");
+    try {
+      await deleteSubtask(subtask.task_id, subtask.id);
+      applySubtasks(subtask.task_id, (items) => items.filter((entry) => entry.id !== subtask.id));
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to delete subtask.");
+    }
+  }
+
   async function onDelete(task: TaskItem) {
     if (deletingTaskId !== null) return;
     setOpenMenuTaskId(null);
@@ -183,9 +298,10 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     setTaskCode("");
     setProjectId("");
     setPhase("");
-    setStatus("todo");
+    setStatus("");
     setPriority("medium");
     setSubtasksText("");
+    resetPlanFields();
     setTaskAttachments([]);
     setShowCreate(true);
   }
@@ -199,7 +315,9 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     setPhase(task.phase || "");
     setStatus(task.status || "todo");
     setPriority(task.priority || "medium");
-    setSubtasksText((task.subtasks || []).join("\n"));
+    setSubtasksText("");
+    setNewSubtaskTitle("");
+    resetPlanFields(task);
     setTaskAttachments([]);
     setShowCreate(true);
   }
@@ -230,7 +348,7 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
     const q = searchQuery.trim().toLowerCase();
     if (!q) return tasks;
     return tasks.filter((task) =>
-      `${task.title} ${task.priority} ${task.status} ${task.project_name || ""} ${task.phase || ""} ${(task.subtasks || []).join(" ")}`
+      `${task.title} ${task.priority} ${task.status} ${task.project_name || ""} ${task.phase || ""} ${(task.subtasks || []).map((item) => item.title).join(" ")}`
         .toLowerCase()
         .includes(q),
     );
@@ -404,7 +522,10 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
                     <td className="px-2 py-3 align-top">
                       <p className="font-medium text-slate-900">{task.title}</p>
                       <p className="mt-1 text-xs text-slate-500">
-                        {task.task_code || `Task ${task.id}`} • {task.subtasks?.length || 0} subtasks
+                        {task.task_code || `Task ${task.id}`} • {task.subtask_progress?.done || 0}/
+                        {task.subtask_progress?.total || 0} subtasks done
+                        {task.due_date ? ` • due ${task.due_date.slice(0, 10)}` : ""}
+                        {task.assignees?.length ? ` • ${task.assignees.join(", ")}` : ""}
                       </p>
                     </td>
                     <td className="px-2 py-3 align-top text-sm text-slate-700">
@@ -529,9 +650,11 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
                   onChange={(event) => setStatus(event.target.value)}
                   className="w-full rounded-lg border border-slate-300 bg-white px-3 py-2 text-sm font-medium text-slate-800 outline-none focus:border-sky-300"
                 >
-                  <option value="todo">To Do</option>
-                  <option value="In progress">In Progress</option>
-                  <option value="done">Done</option>
+                  {statusOptions.map((item) => (
+                    <option key={item.key} value={item.key}>
+                      {item.label || item.key}
+                    </option>
+                  ))}
                 </select>
               </div>
               <div className="md:col-span-2">
@@ -590,18 +713,138 @@ export default function TasksPage({ searchQuery = "" }: TasksPageProps) {
                 </select>
               </div>
                 <div className="md:col-span-2">
-                  <label htmlFor="task-subtasks" className="mb-1 block text-sm font-medium text-slate-700">
-                    Subtasks (one per line)
+                  <label htmlFor="task-description" className="mb-1 block text-sm font-medium text-slate-700">
+                    Description
                   </label>
                   <textarea
-                    id="task-subtasks"
-                    rows={6}
-                    value={subtasksText}
-                    onChange={(event) => setSubtasksText(event.target.value)}
-                    placeholder={"Create API endpoint\nAdd tests\nReview QA checklist"}
+                    id="task-description"
+                    rows={3}
+                    value={description}
+                    onChange={(event) => setDescription(event.target.value)}
+                    className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  />
+                </div>
+                <div className="md:col-span-2">
+                  <label htmlFor="task-assignees" className="mb-1 block text-sm font-medium text-slate-700">
+                    Assignees (comma separated emails)
+                  </label>
+                  <input
+                    id="task-assignees"
+                    type="text"
+                    value={assigneesText}
+                    onChange={(event) => setAssigneesText(event.target.value)}
+                    placeholder="dev@example.com, qa@example.com"
+                    className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  />
+                </div>
+                <div>
+                  <label htmlFor="task-start-date" className="mb-1 block text-sm font-medium text-slate-700">
+                    Start date
+                  </label>
+                  <input
+                    id="task-start-date"
+                    type="date"
+                    value={startDate}
+                    onChange={(event) => setStartDate(event.target.value)}
+                    className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  />
+                </div>
+                <div>
+                  <label htmlFor="task-due-date" className="mb-1 block text-sm font-medium text-slate-700">
+                    Due date
+                  </label>
+                  <input
+                    id="task-due-date"
+                    type="date"
+                    value={dueDate}
+                    onChange={(event) => setDueDate(event.target.value)}
+                    className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  />
+                </div>
+                <div>
+                  <label htmlFor="task-story-points" className="mb-1 block text-sm font-medium text-slate-700">
+                    Story points
+                  </label>
+                  <input
+                    id="task-story-points"
+                    type="number"
+                    min={0}
+                    value={storyPoints}
+                    onChange={(event) => setStoryPoints(event.target.value)}
                     className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
                   />
                 </div>
+                <div>
+                  <label htmlFor="task-estimated-hours" className="mb-1 block text-sm font-medium text-slate-700">
+                    Estimated hours
+                  </label>
+                  <input
+                    id="task-estimated-hours"
+                    type="number"
+                    min={0}
+                    step="0.25"
+                    value={estimatedHours}
+                    onChange={(event) => setEstimatedHours(event.target.value)}
+                    className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  />
+                </div>
+                {editingTask ? (
+                  <div className="md:col-span-2">
+                    <p className="mb-1 block text-sm font-medium text-slate-700">
+                      Subtasks ({editingTask.subtask_progress?.done || 0}/{editingTask.subtask_progress?.total || 0} done)
+                    </p>
+                    <ul className="space-y-1">
+                      {(editingTask.subtasks || []).map((subtask) => (
+                        <li key={subtask.id} className="flex items-center gap-2 text-sm text-slate-700">
+                          <input
+                            type="checkbox"
+                            checked={["done", "completed", "closed"].includes(subtask.status.trim().toLowerCase())}
+                            onChange={() => void onToggleSubtask(subtask)}
+                            className="rounded border-slate-300 text-slate-900 focus:ring-slate-500"
+                          />
+                          <span className="flex-1">{subtask.title}</span>
+                          <button
+                            type="button"
+                            onClick={() => void onRemoveSubtask(subtask)}
+                            className="text-xs text-slate-400 hover:text-rose-600"
+                          >
+                            Remove
+                          </button>
+                        </li>
+                      ))}
+                    </ul>
+                    <div className="mt-2 flex gap-2">
+                      <input
+                        type="text"
+                        value={newSubtaskTitle}
+                        onChange={(event) => setNewSubtaskTitle(event.target.value)}
+                        placeholder="New subtask"
+                        className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                      />
+                      <button
+                        type="button"
+                        onClick={() => void onAddSubtask()}
+                        className="rounded-lg border border-slate-300 px-3 py-2 text-sm text-slate-700 hover:bg-slate-50"
+                      >
+                        Add
+                      </button>
+                    </div>
+                  </div>
+                ) : (
+                  <div className="md:col-span-2">
+                    <label htmlFor="task-subtasks" className="mb-1 block text-sm font-medium text-slate-700">
+                      Subtasks (one per line)
+                    </label>
+                    <textarea
+                      id="task-subtasks"
+                      rows={6}
+                      value={subtasksText}
+                      onChange={(event) => setSubtasksText(event.target.value)}
+                      placeholder={"Create API endpoint\nAdd tests\nReview QA checklist"}
+                      className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                    />
+                  </div>
+                )}
                 <div className="md:col-span-2">
                   <label htmlFor="task-attachments" className="mb-1 block text-sm font-medium text-slate-700">
                     Attach documents
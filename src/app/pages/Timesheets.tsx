@@ -1,7 +1,33 @@
 "use client";
 
 import { FormEvent, useEffect, useMemo, useState } from "react";
-import { createTimesheet, listProjects, listTasks, listTimesheets, Project, TaskItem, TimesheetEntry, TimesheetSummary, deleteTimesheet } from "../auth/auth";
+import {
+  createTimesheet,
+  getCurrentTimer,
+  getCurrentUser,
+  getTimesheetPolicy,
+  heartbeatTimer,
+  listProjects,
+  listTasks,
+  listTimesheets,
+  listTimesheetSubmissions,
+  PolicyViolation,
+  Project,
+  reopenTimesheetSubmission,
+  RequestError,
+  startTimer,
+  stopTimer,
+  submitTimesheetWeek,
+  TaskItem,
+  Timer,
+  TimesheetEntry,
+  TimesheetPolicy,
+  TimesheetSubmission,
+  TimesheetSummary,
+  deleteTimesheet,
+  updateTimesheet,
+  updateTimesheetPolicy,
+} from "../auth/auth";
 import { LoadingSpinner } from "../componets/LoadingSpinner";
 
 type TimesheetsPageProps = {
@@ -17,22 +43,48 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
   const [error, setError] = useState("");
 
   const [showCreate, setShowCreate] = useState(false);
+  const [editingId, setEditingId] = useState<number | null>(null);
   const [projectId, setProjectId] = useState("");
   const [taskId, setTaskId] = useState("");
   const [workDate, setWorkDate] = useState(() => new Date().toISOString().slice(0, 10));
   const [hours, setHours] = useState("8");
   const [billable, setBillable] = useState(true);
   const [notes, setNotes] = useState("");
+  const [startTime, setStartTime] = useState("");
+  const [endTime, setEndTime] = useState("");
+  const [violations, setViolations] = useState<PolicyViolation[]>([]);
+
+  const [policy, setPolicy] = useState<TimesheetPolicy | null>(null);
+  const [showPolicy, setShowPolicy] = useState(false);
+  const [policyStatus, setPolicyStatus] = useState("");
 
   const [openMenuTimesheetId, setOpenMenuTimesheetId] = useState<number | null>(null);
   const [deletingTimesheetId, setDeletingTimesheetId] = useState<number | null>(null);
 
+  const [submissions, setSubmissions] = useState<TimesheetSubmission[]>([]);
+  const [weekOf, setWeekOf] = useState(() => new Date().toISOString().slice(0, 10));
+  const [submitting, setSubmitting] = useState(false);
+  const currentEmail = (getCurrentUser()?.email || "").toLowerCase();
+
+  const [timer, setTimer] = useState<Timer | null>(null);
+  const [timerProjectId, setTimerProjectId] = useState("");
+  const [timerNotes, setTimerNotes] = useState("");
+  const [timerBusy, setTimerBusy] = useState(false);
+  const [now, setNow] = useState(() => Date.now());
+
   async function loadData() {
     setLoading(true);
     setError("");
     try {
-      const [timesheetsPayload, projectsPayload, tasksPayload] = await Promise.all([listTimesheets(), listProjects(), listTasks()]);
+      const [timesheetsPayload, projectsPayload, tasksPayload, submissionsPayload] = await Promise.all([
+        listTimesheets(),
+        listProjects(),
+        listTasks(),
+        listTimesheetSubmissions(),
+      ]);
+      setTimer(await getCurrentTimer());
       setItems(timesheetsPayload.items);
+      setSubmissions(submissionsPayload);
       setSummary(timesheetsPayload.summary);
       setProjects(projectsPayload);
       setTasks(tasksPayload);
@@ -47,6 +99,69 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
     void loadData();
   }, []);
 
+  useEffect(() => {
+    if (!timer) return;
+    const tick = window.setInterval(() => setNow(Date.now()), 1000);
+    // Keep the timer alive while this page is open so the idle auto-stop
+    // only catches timers that were really forgotten.
+    const heartbeat = window.setInterval(() => {
+      void heartbeatTimer().catch(() => undefined);
+    }, 5 * 60 * 1000);
+    return () => {
+      window.clearInterval(tick);
+      window.clearInterval(heartbeat);
+    };
+  }, [timer]);
+
+  const timerElapsed = useMemo(() => {
+    if (!timer) return "";
+    const seconds = Math.max(0, Math.floor((now - new Date(timer.started_at).getTime()) / 1000));
+    const h = Math.floor(seconds / 3600);
+    const m = Math.floor((seconds % 3600) / 60);
+    const s = seconds % 60;
+    return `${h}:${String(m).padStart(2, "0")}:${String(s).padStart(2, "0")}`;
+  }, [timer, now]);
+
+  async function onStartTimer() {
+    if (timerBusy) return;
+    setError("");
+    setTimerBusy(true);
+    try {
+      setTimer(await startTimer({ project_id: timerProjectId ? Number(timerProjectId) : null, notes: timerNotes.trim() }));
+      setNow(Date.now());
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to start timer.");
+    } finally {
+      setTimerBusy(false);
+    }
+  }
+
+  async function onStopTimer() {
+    if (timerBusy) return;
+    setError("");
+    setTimerBusy(true);
+    try {
+      const result = await stopTimer(timerNotes.trim() || undefined);
+      setTimer(null);
+      setTimerNotes("");
+      const notices: string[] = [];
+      if (result.skipped_days.length > 0) {
+        notices.push(`Not logged for already submitted days: ${result.skipped_days.join(", ")}`);
+      }
+      if (result.clipped.length > 0) {
+        notices.push(`Cut to fit the hour limits: ${result.clipped.map((violation) => violation.message).join("; ")}`);
+      }
+      if (notices.length > 0) {
+        setError(notices.join(". "));
+      }
+      await loadData();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to stop timer.");
+    } finally {
+      setTimerBusy(false);
+    }
+  }
+
   const filteredTasks = useMemo(() => {
     if (!projectId) return tasks;
     return tasks.filter((task) => String(task.project_id) === projectId);
@@ -83,33 +198,108 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
     }
   }
 
-  async function onCreate(event: FormEvent<HTMLFormElement>) {
+  function resetForm(entry?: TimesheetEntry) {
+    setEditingId(entry?.id ?? null);
+    setProjectId(entry?.project_id ? String(entry.project_id) : "");
+    setTaskId(entry?.task_id ? String(entry.task_id) : "");
+    setWorkDate(entry?.work_date || new Date().toISOString().slice(0, 10));
+    setHours(entry ? String(entry.hours) : "8");
+    setBillable(entry ? entry.billable : true);
+    setNotes(entry?.notes || "");
+    setStartTime(entry?.start_time || "");
+    setEndTime(entry?.end_time || "");
+    setViolations([]);
+  }
+
+  function onEdit(entry: TimesheetEntry) {
+    setOpenMenuTimesheetId(null);
+    resetForm(entry);
+    setShowCreate(true);
+  }
+
+  async function onSave(event: FormEvent<HTMLFormElement>) {
     event.preventDefault();
     setError("");
-    const parsedHours = Number(hours);
-    if (!Number.isFinite(parsedHours) || parsedHours <= 0 || parsedHours > 24) {
+    setViolations([]);
+    const clocked = Boolean(startTime && endTime);
+    const parsedHours = clocked ? 0 : Number(hours);
+    if (!clocked && (!Number.isFinite(parsedHours) || parsedHours <= 0 || parsedHours > 24)) {
       setError("Hours must be between 0 and 24.");
       return;
     }
+    const input = {
+      project_id: projectId ? Number(projectId) : null,
+      task_id: taskId ? Number(taskId) : null,
+      work_date: workDate,
+      hours: parsedHours,
+      billable,
+      notes: notes.trim(),
+      start_time: startTime,
+      end_time: endTime,
+    };
     try {
-      await createTimesheet({
-        project_id: projectId ? Number(projectId) : null,
-        task_id: taskId ? Number(taskId) : null,
-        work_date: workDate,
-        hours: parsedHours,
-        billable,
-        notes: notes.trim(),
-      });
+      if (editingId !== null) {
+        await updateTimesheet(editingId, input);
+      } else {
+        await createTimesheet(input);
+      }
       setShowCreate(false);
-      setProjectId("");
-      setTaskId("");
-      setWorkDate(new Date().toISOString().slice(0, 10));
-      setHours("8");
-      setBillable(true);
-      setNotes("");
+      resetForm();
       await loadData();
     } catch (err) {
       setError(err instanceof Error ? err.message : "Failed to log time.");
+      setViolations(err instanceof RequestError ? err.violations : []);
+    }
+  }
+
+  async function onOpenPolicy() {
+    setPolicyStatus("");
+    try {
+      setPolicy(await getTimesheetPolicy());
+      setShowPolicy(true);
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to load timesheet policy.");
+    }
+  }
+
+  async function onSavePolicy(event: FormEvent<HTMLFormElement>) {
+    event.preventDefault();
+    if (!policy) return;
+    setPolicyStatus("");
+    try {
+      setPolicy(await updateTimesheetPolicy(policy));
+      setShowPolicy(false);
+    } catch (err) {
+      setPolicyStatus(err instanceof Error ? err.message : "Failed to save timesheet policy.");
+    }
+  }
+
+  function policyLimit(value: string): number | null {
+    return value.trim() === "" ? null : Number(value);
+  }
+
+  async function onSubmitWeek() {
+    if (submitting) return;
+    setError("");
+    setSubmitting(true);
+    try {
+      await submitTimesheetWeek({ week_of: weekOf });
+      await loadData();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to submit week.");
+    } finally {
+      setSubmitting(false);
+    }
+  }
+
+  async function onReopen(submission: TimesheetSubmission) {
+    if (!window.confirm(`Reopen the week of ${submission.week_start} for ${submission.user_email}?`)) return;
+    setError("");
+    try {
+      await reopenTimesheetSubmission(submission.id);
+      await loadData();
+    } catch (err) {
+      setError(err instanceof Error ? err.message : "Failed to reopen submission.");
     }
   }
 
@@ -122,9 +312,34 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
             <p className="text-sm text-slate-600">Track billable and non-billable hours across projects and tasks.</p>
           </div>
           <div className="flex items-center gap-2">
+            <input
+              type="date"
+              value={weekOf}
+              onChange={(event) => setWeekOf(event.target.value)}
+              className="rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+              aria-label="Week to submit"
+            />
             <button
               type="button"
-              onClick={() => setShowCreate(true)}
+              onClick={() => void onSubmitWeek()}
+              disabled={submitting}
+              className="rounded-lg border border-slate-300 px-4 py-2 text-sm font-medium text-slate-700 transition hover:bg-slate-50 disabled:cursor-not-allowed disabled:opacity-60"
+            >
+              {submitting ? "Submitting..." : "Submit Week"}
+            </button>
+            <button
+              type="button"
+              onClick={() => void onOpenPolicy()}
+              className="rounded-lg border border-slate-300 px-4 py-2 text-sm font-medium text-slate-700 transition hover:bg-slate-50"
+            >
+              Policy
+            </button>
+            <button
+              type="button"
+              onClick={() => {
+                resetForm();
+                setShowCreate(true);
+              }}
               className="rounded-lg bg-slate-900 px-4 py-2 text-sm font-medium text-white transition hover:-translate-y-px"
             >
               Log Hours
@@ -148,6 +363,46 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
         </article>
       </div>
 
+      <div className="flex flex-wrap items-center gap-2 rounded-xl border border-slate-200 bg-white px-4 py-3">
+        {timer ? (
+          <>
+            <span className="h-2 w-2 animate-pulse rounded-full bg-rose-500" />
+            <span className="font-mono text-lg font-semibold text-slate-900">{timerElapsed}</span>
+            <span className="text-sm text-slate-600">
+              {projects.find((project) => project.id === timer.project_id)?.name || "No project"}
+              {timer.notes ? ` · ${timer.notes}` : ""}
+            </span>
+          </>
+        ) : (
+          <select
+            value={timerProjectId}
+            onChange={(event) => setTimerProjectId(event.target.value)}
+            className="rounded-lg border border-slate-300 bg-white px-3 py-2 text-sm outline-none focus:border-sky-300"
+          >
+            <option value="">No project</option>
+            {projects.map((project) => (
+              <option key={project.id} value={project.id}>
+                {project.name}
+              </option>
+            ))}
+          </select>
+        )}
+        <input
+          value={timerNotes}
+          onChange={(event) => setTimerNotes(event.target.value)}
+          placeholder={timer ? "Notes to save on stop" : "What are you working on?"}
+          className="min-w-[200px] flex-1 rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+        />
+        <button
+          type="button"
+          onClick={() => void (timer ? onStopTimer() : onStartTimer())}
+          disabled={timerBusy}
+          className={`rounded-lg px-4 py-2 text-sm font-medium text-white disabled:cursor-not-allowed disabled:opacity-60 ${timer ? "bg-rose-600" : "bg-emerald-600"}`}
+        >
+          {timer ? "Stop Timer" : "Start Timer"}
+        </button>
+      </div>
+
       {error ? <p className="text-sm text-rose-600">{error}</p> : null}
       {loading ? <LoadingSpinner label="Loading timesheets..." /> : null}
 
@@ -163,6 +418,7 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                 <th className="px-2 py-2 font-medium">Type</th>
                 <th className="px-2 py-2 font-medium">Notes</th>
                 <th className="px-2 py-2 font-medium">By</th>
+                <th className="px-2 py-2 font-medium">Status</th>
                 <th className="px-2 py-2 font-medium">Action</th>
               </tr>
             </thead>
@@ -172,7 +428,10 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                   <td className="px-2 py-3 text-slate-700">{new Date(entry.work_date).toLocaleDateString()}</td>
                   <td className="px-2 py-3 text-slate-700">{entry.project_name || "-"}</td>
                   <td className="px-2 py-3 text-slate-700">{entry.task_title || "-"}</td>
-                  <td className="px-2 py-3 font-medium text-slate-900">{entry.hours.toFixed(2)}h</td>
+                  <td className="px-2 py-3 font-medium text-slate-900">
+                    {entry.hours.toFixed(2)}h
+                    {entry.start_time ? <span className="block text-xs font-normal text-slate-500">{entry.start_time}-{entry.end_time}</span> : null}
+                  </td>
                   <td className="px-2 py-3">
                     <span className={`rounded-full px-2 py-1 text-xs font-medium ${entry.billable ? "bg-emerald-50 text-emerald-700" : "bg-amber-50 text-amber-700"}`}>
                       {entry.billable ? "Billable" : "Non-Billable"}
@@ -180,6 +439,15 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                   </td>
                   <td className="px-2 py-3 text-slate-700 whitespace-normal break-words">{entry.notes || "-"}</td>
                   <td className="px-2 py-3 text-slate-700 whitespace-normal break-words">{entry.created_by_email}</td>
+                  <td className="px-2 py-3">
+                    <span
+                      className={`rounded-full px-2 py-1 text-xs font-medium ${
+                        entry.status === "locked" ? "bg-slate-100 text-slate-700" : entry.status === "submitted" ? "bg-sky-50 text-sky-700" : "bg-white text-slate-500"
+                      }`}
+                    >
+                      {entry.status}
+                    </span>
+                  </td>
                   <td className="px-2 py-3">
                     <div className="relative flex justify-end">
                       <button
@@ -196,14 +464,28 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                       </button>
                       {openMenuTimesheetId === entry.id ? (
                         <div className="absolute right-0 top-11 z-10 w-32 rounded-xl border border-slate-200 bg-white p-1 shadow-lg">
-                          <button
-                            type="button"
-                            onClick={() => void onDelete(entry)}
-                            disabled={deletingTimesheetId === entry.id}
-                            className="block w-full rounded-lg px-3 py-2 text-left text-sm text-rose-600 transition hover:bg-rose-50 disabled:cursor-not-allowed disabled:opacity-60"
-                          >
-                            {deletingTimesheetId === entry.id ? "Deleting..." : "Delete"}
-                          </button>
+                          {entry.status !== "draft" ? (
+                            <p className="px-3 py-2 text-xs text-slate-500">{entry.status === "locked" ? "Locked after approval" : "Awaiting approval"}</p>
+                          ) : null}
+                          {entry.status === "draft" && entry.created_by_email.toLowerCase() === currentEmail ? (
+                            <button
+                              type="button"
+                              onClick={() => onEdit(entry)}
+                              className="block w-full rounded-lg px-3 py-2 text-left text-sm text-slate-700 transition hover:bg-slate-50"
+                            >
+                              Edit
+                            </button>
+                          ) : null}
+                          {entry.status === "draft" ? (
+                            <button
+                              type="button"
+                              onClick={() => void onDelete(entry)}
+                              disabled={deletingTimesheetId === entry.id}
+                              className="block w-full rounded-lg px-3 py-2 text-left text-sm text-rose-600 transition hover:bg-rose-50 disabled:cursor-not-allowed disabled:opacity-60"
+                            >
+                              {deletingTimesheetId === entry.id ? "Deleting..." : "Delete"}
+                            </button>
+                          ) : null}
                         </div>
                       ) : null}
                     </div>
@@ -215,11 +497,55 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
         </div>
       </div>
 
+      {submissions.length > 0 ? (
+        <div className="rounded-xl border border-slate-200 bg-white p-4">
+          <h3 className="mb-2 text-sm font-semibold text-slate-900">Weekly Submissions</h3>
+          <table className="w-full text-left text-sm">
+            <thead className="text-slate-500">
+              <tr className="border-b border-slate-200">
+                <th className="px-2 py-2 font-medium">Week</th>
+                <th className="px-2 py-2 font-medium">User</th>
+                <th className="px-2 py-2 font-medium">Entries</th>
+                <th className="px-2 py-2 font-medium">Hours</th>
+                <th className="px-2 py-2 font-medium">Status</th>
+                <th className="px-2 py-2 font-medium">Action</th>
+              </tr>
+            </thead>
+            <tbody>
+              {submissions.map((submission) => (
+                <tr key={submission.id} className="border-b border-slate-100">
+                  <td className="px-2 py-3 text-slate-700">
+                    {submission.week_start} – {submission.week_end}
+                  </td>
+                  <td className="px-2 py-3 text-slate-700">{submission.user_email}</td>
+                  <td className="px-2 py-3 text-slate-700">{submission.entry_count}</td>
+                  <td className="px-2 py-3 text-slate-700">
+                    {submission.total_hours.toFixed(2)}h ({submission.billable_hours.toFixed(2)}h billable)
+                  </td>
+                  <td className="px-2 py-3 capitalize text-slate-700">{submission.status}</td>
+                  <td className="px-2 py-3">
+                    {submission.status === "approved" ? (
+                      <button
+                        type="button"
+                        onClick={() => void onReopen(submission)}
+                        className="rounded-lg border border-slate-300 px-3 py-1 text-xs text-slate-700 hover:bg-slate-50"
+                      >
+                        Reopen
+                      </button>
+                    ) : null}
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      ) : null}
+
       {showCreate ? (
         <div className="fixed inset-0 z-40 flex items-center justify-center bg-slate-950/55 p-4 backdrop-blur-[2px]">
-          <form onSubmit={onCreate} className="w-full max-w-3xl overflow-hidden rounded-2xl border border-slate-200 bg-white shadow-2xl">
+          <form onSubmit={onSave} className="w-full max-w-3xl overflow-hidden rounded-2xl border border-slate-200 bg-white shadow-2xl">
             <div className="border-b border-slate-200 bg-slate-50 px-6 py-4">
-              <h3 className="text-lg font-semibold text-slate-900">Log Timesheet Entry</h3>
+              <h3 className="text-lg font-semibold text-slate-900">{editingId !== null ? "Edit Timesheet Entry" : "Log Timesheet Entry"}</h3>
               <p className="mt-1 text-sm text-slate-600">Capture work date, hours, and whether the effort is billable.</p>
             </div>
             <div className="grid gap-4 p-6 md:grid-cols-2">
@@ -284,10 +610,35 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                   step={0.25}
                   value={hours}
                   onChange={(event) => setHours(event.target.value)}
-                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300 disabled:bg-slate-50"
+                  disabled={Boolean(startTime && endTime)}
                   required
                 />
               </div>
+              <div>
+                <label htmlFor="timesheet-start" className="mb-1 block text-sm font-medium text-slate-700">
+                  Start Time
+                </label>
+                <input
+                  id="timesheet-start"
+                  type="time"
+                  value={startTime}
+                  onChange={(event) => setStartTime(event.target.value)}
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
+              <div>
+                <label htmlFor="timesheet-end" className="mb-1 block text-sm font-medium text-slate-700">
+                  End Time
+                </label>
+                <input
+                  id="timesheet-end"
+                  type="time"
+                  value={endTime}
+                  onChange={(event) => setEndTime(event.target.value)}
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
               <div>
                 <label htmlFor="timesheet-worktype" className="mb-1 block text-sm font-medium text-slate-700">
                   Work Type
@@ -306,6 +657,13 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
                 <label className="mb-1 block text-sm font-medium text-slate-700">Notes</label>
                 <textarea rows={4} value={notes} onChange={(event) => setNotes(event.target.value)} placeholder="Client workshop and follow-up documentation." className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300" />
               </div>
+              {violations.length > 0 ? (
+                <ul className="md:col-span-2 list-disc space-y-1 rounded-lg border border-rose-200 bg-rose-50 px-6 py-3 text-sm text-rose-700">
+                  {violations.map((violation, index) => (
+                    <li key={`${violation.rule}-${index}`}>{violation.message}</li>
+                  ))}
+                </ul>
+              ) : null}
             </div>
             <div className="flex justify-end gap-2 border-t border-slate-200 bg-white px-6 py-4">
               <button type="button" onClick={() => setShowCreate(false)} className="rounded-lg border border-slate-300 px-4 py-2 text-sm font-medium text-slate-700">
@@ -318,6 +676,90 @@ export default function TimesheetsPage({ searchQuery = "" }: TimesheetsPageProps
           </form>
         </div>
       ) : null}
+
+      {showPolicy && policy ? (
+        <div className="fixed inset-0 z-40 flex items-center justify-center bg-slate-950/55 p-4 backdrop-blur-[2px]">
+          <form onSubmit={onSavePolicy} className="w-full max-w-2xl overflow-hidden rounded-2xl border border-slate-200 bg-white shadow-2xl">
+            <div className="border-b border-slate-200 bg-slate-50 px-6 py-4">
+              <h3 className="text-lg font-semibold text-slate-900">Timesheet Policy</h3>
+              <p className="mt-1 text-sm text-slate-600">Rules every entry in this workspace is checked against. Leave a limit empty to disable it.</p>
+            </div>
+            <div className="grid gap-4 p-6 md:grid-cols-3">
+              <div>
+                <label htmlFor="policy-day" className="mb-1 block text-sm font-medium text-slate-700">
+                  Max Hours / Day
+                </label>
+                <input
+                  id="policy-day"
+                  type="number"
+                  min={0.25}
+                  max={24}
+                  step={0.25}
+                  value={policy.max_hours_per_day ?? ""}
+                  onChange={(event) => setPolicy({ ...policy, max_hours_per_day: policyLimit(event.target.value) })}
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
+              <div>
+                <label htmlFor="policy-week" className="mb-1 block text-sm font-medium text-slate-700">
+                  Max Hours / Week
+                </label>
+                <input
+                  id="policy-week"
+                  type="number"
+                  min={0.25}
+                  max={168}
+                  step={0.25}
+                  value={policy.max_hours_per_week ?? ""}
+                  onChange={(event) => setPolicy({ ...policy, max_hours_per_week: policyLimit(event.target.value) })}
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
+              <div>
+                <label htmlFor="policy-future" className="mb-1 block text-sm font-medium text-slate-700">
+                  Max Days Ahead
+                </label>
+                <input
+                  id="policy-future"
+                  type="number"
+                  min={0}
+                  step={1}
+                  value={policy.max_future_days ?? ""}
+                  onChange={(event) => setPolicy({ ...policy, max_future_days: policyLimit(event.target.value) })}
+                  className="w-full rounded-lg border border-slate-300 px-3 py-2 text-sm outline-none focus:border-sky-300"
+                />
+              </div>
+              <div className="space-y-2 text-sm text-slate-700 md:col-span-3">
+                <label className="flex items-center gap-2">
+                  <input type="checkbox" checked={policy.enforce_project_window} onChange={(event) => setPolicy({ ...policy, enforce_project_window: event.target.checked })} />
+                  Only allow dates between a project&apos;s start and due date
+                </label>
+                <label className="flex items-center gap-2">
+                  <input type="checkbox" checked={policy.block_closed_projects} onChange={(event) => setPolicy({ ...policy, block_closed_projects: event.target.checked })} />
+                  Block entries on completed projects
+                </label>
+                <label className="flex items-center gap-2">
+                  <input type="checkbox" checked={policy.require_clock_times} onChange={(event) => setPolicy({ ...policy, require_clock_times: event.target.checked })} />
+                  Require start and end times
+                </label>
+                <label className="flex items-center gap-2">
+                  <input type="checkbox" checked={policy.prevent_overlaps} onChange={(event) => setPolicy({ ...policy, prevent_overlaps: event.target.checked })} />
+                  Reject entries whose times overlap
+                </label>
+              </div>
+              {policyStatus ? <p className="text-sm text-rose-600 md:col-span-3">{policyStatus}</p> : null}
+            </div>
+            <div className="flex justify-end gap-2 border-t border-slate-200 bg-white px-6 py-4">
+              <button type="button" onClick={() => setShowPolicy(false)} className="rounded-lg border border-slate-300 px-4 py-2 text-sm font-medium text-slate-700">
+                Cancel
+              </button>
+              <button type="submit" className="rounded-lg bg-slate-900 px-4 py-2 text-sm font-medium text-white">
+                Save Policy
+              </button>
+            </div>
+          </form>
+        </div>
+      ) : null}
     </section>
   );
 }